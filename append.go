@@ -0,0 +1,110 @@
+package parquet
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	sch "github.com/parsyl/parquet/schema"
+)
+
+// AppendTo reads the footer of the parquet file r and makes m
+// continue that file: the row groups that are written with m are
+// added after the file's existing row groups and the footer that
+// m writes has both (along with the file's column orders).  It
+// returns the offset of r's footer, which is where the new row
+// groups must be written (overwriting the old footer).  The new
+// footer can be shorter than the old one, so r should be
+// truncated once it has been written.  The file's schema
+// (including its field IDs) must match m's, otherwise an
+// *ErrSchemaMismatch is returned.
+func (m *Metadata) AppendTo(r io.ReadSeeker) (int64, error) {
+	footer, err := ReadMetaData(r)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := checkSchema(m.schema.fields, footer); err != nil {
+		return 0, err
+	}
+
+	size, err := getMetaDataSize(r)
+	if err != nil {
+		return 0, err
+	}
+
+	end, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+
+	m.offset = end - int64(size) - 8
+	m.existing = footer.RowGroups
+	m.columnOrders = footer.ColumnOrders
+	m.docs += footer.NumRows
+
+	// the existing key/value metadata is kept unless it has
+	// been set again
+	keyValues := m.keyValues
+	m.keyValues = nil
+	for _, kv := range footer.KeyValueMetadata {
+		var v string
+		if kv.Value != nil {
+			v = *kv.Value
+		}
+		m.SetKeyValue(kv.Key, v)
+	}
+	for _, kv := range keyValues {
+		m.SetKeyValue(kv.Key, *kv.Value)
+	}
+
+	if m.createdBy == nil {
+		m.createdBy = footer.CreatedBy
+	}
+
+	return m.offset, nil
+}
+
+// checkSchema returns an *ErrSchemaMismatch if the primitive
+// columns of footer's schema aren't the same as fields.
+func checkSchema(fields []Field, footer *sch.FileMetaData) error {
+	columns, err := schemaColumns(footer)
+	if err != nil {
+		return err
+	}
+
+	for i, f := range fields {
+		name := strings.Join(f.Path, ".")
+		if i >= len(columns) {
+			return &ErrSchemaMismatch{Column: name, Reason: "not in the file's schema"}
+		}
+
+		c := columns[i].field
+		if !reflect.DeepEqual(c.Path, f.Path) {
+			return &ErrSchemaMismatch{Column: name, Reason: fmt.Sprintf("the file's column is %s", c.Name)}
+		}
+
+		if !reflect.DeepEqual(c.Types, f.Types) {
+			return &ErrSchemaMismatch{Column: name, Reason: fmt.Sprintf("repetition types %v don't match the file's %v", f.Types, c.Types)}
+		}
+
+		var se, cse sch.SchemaElement
+		f.Type(&se)
+		c.Type(&cse)
+		if *se.Type != *cse.Type || !reflect.DeepEqual(se.ConvertedType, cse.ConvertedType) {
+			return &ErrSchemaMismatch{Column: name, Reason: fmt.Sprintf("type %s doesn't match the file's %s", se.Type, cse.Type)}
+		}
+
+		for j := range f.Path {
+			if !reflect.DeepEqual(f.id(j), c.id(j)) {
+				return &ErrSchemaMismatch{Column: name, Reason: fmt.Sprintf("field IDs %v don't match the file's %v", f.IDs, c.IDs)}
+			}
+		}
+	}
+
+	if len(columns) > len(fields) {
+		return &ErrSchemaMismatch{Column: columns[len(fields)].field.Name, Reason: "not in the schema"}
+	}
+	return nil
+}
@@ -42,5 +42,16 @@ func Read(f fields.Field) string {
 func writeRequired(f fields.Field) string {
 	return fmt.Sprintf(`func %s(x *%s, vals []%s) {
 	x.%s = vals[0]
-}`, fmt.Sprintf("write%s", strings.Join(f.FieldNames(), "")), f.StructType(), f.TypeName(), strings.Join(f.FieldNames(), "."))
+}`, fmt.Sprintf("write%s", f.FuncName()), f.StructType(), f.TypeName(), strings.Join(f.FieldNames(), "."))
+}
+
+// WriteBatch generates the code for initializing a run of
+// structs with the values of a required field, one value per
+// struct.
+func WriteBatch(f fields.Field) string {
+	return fmt.Sprintf(`func %s(xs []%s, vals []%s) {
+	for i := range xs {
+		xs[i].%s = vals[i]
+	}
+}`, fmt.Sprintf("scan%s", f.FuncName()), f.StructType(), f.TypeName(), strings.Join(f.FieldNames(), "."))
 }
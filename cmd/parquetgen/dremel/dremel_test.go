@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"testing"
 
+	"github.com/parsyl/parquet"
 	"github.com/parsyl/parquet/cmd/parquetgen/dremel/testcases/doc"
 	"github.com/parsyl/parquet/cmd/parquetgen/dremel/testcases/person"
 	"github.com/parsyl/parquet/cmd/parquetgen/dremel/testcases/repetition"
@@ -74,7 +75,7 @@ func TestLevels(t *testing.T) {
 		assert.NoError(t, err)
 	}
 
-	expected := []doc.Levels{
+	expected := []parquet.Levels{
 		{Name: "docid"},
 		{Name: "link.backward", Defs: []uint8{1, 2, 2}, Reps: []uint8{0, 0, 1}},
 		{Name: "link.forward", Defs: []uint8{2, 2, 2, 2}, Reps: []uint8{0, 1, 1, 0}},
@@ -124,7 +125,7 @@ func TestPersonLevels(t *testing.T) {
 		assert.NoError(t, err)
 	}
 
-	expected := []person.Levels{
+	expected := []parquet.Levels{
 		{Name: "name"},
 		{Name: "hobby.name", Defs: []uint8{1}},
 		{Name: "hobby.difficulty", Defs: []uint8{2}},
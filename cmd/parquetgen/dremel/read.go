@@ -10,7 +10,20 @@ import (
 func readRequired(f fields.Field) string {
 	return fmt.Sprintf(`func read%s(x %s) %s {
 	return x.%s
-}`, strings.Join(f.FieldNames(), ""), f.StructType(), f.TypeName(), strings.Join(f.FieldNames(), "."))
+}`, f.FuncName(), f.StructType(), f.TypeName(), strings.Join(f.FieldNames(), "."))
+}
+
+// ReadBatch generates the code for appending the values of a
+// required field from a run of structs with a single append.
+func ReadBatch(f fields.Field) string {
+	return fmt.Sprintf(`func add%s(xs []%s, vals []%s) []%s {
+	n := len(vals)
+	vals = append(vals, make([]%s, len(xs))...)
+	for i := range xs {
+		vals[n+i] = xs[i].%s
+	}
+	return vals
+}`, f.FuncName(), f.StructType(), f.TypeName(), f.TypeName(), f.TypeName(), strings.Join(f.FieldNames(), "."))
 }
 
 func readOptional(f fields.Field) string {
@@ -38,7 +51,7 @@ func readOptional(f fields.Field) string {
 		switch {
 		%s
 		}
-	}`, strings.Join(f.FieldNames(), ""), f.StructType(), cleanTypeName(f.Type), cleanTypeName(f.Type), out)
+	}`, f.FuncName(), f.StructType(), cleanTypeName(f.Type), cleanTypeName(f.Type), out)
 }
 
 func cleanTypeName(s string) string {
@@ -67,7 +67,7 @@ func readRepeated(f fields.Field) string {
 
 	return vals, defs, reps	
 }`,
-		strings.Join(f.FieldNames(), ""),
+		f.FuncName(),
 		f.StructType(),
 		cleanTypeName(f.Type),
 		cleanTypeName(f.Type),
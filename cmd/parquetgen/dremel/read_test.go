@@ -595,3 +595,55 @@ func TestRead(t *testing.T) {
 		})
 	}
 }
+
+func TestReadBatch(t *testing.T) {
+	testCases := []struct {
+		name   string
+		field  fields.Field
+		result string
+	}{
+		{
+			name: "not nested",
+			field: fields.Field{
+				Type: "int32", Name: "ID", RepetitionType: fields.Required,
+			},
+			result: `func addID(xs []Person, vals []int32) []int32 {
+	n := len(vals)
+	vals = append(vals, make([]int32, len(xs))...)
+	for i := range xs {
+		vals[n+i] = xs[i].ID
+	}
+	return vals
+}`,
+		},
+		{
+			name: "nested",
+			field: fields.Field{
+				Name: "Other", RepetitionType: fields.Required, Children: []fields.Field{
+					{Name: "Hobby", RepetitionType: fields.Required, Children: []fields.Field{
+						{Type: "string", Name: "Name", RepetitionType: fields.Required},
+					}},
+				},
+			},
+			result: `func addOtherHobbyName(xs []Person, vals []string) []string {
+	n := len(vals)
+	vals = append(vals, make([]string, len(xs))...)
+	for i := range xs {
+		vals[n+i] = xs[i].Other.Hobby.Name
+	}
+	return vals
+}`,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%02d %s", i, tc.name), func(t *testing.T) {
+			flds := fields.Field{Type: "Person", Children: []fields.Field{tc.field}}.Fields()
+			f := flds[len(flds)-1]
+			s := dremel.ReadBatch(f)
+			gocode, err := format.Source([]byte(s))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.result, string(gocode))
+		})
+	}
+}
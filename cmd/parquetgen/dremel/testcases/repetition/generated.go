@@ -3,27 +3,19 @@ package repetition
 // Code generated by github.com/parsyl/parquet.  DO NOT EDIT.
 
 import (
+	"context"
 	"encoding/binary"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/parsyl/parquet"
 	sch "github.com/parsyl/parquet/schema"
 	"github.com/valyala/bytebufferpool"
 )
 
-type compression int
-
-const (
-	compressionUncompressed compression = 0
-	compressionSnappy       compression = 1
-	compressionGzip         compression = 2
-	compressionUnknown      compression = -1
-)
-
-var buffpool = bytebufferpool.Pool{}
-
 // ParquetWriter reprents a row group
 type ParquetWriter struct {
 	fields []Field
@@ -39,17 +31,51 @@ type ParquetWriter struct {
 
 	meta        *parquet.Metadata
 	w           io.Writer
-	compression compression
+	compression sch.CompressionCodec
+
+	// concurrency is the number of columns that are
+	// encoded and compressed at the same time by Write
+	concurrency int
+
+	// checksums is true if each page header
+	// includes a CRC32 of the page's data
+	checksums bool
+
+	// encodings holds the encoding of each column
+	// that isn't PLAIN encoded
+	encodings map[string]sch.Encoding
+
+	// keyValues and createdBy are written to
+	// the footer
+	keyValues [][2]string
+	createdBy *string
+
+	// sortBy holds the columns that each row group is sorted by,
+	// along with their compare funcs, and records holds the records
+	// that have been added since the last Write
+	sortBy   []string
+	compares []func(a, b Document) int
+	records  []Document
+
+	// appended is the file that NewParquetAppender
+	// writes to.  Close truncates it to the end of the new
+	// footer, which can be shorter than the old one.
+	appended truncater
 }
 
-func Fields(compression compression) []Field {
+type truncater interface {
+	io.Seeker
+	Truncate(size int64) error
+}
+
+func Fields(compression sch.CompressionCodec) []Field {
 	return []Field{
-		NewStringOptionalField(readLinksBackwardCodes, writeLinksBackwardCodes, []string{"links", "backward", "code"}, []int{2, 2, 2}, optionalFieldCompression(compression)),
-		NewStringOptionalField(readLinksBackwardURL, writeLinksBackwardURL, []string{"links", "backward", "url"}, []int{2, 2, 1}, optionalFieldCompression(compression)),
-		NewStringOptionalField(readLinksBackwardCountries, writeLinksBackwardCountries, []string{"links", "backward", "countries"}, []int{2, 2, 2}, optionalFieldCompression(compression)),
-		NewStringOptionalField(readLinksForwardCodes, writeLinksForwardCodes, []string{"links", "forward", "code"}, []int{2, 2, 2}, optionalFieldCompression(compression)),
-		NewStringOptionalField(readLinksForwardURL, writeLinksForwardURL, []string{"links", "forward", "url"}, []int{2, 2, 1}, optionalFieldCompression(compression)),
-		NewStringOptionalField(readLinksForwardCountries, writeLinksForwardCountries, []string{"links", "forward", "countries"}, []int{2, 2, 2}, optionalFieldCompression(compression)),
+		NewStringOptionalField(readLinksBackwardCodes, writeLinksBackwardCodes, []string{"links", "backward", "code"}, []int{2, 2, 2}, parquet.OptionalFieldCompression(compression)),
+		NewStringOptionalField(readLinksBackwardURL, writeLinksBackwardURL, []string{"links", "backward", "url"}, []int{2, 2, 1}, parquet.OptionalFieldCompression(compression)),
+		NewStringOptionalField(readLinksBackwardCountries, writeLinksBackwardCountries, []string{"links", "backward", "countries"}, []int{2, 2, 2}, parquet.OptionalFieldCompression(compression)),
+		NewStringOptionalField(readLinksForwardCodes, writeLinksForwardCodes, []string{"links", "forward", "code"}, []int{2, 2, 2}, parquet.OptionalFieldCompression(compression)),
+		NewStringOptionalField(readLinksForwardURL, writeLinksForwardURL, []string{"links", "forward", "url"}, []int{2, 2, 1}, parquet.OptionalFieldCompression(compression)),
+		NewStringOptionalField(readLinksForwardCountries, writeLinksForwardCountries, []string{"links", "forward", "countries"}, []int{2, 2, 2}, parquet.OptionalFieldCompression(compression)),
 	}
 }
 
@@ -95,7 +121,7 @@ func readLinksBackwardCodes(x Document, vals []string, defs, reps []uint8) ([]st
 
 func writeLinksBackwardCodes(x *Document, vals []string, defs, reps []uint8) (int, int) {
 	var nVals, nLevels int
-	ind := make(indices, 3)
+	ind := make(parquet.Indices, 3)
 
 	for i := range defs {
 		def := defs[i]
@@ -105,7 +131,7 @@ func writeLinksBackwardCodes(x *Document, vals []string, defs, reps []uint8) (in
 		}
 
 		nLevels++
-		ind.rep(rep)
+		ind.Rep(rep)
 
 		switch def {
 		case 1:
@@ -170,7 +196,7 @@ func readLinksBackwardURL(x Document, vals []string, defs, reps []uint8) ([]stri
 
 func writeLinksBackwardURL(x *Document, vals []string, defs, reps []uint8) (int, int) {
 	var nVals, nLevels int
-	ind := make(indices, 2)
+	ind := make(parquet.Indices, 2)
 
 	for i := range defs {
 		def := defs[i]
@@ -180,11 +206,11 @@ func writeLinksBackwardURL(x *Document, vals []string, defs, reps []uint8) (int,
 		}
 
 		nLevels++
-		ind.rep(rep)
+		ind.Rep(rep)
 
 		switch def {
 		case 3:
-			x.Links[ind[0]].Backward[ind[1]].URL = pstring(vals[nVals])
+			x.Links[ind[0]].Backward[ind[1]].URL = parquet.StringPtr(vals[nVals])
 			nVals++
 		}
 	}
@@ -234,7 +260,7 @@ func readLinksBackwardCountries(x Document, vals []string, defs, reps []uint8) (
 
 func writeLinksBackwardCountries(x *Document, vals []string, defs, reps []uint8) (int, int) {
 	var nVals, nLevels int
-	ind := make(indices, 3)
+	ind := make(parquet.Indices, 3)
 
 	for i := range defs {
 		def := defs[i]
@@ -244,7 +270,7 @@ func writeLinksBackwardCountries(x *Document, vals []string, defs, reps []uint8)
 		}
 
 		nLevels++
-		ind.rep(rep)
+		ind.Rep(rep)
 
 		switch def {
 		case 3:
@@ -298,7 +324,7 @@ func readLinksForwardCodes(x Document, vals []string, defs, reps []uint8) ([]str
 
 func writeLinksForwardCodes(x *Document, vals []string, defs, reps []uint8) (int, int) {
 	var nVals, nLevels int
-	ind := make(indices, 3)
+	ind := make(parquet.Indices, 3)
 
 	for i := range defs {
 		def := defs[i]
@@ -308,7 +334,7 @@ func writeLinksForwardCodes(x *Document, vals []string, defs, reps []uint8) (int
 		}
 
 		nLevels++
-		ind.rep(rep)
+		ind.Rep(rep)
 
 		switch def {
 		case 2:
@@ -364,7 +390,7 @@ func readLinksForwardURL(x Document, vals []string, defs, reps []uint8) ([]strin
 
 func writeLinksForwardURL(x *Document, vals []string, defs, reps []uint8) (int, int) {
 	var nVals, nLevels int
-	ind := make(indices, 2)
+	ind := make(parquet.Indices, 2)
 
 	for i := range defs {
 		def := defs[i]
@@ -374,11 +400,11 @@ func writeLinksForwardURL(x *Document, vals []string, defs, reps []uint8) (int,
 		}
 
 		nLevels++
-		ind.rep(rep)
+		ind.Rep(rep)
 
 		switch def {
 		case 3:
-			x.Links[ind[0]].Forward[ind[1]].URL = pstring(vals[nVals])
+			x.Links[ind[0]].Forward[ind[1]].URL = parquet.StringPtr(vals[nVals])
 			nVals++
 		}
 	}
@@ -428,7 +454,7 @@ func readLinksForwardCountries(x Document, vals []string, defs, reps []uint8) ([
 
 func writeLinksForwardCountries(x *Document, vals []string, defs, reps []uint8) (int, int) {
 	var nVals, nLevels int
-	ind := make(indices, 3)
+	ind := make(parquet.Indices, 3)
 
 	for i := range defs {
 		def := defs[i]
@@ -438,7 +464,7 @@ func writeLinksForwardCountries(x *Document, vals []string, defs, reps []uint8)
 		}
 
 		nLevels++
-		ind.rep(rep)
+		ind.Rep(rep)
 
 		switch def {
 		case 3:
@@ -450,41 +476,44 @@ func writeLinksForwardCountries(x *Document, vals []string, defs, reps []uint8)
 	return nVals, nLevels
 }
 
-func fieldCompression(c compression) func(*parquet.RequiredField) {
-	switch c {
-	case compressionUncompressed:
-		return parquet.RequiredFieldUncompressed
-	case compressionSnappy:
-		return parquet.RequiredFieldSnappy
-	case compressionGzip:
-		return parquet.RequiredFieldGzip
-	default:
-		return parquet.RequiredFieldUncompressed
-	}
+func NewParquetWriter(w io.Writer, opts ...func(*ParquetWriter) error) (*ParquetWriter, error) {
+	return newParquetWriter(w, append(opts, begin)...)
 }
 
-func optionalFieldCompression(c compression) func(*parquet.OptionalField) {
-	switch c {
-	case compressionUncompressed:
-		return parquet.OptionalFieldUncompressed
-	case compressionSnappy:
-		return parquet.OptionalFieldSnappy
-	case compressionGzip:
-		return parquet.OptionalFieldGzip
-	default:
-		return parquet.OptionalFieldUncompressed
+// NewParquetAppender creates a writer that adds row groups to the
+// existing parquet file f.  It reads f's footer, checks that the file's
+// schema matches Document's and then writes the new row groups over the
+// footer.  Close writes a footer that has both the existing and the new
+// row groups and truncates f to the end of it, so f must also have a
+// Truncate(int64) error method (like *os.File).
+func NewParquetAppender(f io.ReadWriteSeeker, opts ...func(*ParquetWriter) error) (*ParquetWriter, error) {
+	t, ok := f.(truncater)
+	if !ok {
+		return nil, fmt.Errorf("appending requires a file that can be truncated, got %T", f)
 	}
-}
 
-func NewParquetWriter(w io.Writer, opts ...func(*ParquetWriter) error) (*ParquetWriter, error) {
-	return newParquetWriter(w, append(opts, begin)...)
+	p, err := newParquetWriter(f, opts...)
+	if err != nil {
+		return nil, err
+	}
+	p.appended = t
+
+	offset, err := p.meta.AppendTo(f)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return p, nil
 }
 
 func newParquetWriter(w io.Writer, opts ...func(*ParquetWriter) error) (*ParquetWriter, error) {
 	p := &ParquetWriter{
 		max:         1000,
 		w:           w,
-		compression: compressionSnappy,
+		compression: sch.CompressionCodec_SNAPPY,
 	}
 
 	for _, opt := range opts {
@@ -501,6 +530,25 @@ func newParquetWriter(w io.Writer, opts ...func(*ParquetWriter) error) (*Parquet
 			schema[i] = f.Schema()
 		}
 		p.meta = parquet.New(schema...)
+		if p.checksums {
+			p.meta.EnableChecksums()
+		}
+		for col, enc := range p.encodings {
+			if err := p.meta.SetEncoding(col, enc); err != nil {
+				return nil, err
+			}
+		}
+		for _, kv := range p.keyValues {
+			p.meta.SetKeyValue(kv[0], kv[1])
+		}
+		if p.createdBy != nil {
+			p.meta.SetCreatedBy(*p.createdBy)
+		}
+		if len(p.sortBy) > 0 {
+			if err := p.meta.SetSortingColumns(p.sortBy...); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	return p, nil
@@ -514,10 +562,80 @@ func MaxPageSize(m int) func(*ParquetWriter) error {
 	}
 }
 
-var par1 = []byte("PAR1")
+// Concurrency sets the number of columns that are encoded and
+// compressed in parallel by Write.  Each column is written to its own
+// buffer and the buffers are then written in the order of the schema.
+func Concurrency(n int) func(*ParquetWriter) error {
+	return func(p *ParquetWriter) error {
+		if n < 1 {
+			return fmt.Errorf("invalid concurrency: %d", n)
+		}
+		p.concurrency = n
+		return nil
+	}
+}
+
+// Checksums adds a CRC32 of each page's data to its page header
+// so that readers can detect corrupt pages.
+func Checksums(p *ParquetWriter) error {
+	p.checksums = true
+	return nil
+}
+
+// Encoding sets the encoding of column's values (column is the
+// name of the column's fields, separated by dots).  NewParquetWriter
+// returns an error if the column doesn't exist or if its type can't be
+// encoded with enc.
+func Encoding(column string, enc sch.Encoding) func(*ParquetWriter) error {
+	return func(p *ParquetWriter) error {
+		if p.encodings == nil {
+			p.encodings = map[string]sch.Encoding{}
+		}
+		p.encodings[column] = enc
+		return nil
+	}
+}
+
+// KeyValue adds a key/value pair to the metadata in the
+// file's footer.
+func KeyValue(key, value string) func(*ParquetWriter) error {
+	return func(p *ParquetWriter) error {
+		p.keyValues = append(p.keyValues, [2]string{key, value})
+		return nil
+	}
+}
+
+// CreatedBy sets the application that wrote the file (the
+// footer's created_by).
+func CreatedBy(createdBy string) func(*ParquetWriter) error {
+	return func(p *ParquetWriter) error {
+		p.createdBy = &createdBy
+		return nil
+	}
+}
+
+// SortBy makes Write sort the records of each row group by columns
+// (in ascending order, with missing values first) before they are encoded.
+// The sort order is recorded in the row groups' sorting_columns.  Repeated
+// columns can't be sorted by.
+func SortBy(columns ...string) func(*ParquetWriter) error {
+	return func(p *ParquetWriter) error {
+		for _, col := range columns {
+			cmp, ok := compareFuncs[col]
+			if !ok {
+				return fmt.Errorf("can't sort by column %s, it doesn't exist or is repeated", col)
+			}
+			p.compares = append(p.compares, cmp)
+		}
+		p.sortBy = columns
+		return nil
+	}
+}
+
+var compareFuncs = map[string]func(a, b Document) int{}
 
 func begin(p *ParquetWriter) error {
-	_, err := p.w.Write(par1)
+	_, err := io.WriteString(p.w, parquet.Magic)
 	return err
 }
 
@@ -529,21 +647,21 @@ func withMeta(m *parquet.Metadata) func(*ParquetWriter) error {
 }
 
 func Uncompressed(p *ParquetWriter) error {
-	p.compression = compressionUncompressed
+	p.compression = sch.CompressionCodec_UNCOMPRESSED
 	return nil
 }
 
 func Snappy(p *ParquetWriter) error {
-	p.compression = compressionSnappy
+	p.compression = sch.CompressionCodec_SNAPPY
 	return nil
 }
 
 func Gzip(p *ParquetWriter) error {
-	p.compression = compressionGzip
+	p.compression = sch.CompressionCodec_GZIP
 	return nil
 }
 
-func withCompression(c compression) func(*ParquetWriter) error {
+func withCompression(c sch.CompressionCodec) func(*ParquetWriter) error {
 	return func(p *ParquetWriter) error {
 		p.compression = c
 		return nil
@@ -551,13 +669,26 @@ func withCompression(c compression) func(*ParquetWriter) error {
 }
 
 func (p *ParquetWriter) Write() error {
-	for i, f := range p.fields {
-		if err := f.Write(p.w, p.meta); err != nil {
+	return p.WriteContext(context.Background())
+}
+
+// WriteContext is Write with a context.  ctx is checked before each
+// page is written and ctx.Err() is returned if it is done (after
+// which the file is incomplete and should be discarded).
+func (p *ParquetWriter) WriteContext(ctx context.Context) error {
+	if p.compares != nil {
+		if err := p.addSorted(); err != nil {
 			return err
 		}
+	}
 
-		for child := p.child; child != nil; child = child.child {
-			if err := child.fields[i].Write(p.w, p.meta); err != nil {
+	if p.concurrency > 1 {
+		if err := p.writeConcurrently(ctx); err != nil {
+			return err
+		}
+	} else {
+		for i := range p.fields {
+			if err := p.writeColumn(ctx, p.w, i); err != nil {
 				return err
 			}
 		}
@@ -575,16 +706,118 @@ func (p *ParquetWriter) Write() error {
 	return nil
 }
 
+// addSorted sorts the records that have been added since the
+// last Write and adds them to the row group.
+func (p *ParquetWriter) addSorted() error {
+	recs := p.records
+	p.records = nil
+	sort.SliceStable(recs, func(i, j int) bool {
+		for _, cmp := range p.compares {
+			if c := cmp(recs[i], recs[j]); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	return p.addBatch(recs)
+}
+
+// writeColumn writes the pages of the i'th column.
+func (p *ParquetWriter) writeColumn(ctx context.Context, w io.Writer, i int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := p.fields[i].Write(w, p.meta); err != nil {
+		return err
+	}
+
+	for child := p.child; child != nil; child = child.child {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := child.fields[i].Write(w, p.meta); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeConcurrently writes each column to its own buffer (using
+// up to p.concurrency goroutines) and then writes the buffers to
+// p.w in order.
+func (p *ParquetWriter) writeConcurrently(ctx context.Context) error {
+	bufs := make([]*bytebufferpool.ByteBuffer, len(p.fields))
+	errs := make([]error, len(p.fields))
+	sem := make(chan struct{}, p.concurrency)
+
+	var wg sync.WaitGroup
+	for i := range p.fields {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			bufs[i] = parquet.GetBuffer()
+			errs[i] = p.writeColumn(ctx, bufs[i], i)
+		}(i)
+	}
+	wg.Wait()
+
+	defer func() {
+		for _, buf := range bufs {
+			parquet.PutBuffer(buf)
+		}
+	}()
+
+	for i, buf := range bufs {
+		if errs[i] != nil {
+			return errs[i]
+		}
+
+		if _, err := p.w.Write(buf.B); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (p *ParquetWriter) Close() error {
-	if err := p.meta.Footer(p.w); err != nil {
+	return p.CloseContext(context.Background())
+}
+
+// CloseContext is Close with a context.  It returns ctx.Err() if
+// ctx is done before the metadata is written.
+func (p *ParquetWriter) CloseContext(ctx context.Context) error {
+	if err := p.meta.FooterContext(ctx, p.w); err != nil {
 		return err
 	}
 
-	_, err := p.w.Write(par1)
-	return err
+	if _, err := io.WriteString(p.w, parquet.Magic); err != nil {
+		return err
+	}
+
+	if p.appended == nil {
+		return nil
+	}
+
+	end, err := p.appended.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	return p.appended.Truncate(end)
 }
 
 func (p *ParquetWriter) Add(rec Document) {
+	if p.compares != nil {
+		p.records = append(p.records, rec)
+		return
+	}
+
 	if p.len == p.max {
 		if p.child == nil {
 			// an error can't happen here
@@ -603,11 +836,61 @@ func (p *ParquetWriter) Add(rec Document) {
 	p.len++
 }
 
+// AddBatch adds recs to the current row group.  Unlike calling
+// Add for each record it adds the records one column (and one page)
+// at a time, so each required column grows with a single append
+// per page.
+func (p *ParquetWriter) AddBatch(recs []Document) error {
+	if p.max <= 0 {
+		return fmt.Errorf("invalid max page size: %d", p.max)
+	}
+
+	if p.compares != nil {
+		p.records = append(p.records, recs...)
+		return nil
+	}
+	return p.addBatch(recs)
+}
+
+func (p *ParquetWriter) addBatch(recs []Document) error {
+	w := p
+	for len(recs) > 0 {
+		for w.len == w.max {
+			if w.child == nil {
+				child, err := newParquetWriter(p.w, MaxPageSize(p.max), withMeta(p.meta), withCompression(p.compression))
+				if err != nil {
+					return err
+				}
+				w.child = child
+			}
+			w = w.child
+		}
+
+		n := w.max - w.len
+		if n > len(recs) {
+			n = len(recs)
+		}
+
+		batch := recs[:n]
+		for _, f := range w.fields {
+			f.AddBatch(batch)
+		}
+
+		p.meta.NextDocs(int64(n))
+
+		w.len += n
+		recs = recs[n:]
+	}
+	return nil
+}
+
 type Field interface {
 	Add(r Document)
+	AddBatch(rs []Document)
 	Write(w io.Writer, meta *parquet.Metadata) error
 	Schema() parquet.Field
-	Scan(r *Document)
+	Scan(r *Document) bool
+	ScanBatch(rs []Document) int
 	Read(r io.ReadSeeker, pg parquet.Page) error
 	Name() string
 	Levels() ([]uint8, []uint8)
@@ -622,7 +905,6 @@ func getFields(ff []Field) map[string]Field {
 }
 
 func NewParquetReader(r io.ReadSeeker, opts ...func(*ParquetReader)) (*ParquetReader, error) {
-	ff := Fields(compressionUnknown)
 	pr := &ParquetReader{
 		r: r,
 	}
@@ -631,31 +913,64 @@ func NewParquetReader(r io.ReadSeeker, opts ...func(*ParquetReader)) (*ParquetRe
 		opt(pr)
 	}
 
-	schema := make([]parquet.Field, len(ff))
-	for i, f := range ff {
-		pr.fieldNames = append(pr.fieldNames, f.Name())
-		schema[i] = f.Schema()
+	if pr.concurrency > 0 || pr.prefetch {
+		ra, ok := r.(io.ReaderAt)
+		if !ok {
+			return nil, fmt.Errorf("concurrent reads require an io.ReaderAt, got %T", r)
+		}
+		pr.ra = ra
 	}
 
-	meta := parquet.New(schema...)
+	meta := pr.metadata()
 	if err := meta.ReadFooter(r); err != nil {
 		return nil, err
 	}
-	pr.rows = meta.Rows()
-	var err error
-	pr.pages, err = meta.Pages()
-	if err != nil {
+
+	return pr, pr.start(meta)
+}
+
+// NewParquetReaderAt creates a reader that only reads the file (of the
+// given size) with ReadAt, so, unlike NewParquetReader, it doesn't depend
+// on the seek position of r.
+func NewParquetReaderAt(r io.ReaderAt, size int64, opts ...func(*ParquetReader)) (*ParquetReader, error) {
+	pr := &ParquetReader{
+		ra: r,
+	}
+
+	for _, opt := range opts {
+		opt(pr)
+	}
+
+	meta := pr.metadata()
+	if err := meta.ReadFooterAt(r, size); err != nil {
 		return nil, err
 	}
 
-	pr.rowGroups = meta.RowGroups()
-	_, err = r.Seek(4, io.SeekStart)
+	return pr, pr.start(meta)
+}
+
+func (p *ParquetReader) metadata() *parquet.Metadata {
+	ff := Fields(sch.CompressionCodec_UNCOMPRESSED)
+	schema := make([]parquet.Field, len(ff))
+	for i, f := range ff {
+		p.fieldNames = append(p.fieldNames, f.Name())
+		schema[i] = f.Schema()
+	}
+	return parquet.New(schema...)
+}
+
+func (p *ParquetReader) start(meta *parquet.Metadata) error {
+	p.rows = meta.Rows()
+	var err error
+	p.pages, err = meta.Pages()
 	if err != nil {
-		return nil, err
+		return err
 	}
-	pr.meta = meta
 
-	return pr, pr.readRowGroup()
+	p.rowGroups = meta.RowGroups()
+	p.meta = meta
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	return p.readRowGroup(context.Background())
 }
 
 func readerIndex(i int) func(*ParquetReader) {
@@ -664,6 +979,22 @@ func readerIndex(i int) func(*ParquetReader) {
 	}
 }
 
+// ReadConcurrency makes the reader read and decode up to n columns
+// of a row group at the same time.  The io.ReadSeeker passed to
+// NewParquetReader must also be an io.ReaderAt.
+func ReadConcurrency(n int) func(*ParquetReader) {
+	return func(p *ParquetReader) {
+		p.concurrency = n
+	}
+}
+
+// Prefetch makes the reader read and decode the next row group in
+// the background while the current one is being scanned.  The io.ReadSeeker
+// passed to NewParquetReader must also be an io.ReaderAt.
+func Prefetch(p *ParquetReader) {
+	p.prefetch = true
+}
+
 // ParquetReader reads one page from a row group.
 type ParquetReader struct {
 	fields         map[string]Field
@@ -679,21 +1010,28 @@ type ParquetReader struct {
 
 	r         io.ReadSeeker
 	rowGroups []parquet.RowGroup
-}
 
-type Levels struct {
-	Name string
-	Defs []uint8
-	Reps []uint8
+	// ra is set when the reader was created by NewParquetReaderAt
+	// or when the row groups are read concurrently (see
+	// ReadConcurrency and Prefetch).
+	ra          io.ReaderAt
+	concurrency int
+	prefetch    bool
+	prefetched  <-chan fetchedRowGroup
+
+	// ctx bounds the row groups that are read in the background.
+	// It is cancelled by Close or once the reader fails.
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
-func (p *ParquetReader) Levels() []Levels {
-	var out []Levels
+func (p *ParquetReader) Levels() []parquet.Levels {
+	var out []parquet.Levels
 	//for {
 	for _, name := range p.fieldNames {
 		f := p.fields[name]
 		d, r := f.Levels()
-		out = append(out, Levels{Name: f.Name(), Defs: d, Reps: r})
+		out = append(out, parquet.Levels{Name: f.Name(), Defs: d, Reps: r})
 	}
 	//	if err := p.readRowGroup(); err != nil {
 	//		break
@@ -706,7 +1044,27 @@ func (p *ParquetReader) Error() error {
 	return p.err
 }
 
-func (p *ParquetReader) readRowGroup() error {
+// Close stops reading row groups in the background (see
+// Prefetch).  It doesn't close the underlying reader.
+func (p *ParquetReader) Close() error {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	return nil
+}
+
+// KeyValueMetadata returns the key/value metadata of the file.
+func (p *ParquetReader) KeyValueMetadata() map[string]string {
+	return p.meta.KeyValueMetadata()
+}
+
+// CreatedBy returns the application that wrote the file (if the
+// file says so).
+func (p *ParquetReader) CreatedBy() string {
+	return p.meta.CreatedBy()
+}
+
+func (p *ParquetReader) readRowGroup(ctx context.Context) error {
 	p.rowGroupCursor = 0
 
 	if len(p.rowGroups) == 0 {
@@ -715,23 +1073,39 @@ func (p *ParquetReader) readRowGroup() error {
 	}
 
 	rg := p.rowGroups[0]
-	p.fields = getFields(Fields(compressionUnknown))
 	p.rowGroupCount = rg.Rows
-	p.rowGroupCursor = 0
+	if p.ra != nil {
+		return p.readRowGroupAt(ctx)
+	}
+
+	p.fields = getFields(Fields(sch.CompressionCodec_UNCOMPRESSED))
 	for _, col := range rg.Columns() {
 		name := strings.Join(col.MetaData.PathInSchema, ".")
 		f, ok := p.fields[name]
 		if !ok {
-			return fmt.Errorf("unknown field: %s", name)
+			return &parquet.ErrSchemaMismatch{Column: name, Reason: "unknown field"}
 		}
 		pages := p.pages[name]
 		if len(pages) <= p.index {
 			break
 		}
 
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		pg := pages[0]
+		if _, err := p.r.Seek(pg.Offset, io.SeekStart); err != nil {
+			return err
+		}
+
 		if err := f.Read(p.r, pg); err != nil {
-			return fmt.Errorf("unable to read field %s, err: %s", f.Name(), err)
+			return fmt.Errorf("unable to read field %s, err: %w", f.Name(), err)
+		}
+
+		_, reps := f.Levels()
+		if err := parquet.CheckRecords(name, pg, reps, rg.Rows); err != nil {
+			return err
 		}
 		p.pages[name] = p.pages[name][1:]
 	}
@@ -739,16 +1113,146 @@ func (p *ParquetReader) readRowGroup() error {
 	return nil
 }
 
+type fetchedRowGroup struct {
+	fields map[string]Field
+	err    error
+}
+
+// readRowGroupAt reads the next row group (unless it has already
+// been prefetched) and, if p.prefetch is set, starts fetching the
+// one after that.  The row groups are fetched under p.ctx, ctx only
+// bounds how long readRowGroupAt waits for the next one.
+func (p *ParquetReader) readRowGroupAt(ctx context.Context) error {
+	next := p.prefetched
+	p.prefetched = nil
+	if next == nil {
+		next = p.fetchRowGroup(p.ctx, p.rowGroups[0])
+	}
+
+	p.rowGroups = p.rowGroups[1:]
+	if p.prefetch && len(p.rowGroups) > 0 {
+		p.prefetched = p.fetchRowGroup(p.ctx, p.rowGroups[0])
+	}
+
+	var res fetchedRowGroup
+	select {
+	case res = <-next:
+	case <-ctx.Done():
+		p.cancel()
+		return ctx.Err()
+	}
+
+	if res.err != nil {
+		p.cancel()
+		return res.err
+	}
+
+	p.fields = res.fields
+	return nil
+}
+
+// fetchRowGroup reads and decodes the columns of rg in the
+// background, up to p.concurrency columns at a time.  It stops
+// reading columns once ctx is done.
+func (p *ParquetReader) fetchRowGroup(ctx context.Context, rg parquet.RowGroup) <-chan fetchedRowGroup {
+	type column struct {
+		f  Field
+		pg parquet.Page
+	}
+
+	out := make(chan fetchedRowGroup, 1)
+	fields := getFields(Fields(sch.CompressionCodec_UNCOMPRESSED))
+	var cols []column
+	for _, col := range rg.Columns() {
+		name := strings.Join(col.MetaData.PathInSchema, ".")
+		f, ok := fields[name]
+		if !ok {
+			out <- fetchedRowGroup{err: &parquet.ErrSchemaMismatch{Column: name, Reason: "unknown field"}}
+			return out
+		}
+		pages := p.pages[name]
+		if len(pages) <= p.index {
+			break
+		}
+
+		cols = append(cols, column{f: f, pg: pages[0]})
+		p.pages[name] = p.pages[name][1:]
+	}
+
+	n := p.concurrency
+	if n < 1 {
+		n = 1
+	}
+
+	go func() {
+		errs := make([]error, len(cols))
+		sem := make(chan struct{}, n)
+		var wg sync.WaitGroup
+		for i, c := range cols {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(i int, c column) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+
+				if err := ctx.Err(); err != nil {
+					errs[i] = err
+					return
+				}
+
+				r := io.NewSectionReader(p.ra, c.pg.Offset, int64(c.pg.Size))
+				if err := c.f.Read(r, c.pg); err != nil {
+					errs[i] = fmt.Errorf("unable to read field %s, err: %w", c.f.Name(), err)
+					return
+				}
+
+				_, reps := c.f.Levels()
+				errs[i] = parquet.CheckRecords(c.f.Name(), c.pg, reps, rg.Rows)
+			}(i, c)
+		}
+		wg.Wait()
+
+		for _, err := range errs {
+			if err != nil {
+				out <- fetchedRowGroup{err: err}
+				return
+			}
+		}
+		out <- fetchedRowGroup{fields: fields}
+	}()
+	return out
+}
+
 func (p *ParquetReader) Rows() int64 {
 	return p.rows
 }
 
 func (p *ParquetReader) Next() bool {
-	if p.err == nil && p.cursor >= p.rows {
+	return p.NextContext(context.Background())
+}
+
+// NextContext is Next with a context.  It returns false (and Error
+// returns ctx.Err()) once ctx is done, even if every record has
+// already been read, and it keeps returning false once the reader
+// has failed.
+func (p *ParquetReader) NextContext(ctx context.Context) bool {
+	if p.err != nil {
+		return false
+	}
+
+	if err := ctx.Err(); err != nil {
+		p.err = err
+		return false
+	}
+
+	if p.cursor >= p.rows {
 		return false
 	}
+
 	if p.rowGroupCursor >= p.rowGroupCount {
-		p.err = p.readRowGroup()
+		p.err = p.readRowGroup(ctx)
 		if p.err != nil {
 			return false
 		}
@@ -770,6 +1274,56 @@ func (p *ParquetReader) Scan(x *Document) {
 	}
 }
 
+// ReadBatch reads up to len(dst) records into dst, one column at a
+// time, and returns the number of records read.  Each record is
+// reset before it is read into, so dst can be reused.  It returns
+// io.EOF once all the records have been read.
+func (p *ParquetReader) ReadBatch(dst []Document) (int, error) {
+	var n int
+	for n < len(dst) && p.err == nil && p.cursor < p.rows {
+		if p.rowGroupCursor >= p.rowGroupCount {
+			p.err = p.readRowGroup(context.Background())
+			if p.err != nil || p.rowGroupCount == 0 {
+				break
+			}
+		}
+
+		m := int64(len(dst) - n)
+		if r := p.rowGroupCount - p.rowGroupCursor; r < m {
+			m = r
+		}
+
+		batch := dst[n : n+int(m)]
+		for i := range batch {
+			batch[i] = Document{}
+		}
+
+		for _, name := range p.fieldNames {
+			if k := p.fields[name].ScanBatch(batch); k < len(batch) {
+				p.err = fmt.Errorf("column %s has no more values: %w", name, io.EOF)
+				break
+			}
+		}
+
+		if p.err != nil {
+			break
+		}
+
+		p.cursor += m
+		p.rowGroupCursor += m
+		n += int(m)
+	}
+
+	if p.err != nil {
+		return n, p.err
+	}
+
+	if n == 0 && len(dst) > 0 {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
 type StringOptionalField struct {
 	parquet.OptionalField
 	vals  []string
@@ -779,16 +1333,17 @@ type StringOptionalField struct {
 }
 
 func NewStringOptionalField(read func(r Document, vals []string, def, rep []uint8) ([]string, []uint8, []uint8), write func(r *Document, vals []string, defs, reps []uint8) (int, int), path []string, types []int, opts ...func(*parquet.OptionalField)) *StringOptionalField {
-	return &StringOptionalField{
+	f := &StringOptionalField{
 		read:          read,
 		write:         write,
 		OptionalField: parquet.NewOptionalField(path, types, opts...),
-		stats:         newStringOptionalStats(maxDef(types)),
 	}
+	f.stats = newStringOptionalStats(f.MaxLevels.Def)
+	return f
 }
 
 func (f *StringOptionalField) Schema() parquet.Field {
-	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: StringType, RepetitionType: f.RepetitionType, Types: f.Types}
+	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: parquet.StringType, RepetitionType: f.RepetitionType, Types: f.Types, IDs: f.IDs()}
 }
 
 func (f *StringOptionalField) Add(r Document) {
@@ -799,9 +1354,42 @@ func (f *StringOptionalField) Add(r Document) {
 	f.Reps = reps
 }
 
-func (f *StringOptionalField) Scan(r *Document) {
+// AddBatch adds the values and levels of each of rs.  It
+// assumes each record has (at least) one value.
+func (f *StringOptionalField) AddBatch(rs []Document) {
+	if n := len(f.vals) + len(rs); n > cap(f.vals) {
+		vals := make([]string, len(f.vals), n)
+		copy(vals, f.vals)
+		f.vals = vals
+	}
+
+	if n := len(f.Defs) + len(rs); n > cap(f.Defs) {
+		defs := make([]uint8, len(f.Defs), n)
+		copy(defs, f.Defs)
+		f.Defs = defs
+	}
+
+	if n := len(f.Reps) + len(rs); f.MaxLevels.Rep > 0 && n > cap(f.Reps) {
+		reps := make([]uint8, len(f.Reps), n)
+		copy(reps, f.Reps)
+		f.Reps = reps
+	}
+
+	vals, defs, reps := f.vals, f.Defs, f.Reps
+	for _, r := range rs {
+		vals, defs, reps = f.read(r, vals, defs, reps)
+	}
+	f.stats.add(vals[len(f.vals):], defs[len(f.Defs):])
+	f.vals = vals
+	f.Defs = defs
+	f.Reps = reps
+}
+
+// Scan writes the values of the next record to r.  It returns
+// false if there are no more records.
+func (f *StringOptionalField) Scan(r *Document) bool {
 	if len(f.Defs) == 0 {
-		return
+		return false
 	}
 
 	v, l := f.write(r, f.vals, f.Defs, f.Reps)
@@ -810,11 +1398,36 @@ func (f *StringOptionalField) Scan(r *Document) {
 	if len(f.Reps) > 0 {
 		f.Reps = f.Reps[l:]
 	}
+	return true
+}
+
+// ScanBatch writes the values of the next len(rs) records to rs
+// and returns the number of records it wrote, which is less than
+// len(rs) if the column has run out of values.
+func (f *StringOptionalField) ScanBatch(rs []Document) int {
+	var n, v, l int
+	for ; n < len(rs) && l < len(f.Defs); n++ {
+		var reps []uint8
+		if len(f.Reps) > 0 {
+			reps = f.Reps[l:]
+		}
+
+		vv, ll := f.write(&rs[n], f.vals[v:], f.Defs[l:], reps)
+		v += vv
+		l += ll
+	}
+
+	f.vals = f.vals[v:]
+	f.Defs = f.Defs[l:]
+	if len(f.Reps) > 0 {
+		f.Reps = f.Reps[l:]
+	}
+	return n
 }
 
 func (f *StringOptionalField) Write(w io.Writer, meta *parquet.Metadata) error {
-	buf := buffpool.Get()
-	defer buffpool.Put(buf)
+	buf := parquet.GetBuffer()
+	defer parquet.PutBuffer(buf)
 
 	bs := make([]byte, 4)
 	for _, s := range f.vals {
@@ -840,7 +1453,7 @@ func (f *StringOptionalField) Read(r io.ReadSeeker, pg parquet.Page) error {
 			return err
 		}
 		s := make([]byte, x)
-		if _, err := rr.Read(s); err != nil {
+		if _, err := io.ReadFull(rr, s); err != nil {
 			return err
 		}
 
@@ -917,80 +1530,3 @@ func (s *stringOptionalStats) Max() []byte {
 	}
 	return []byte(s.max)
 }
-
-func pint32(i int32) *int32       { return &i }
-func puint32(i uint32) *uint32    { return &i }
-func pint64(i int64) *int64       { return &i }
-func puint64(i uint64) *uint64    { return &i }
-func pbool(b bool) *bool          { return &b }
-func pstring(s string) *string    { return &s }
-func pfloat32(f float32) *float32 { return &f }
-func pfloat64(f float64) *float64 { return &f }
-
-// keeps track of the indices of repeated fields
-// that have already been handled by a previous field
-type indices []int
-
-func (i indices) rep(rep uint8) {
-	if rep > 0 {
-		r := int(rep) - 1
-		i[r] = i[r] + 1
-		for j := int(rep); j < len(i); j++ {
-			i[j] = 0
-		}
-	}
-}
-
-func maxDef(types []int) uint8 {
-	var out uint8
-	for _, typ := range types {
-		if typ > 0 {
-			out++
-		}
-	}
-	return out
-}
-
-func Int32Type(se *sch.SchemaElement) {
-	t := sch.Type_INT32
-	se.Type = &t
-}
-
-func Uint32Type(se *sch.SchemaElement) {
-	t := sch.Type_INT32
-	se.Type = &t
-	ct := sch.ConvertedType_UINT_32
-	se.ConvertedType = &ct
-}
-
-func Int64Type(se *sch.SchemaElement) {
-	t := sch.Type_INT64
-	se.Type = &t
-}
-
-func Uint64Type(se *sch.SchemaElement) {
-	t := sch.Type_INT64
-	se.Type = &t
-	ct := sch.ConvertedType_UINT_64
-	se.ConvertedType = &ct
-}
-
-func Float32Type(se *sch.SchemaElement) {
-	t := sch.Type_FLOAT
-	se.Type = &t
-}
-
-func Float64Type(se *sch.SchemaElement) {
-	t := sch.Type_DOUBLE
-	se.Type = &t
-}
-
-func BoolType(se *sch.SchemaElement) {
-	t := sch.Type_BOOLEAN
-	se.Type = &t
-}
-
-func StringType(se *sch.SchemaElement) {
-	t := sch.Type_BYTE_ARRAY
-	se.Type = &t
-}
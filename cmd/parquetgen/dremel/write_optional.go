@@ -73,7 +73,7 @@ type defCases struct {
 func writeOptional(f fields.Field) string {
 	wi := writeInput{
 		Field:    f,
-		FuncName: strings.Join(f.FieldNames(), ""),
+		FuncName: f.FuncName(),
 		Cases:    writeOptionalCases(f),
 	}
 
@@ -61,7 +61,7 @@ func init() {
 
 	writeRepeatedTpl, err = template.New("output").Funcs(funcs).Parse(`func {{.Func}}(x *{{.Field.StructType}}, vals []{{removeStar .Field.TypeName}}, defs, reps []uint8) (int, int) {
 	var nVals, nLevels int
-	ind := make(indices, {{.Field.MaxRep}})
+	ind := make(parquet.Indices, {{.Field.MaxRep}})
 
 	for i := range defs {
 		def := defs[i]
@@ -71,7 +71,7 @@ func init() {
 		}
 
 		nLevels++
-		ind.rep(rep)
+		ind.Rep(rep)
 
 		{{template "defSwitch" .}}
 	}
@@ -104,7 +104,7 @@ func init() {
 func writeRepeated(f fields.Field) string {
 	wi := writeRepeatedInput{
 		Field: f,
-		Func:  fmt.Sprintf("write%s", strings.Join(f.FieldNames(), "")),
+		Func:  fmt.Sprintf("write%s", f.FuncName()),
 		Defs:  writeCases(f),
 	}
 
@@ -35,7 +35,7 @@ func TestWrite(t *testing.T) {
 	def := defs[0]
 	switch def {
 	case 1:
-		x.ID = pint32(vals[0])
+		x.ID = parquet.Int32Ptr(vals[0])
 		return 1, 1
 	}
 
@@ -68,7 +68,7 @@ func TestWrite(t *testing.T) {
 	case 1:
 		x.Hobby = &Hobby{}
 	case 2:
-		x.Hobby = &Hobby{Difficulty: pint32(vals[0])}
+		x.Hobby = &Hobby{Difficulty: parquet.Int32Ptr(vals[0])}
 		return 1, 1
 	}
 
@@ -87,7 +87,7 @@ func TestWrite(t *testing.T) {
 	def := defs[0]
 	switch def {
 	case 2:
-		x.Hobby.Difficulty = pint32(vals[0])
+		x.Hobby.Difficulty = parquet.Int32Ptr(vals[0])
 		return 1, 1
 	}
 
@@ -123,7 +123,7 @@ func TestWrite(t *testing.T) {
 	def := defs[0]
 	switch def {
 	case 1:
-		x.Hobby.Name = pstring(vals[0])
+		x.Hobby.Name = parquet.StringPtr(vals[0])
 		return 1, 1
 	}
 
@@ -145,7 +145,7 @@ func TestWrite(t *testing.T) {
 	case 1:
 		x.Friend = &Entity{}
 	case 2:
-		x.Friend = &Entity{Hobby: Item{Name: pstring(vals[0])}}
+		x.Friend = &Entity{Hobby: Item{Name: parquet.StringPtr(vals[0])}}
 		return 1, 1
 	}
 
@@ -166,7 +166,7 @@ func TestWrite(t *testing.T) {
 	def := defs[0]
 	switch def {
 	case 2:
-		x.Friend.Hobby.Name = pstring(vals[0])
+		x.Friend.Hobby.Name = parquet.StringPtr(vals[0])
 		return 1, 1
 	}
 
@@ -212,7 +212,7 @@ func TestWrite(t *testing.T) {
 	case 2:
 		x.Friend = &Entity{Hobby: &Item{}}
 	case 3:
-		x.Friend = &Entity{Hobby: &Item{Name: pstring(vals[0])}}
+		x.Friend = &Entity{Hobby: &Item{Name: parquet.StringPtr(vals[0])}}
 		return 1, 1
 	}
 
@@ -235,7 +235,7 @@ func TestWrite(t *testing.T) {
 	case 2:
 		x.Friend.Hobby = &Item{}
 	case 3:
-		x.Friend.Hobby = &Item{Name: pstring(vals[0])}
+		x.Friend.Hobby = &Item{Name: parquet.StringPtr(vals[0])}
 		return 1, 1
 	}
 
@@ -263,7 +263,7 @@ func TestWrite(t *testing.T) {
 	case 3:
 		x.Friend = &Entity{Hobby: &Item{Name: &Name{}}}
 	case 4:
-		x.Friend = &Entity{Hobby: &Item{Name: &Name{First: pstring(vals[0])}}}
+		x.Friend = &Entity{Hobby: &Item{Name: &Name{First: parquet.StringPtr(vals[0])}}}
 		return 1, 1
 	}
 
@@ -290,7 +290,7 @@ func TestWrite(t *testing.T) {
 	case 3:
 		x.Friend.Hobby = &Item{Name: &Name{}}
 	case 4:
-		x.Friend.Hobby = &Item{Name: &Name{First: pstring(vals[0])}}
+		x.Friend.Hobby = &Item{Name: &Name{First: parquet.StringPtr(vals[0])}}
 		return 1, 1
 	}
 
@@ -316,7 +316,7 @@ func TestWrite(t *testing.T) {
 	case 2:
 		x.Friend.Hobby = &Item{Name: &Name{}}
 	case 3:
-		x.Friend.Hobby = &Item{Name: &Name{First: pstring(vals[0])}}
+		x.Friend.Hobby = &Item{Name: &Name{First: parquet.StringPtr(vals[0])}}
 		return 1, 1
 	}
 
@@ -343,7 +343,7 @@ func TestWrite(t *testing.T) {
 	case 2:
 		x.Friend.Hobby = &Item{Name: &Name{}}
 	case 3:
-		x.Friend.Hobby = &Item{Name: &Name{First: pstring(vals[0])}}
+		x.Friend.Hobby = &Item{Name: &Name{First: parquet.StringPtr(vals[0])}}
 		return 1, 1
 	}
 
@@ -415,7 +415,7 @@ func TestWrite(t *testing.T) {
 			},
 			result: `func writeLinkBackward(x *Document, vals []string, defs, reps []uint8) (int, int) {
 	var nVals, nLevels int
-	ind := make(indices, 1)
+	ind := make(parquet.Indices, 1)
 
 	for i := range defs {
 		def := defs[i]
@@ -425,7 +425,7 @@ func TestWrite(t *testing.T) {
 		}
 
 		nLevels++
-		ind.rep(rep)
+		ind.Rep(rep)
 
 		switch def {
 		case 1:
@@ -455,7 +455,7 @@ func TestWrite(t *testing.T) {
 			},
 			result: `func writeLinkForward(x *Document, vals []string, defs, reps []uint8) (int, int) {
 	var nVals, nLevels int
-	ind := make(indices, 1)
+	ind := make(parquet.Indices, 1)
 
 	for i := range defs {
 		def := defs[i]
@@ -465,7 +465,7 @@ func TestWrite(t *testing.T) {
 		}
 
 		nLevels++
-		ind.rep(rep)
+		ind.Rep(rep)
 
 		switch def {
 		case 2:
@@ -489,7 +489,7 @@ func TestWrite(t *testing.T) {
 			},
 			result: `func writeNamesLanguagesCode(x *Document, vals []string, defs, reps []uint8) (int, int) {
 	var nVals, nLevels int
-	ind := make(indices, 2)
+	ind := make(parquet.Indices, 2)
 
 	for i := range defs {
 		def := defs[i]
@@ -499,7 +499,7 @@ func TestWrite(t *testing.T) {
 		}
 
 		nLevels++
-		ind.rep(rep)
+		ind.Rep(rep)
 
 		switch def {
 		case 1:
@@ -531,7 +531,7 @@ func TestWrite(t *testing.T) {
 			},
 			result: `func writeNamesLanguagesCountry(x *Document, vals []string, defs, reps []uint8) (int, int) {
 	var nVals, nLevels int
-	ind := make(indices, 2)
+	ind := make(parquet.Indices, 2)
 
 	for i := range defs {
 		def := defs[i]
@@ -541,11 +541,11 @@ func TestWrite(t *testing.T) {
 		}
 
 		nLevels++
-		ind.rep(rep)
+		ind.Rep(rep)
 
 		switch def {
 		case 3:
-			x.Names[ind[0]].Languages[ind[1]].Country = pstring(vals[nVals])
+			x.Names[ind[0]].Languages[ind[1]].Country = parquet.StringPtr(vals[nVals])
 			nVals++
 		}
 	}
@@ -563,7 +563,7 @@ func TestWrite(t *testing.T) {
 			},
 			result: `func writeFriendsID(x *Person, vals []int32, defs, reps []uint8) (int, int) {
 	var nVals, nLevels int
-	ind := make(indices, 1)
+	ind := make(parquet.Indices, 1)
 
 	for i := range defs {
 		def := defs[i]
@@ -573,7 +573,7 @@ func TestWrite(t *testing.T) {
 		}
 
 		nLevels++
-		ind.rep(rep)
+		ind.Rep(rep)
 
 		switch def {
 		case 1:
@@ -593,7 +593,7 @@ func TestWrite(t *testing.T) {
 			},
 			result: `func writeLuckyNumbers(x *Document, vals []int64, defs, reps []uint8) (int, int) {
 	var nVals, nLevels int
-	ind := make(indices, 1)
+	ind := make(parquet.Indices, 1)
 
 	for i := range defs {
 		def := defs[i]
@@ -603,7 +603,7 @@ func TestWrite(t *testing.T) {
 		}
 
 		nLevels++
-		ind.rep(rep)
+		ind.Rep(rep)
 
 		switch def {
 		case 1:
@@ -626,7 +626,7 @@ func TestWrite(t *testing.T) {
 			},
 			result: `func writeLinkForward(x *Document, vals []string, defs, reps []uint8) (int, int) {
 	var nVals, nLevels int
-	ind := make(indices, 1)
+	ind := make(parquet.Indices, 1)
 
 	for i := range defs {
 		def := defs[i]
@@ -636,7 +636,7 @@ func TestWrite(t *testing.T) {
 		}
 
 		nLevels++
-		ind.rep(rep)
+		ind.Rep(rep)
 
 		switch def {
 		case 2:
@@ -661,7 +661,7 @@ func TestWrite(t *testing.T) {
 			},
 			result: `func writeHobbySkillsDifficulty(x *Person, vals []string, defs, reps []uint8) (int, int) {
 	var nVals, nLevels int
-	ind := make(indices, 1)
+	ind := make(parquet.Indices, 1)
 
 	for i := range defs {
 		def := defs[i]
@@ -671,7 +671,7 @@ func TestWrite(t *testing.T) {
 		}
 
 		nLevels++
-		ind.rep(rep)
+		ind.Rep(rep)
 
 		switch def {
 		case 2:
@@ -700,7 +700,7 @@ func TestWrite(t *testing.T) {
 			},
 			result: `func writeLinksForwardCountries(x *Document, vals []string, defs, reps []uint8) (int, int) {
 	var nVals, nLevels int
-	ind := make(indices, 3)
+	ind := make(parquet.Indices, 3)
 
 	for i := range defs {
 		def := defs[i]
@@ -710,7 +710,7 @@ func TestWrite(t *testing.T) {
 		}
 
 		nLevels++
-		ind.rep(rep)
+		ind.Rep(rep)
 
 		switch def {
 		case 3:
@@ -738,7 +738,7 @@ func TestWrite(t *testing.T) {
 			},
 			result: `func writeLinksForwardCodes(x *Doc, vals []string, defs, reps []uint8) (int, int) {
 	var nVals, nLevels int
-	ind := make(indices, 3)
+	ind := make(parquet.Indices, 3)
 
 	for i := range defs {
 		def := defs[i]
@@ -748,7 +748,7 @@ func TestWrite(t *testing.T) {
 		}
 
 		nLevels++
-		ind.rep(rep)
+		ind.Rep(rep)
 
 		switch def {
 		case 2:
@@ -784,3 +784,49 @@ func TestWrite(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteBatch(t *testing.T) {
+	testCases := []struct {
+		name   string
+		field  fields.Field
+		result string
+	}{
+		{
+			name: "not nested",
+			field: fields.Field{
+				Type: "int32", Name: "ID", RepetitionType: fields.Required,
+			},
+			result: `func scanID(xs []Person, vals []int32) {
+	for i := range xs {
+		xs[i].ID = vals[i]
+	}
+}`,
+		},
+		{
+			name: "nested",
+			field: fields.Field{
+				Name: "Other", RepetitionType: fields.Required, Children: []fields.Field{
+					{Name: "Hobby", RepetitionType: fields.Required, Children: []fields.Field{
+						{Type: "string", Name: "Name", RepetitionType: fields.Required},
+					}},
+				},
+			},
+			result: `func scanOtherHobbyName(xs []Person, vals []string) {
+	for i := range xs {
+		xs[i].Other.Hobby.Name = vals[i]
+	}
+}`,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%02d %s", i, tc.name), func(t *testing.T) {
+			flds := fields.Field{Type: "Person", Children: []fields.Field{tc.field}}.Fields()
+			f := flds[len(flds)-1]
+			s := dremel.WriteBatch(f)
+			gocode, err := format.Source([]byte(s))
+			assert.NoError(t, err)
+			assert.Equal(t, tc.result, string(gocode))
+		})
+	}
+}
@@ -17,6 +17,15 @@ type Field struct {
 	Embedded       bool
 	NthChild       int
 	Defined        bool
+
+	// ID is the field ID from the id option of the field's
+	// parquet tag (0 if it doesn't have one).
+	ID int32
+
+	// Prefix is only set on the root field.  It is prepended
+	// to the generated type and function names so that more
+	// than one struct can be generated in the same package.
+	Prefix string
 }
 
 type input struct {
@@ -45,6 +54,20 @@ func (f Field) IsRoot() bool {
 	return f.Parent == nil
 }
 
+func (f Field) root() Field {
+	for f.Parent != nil {
+		f = *f.Parent
+	}
+	return f
+}
+
+// FuncName is the name that is used (along with a read or write
+// prefix) for the generated functions that encode and decode
+// this field.
+func (f Field) FuncName() string {
+	return f.root().Prefix + strings.Join(f.FieldNames(), "")
+}
+
 func (f Field) fields(i int) []Field {
 	var out []Field
 	for j, fld := range f.Children {
@@ -114,6 +137,24 @@ func (f Field) ColumnNames() []string {
 	return out
 }
 
+// IDs returns the field ID of each of the column's fields (see
+// ColumnNames), or nil if none of them have one.
+func (f Field) IDs() []int32 {
+	var out []int32
+	var ok bool
+	for _, fld := range Reverse(f.Chain()) {
+		if fld.ColumnName != "" {
+			out = append(out, fld.ID)
+			ok = ok || fld.ID != 0
+		}
+	}
+
+	if !ok {
+		return nil
+	}
+	return out
+}
+
 func (f Field) RepetitionTypes() RepetitionTypes {
 	var out []RepetitionType
 	for _, fld := range Reverse(f.Chain()) {
@@ -400,15 +441,15 @@ func (f Field) Init(def, rep int) string {
 		case Optional:
 			if fld.Primitive() {
 				if f.NthChild == 0 && fld.Parent.Optional() && !fld.Parent.Repeated() {
-					right = fmt.Sprintf(right, fmt.Sprintf("%s: p%s(vals[0])%%s", fld.Name, fld.Type))
+					right = fmt.Sprintf(right, fmt.Sprintf("%s: %s(vals[0])%%s", fld.Name, ptrFunc(fld.Type)))
 				} else if fld.Parent.RepetitionType == Repeated {
-					right = fmt.Sprintf(right, fmt.Sprintf("p%s(vals[nVals])%%s", fld.Type))
+					right = fmt.Sprintf(right, fmt.Sprintf("%s(vals[nVals])%%s", ptrFunc(fld.Type)))
 				} else if fld.Parent.Repeated() && f.NthChild == 0 {
-					right = fmt.Sprintf(right, fmt.Sprintf("%s: p%s(vals[nVals])%%s", fld.Name, fld.Type))
+					right = fmt.Sprintf(right, fmt.Sprintf("%s: %s(vals[nVals])%%s", fld.Name, ptrFunc(fld.Type)))
 				} else if fld.Parent.Repeated() && f.NthChild > 0 {
-					right = fmt.Sprintf(right, fmt.Sprintf("p%s(vals[nVals])%%s", fld.Type))
+					right = fmt.Sprintf(right, fmt.Sprintf("%s(vals[nVals])%%s", ptrFunc(fld.Type)))
 				} else {
-					right = fmt.Sprintf(right, fmt.Sprintf("p%s(vals[0])%%s", fld.Type))
+					right = fmt.Sprintf(right, fmt.Sprintf("%s(vals[0])%%s", ptrFunc(fld.Type)))
 				}
 			} else {
 				if j == 0 {
@@ -486,7 +527,13 @@ func (f Field) FieldType() string {
 	}
 
 	ft := primitiveTypes[f.Type]
-	return fmt.Sprintf(ft.name, op, "Field")
+	return f.root().Prefix + fmt.Sprintf(ft.name, op, "Field")
+}
+
+// ptrFunc returns the parquet package func that returns
+// a pointer to a value of type typ (parquet.Int32Ptr, etc).
+func ptrFunc(typ string) string {
+	return fmt.Sprintf("parquet.%s%sPtr", strings.ToUpper(typ[:1]), typ[1:])
 }
 
 func (f Field) ParquetType() string {
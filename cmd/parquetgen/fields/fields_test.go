@@ -244,7 +244,7 @@ func TestInit(t *testing.T) {
 				}},
 			},
 			def:      2,
-			expected: "x.Friend = &Entity{Hobby: Item{Name: pstring(vals[0])}}",
+			expected: "x.Friend = &Entity{Hobby: Item{Name: parquet.StringPtr(vals[0])}}",
 		},
 		{
 			fields: []fields.Field{
@@ -255,7 +255,7 @@ func TestInit(t *testing.T) {
 				}},
 			},
 			def:      3,
-			expected: "x.Friend = &Entity{Hobby: &Item{Name: pstring(vals[0])}}",
+			expected: "x.Friend = &Entity{Hobby: &Item{Name: parquet.StringPtr(vals[0])}}",
 		},
 		{
 			fields: []fields.Field{
@@ -267,7 +267,7 @@ func TestInit(t *testing.T) {
 				}},
 			},
 			def:      3,
-			expected: "x.Friend.Hobby = &Item{Name: pstring(vals[0])}",
+			expected: "x.Friend.Hobby = &Item{Name: parquet.StringPtr(vals[0])}",
 		},
 		{
 			fields: []fields.Field{
@@ -285,7 +285,7 @@ func TestInit(t *testing.T) {
 				}},
 			},
 			def:      2,
-			expected: "x.Hobby = &Hobby{Difficulty: pint32(vals[0])}",
+			expected: "x.Hobby = &Hobby{Difficulty: parquet.Int32Ptr(vals[0])}",
 		},
 		{
 			fields: []fields.Field{
@@ -295,7 +295,7 @@ func TestInit(t *testing.T) {
 				}},
 			},
 			def:      2,
-			expected: "x.Hobby.Difficulty = pint32(vals[0])",
+			expected: "x.Hobby.Difficulty = parquet.Int32Ptr(vals[0])",
 		},
 		{
 			fields: []fields.Field{
@@ -313,7 +313,7 @@ func TestInit(t *testing.T) {
 				}},
 			},
 			def:      1,
-			expected: "x.Hobby.Name = pstring(vals[0])",
+			expected: "x.Hobby.Name = parquet.StringPtr(vals[0])",
 		},
 		{
 			fields: []fields.Field{
@@ -331,7 +331,7 @@ func TestInit(t *testing.T) {
 				}},
 			},
 			def:      2,
-			expected: "x.Hobby = &Item{Name: pstring(vals[0])}",
+			expected: "x.Hobby = &Item{Name: parquet.StringPtr(vals[0])}",
 		},
 		{
 			fields: []fields.Field{
@@ -342,7 +342,7 @@ func TestInit(t *testing.T) {
 				}},
 			},
 			def:      3,
-			expected: "x.Friend = &Entity{Hobby: &Item{Name: pstring(vals[0])}}",
+			expected: "x.Friend = &Entity{Hobby: &Item{Name: parquet.StringPtr(vals[0])}}",
 		},
 		{
 			fields: []fields.Field{
@@ -390,7 +390,7 @@ func TestInit(t *testing.T) {
 			},
 			def:      3,
 			rep:      0,
-			expected: "x.Names[ind[0]].Languages[ind[1]].Country = pstring(vals[nVals])",
+			expected: "x.Names[ind[0]].Languages[ind[1]].Country = parquet.StringPtr(vals[nVals])",
 		},
 		{
 			fields: []fields.Field{
@@ -443,7 +443,7 @@ func TestInit(t *testing.T) {
 				}},
 			},
 			def:      3,
-			expected: "x.Friend.Hobby.Name.First = pstring(vals[0])",
+			expected: "x.Friend.Hobby.Name.First = parquet.StringPtr(vals[0])",
 		},
 		{
 			fields: []fields.Field{
@@ -514,7 +514,7 @@ func TestInit(t *testing.T) {
 			},
 			def:      3,
 			rep:      0,
-			expected: "x.A.B = &B{C: C{D: []D{{E: E{F: pstring(vals[nVals])}}}}}",
+			expected: "x.A.B = &B{C: C{D: []D{{E: E{F: parquet.StringPtr(vals[nVals])}}}}}",
 		},
 		{
 			fields: []fields.Field{
@@ -532,7 +532,7 @@ func TestInit(t *testing.T) {
 				}},
 			},
 			def:      3,
-			expected: "x.A.B.C.D[ind[0]].E.F = pstring(vals[nVals])",
+			expected: "x.A.B.C.D[ind[0]].E.F = parquet.StringPtr(vals[nVals])",
 		},
 		{
 			fields: []fields.Field{
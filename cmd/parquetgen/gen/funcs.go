@@ -21,15 +21,34 @@ var (
 		"camelCaseRemoveStar": func(s string) string {
 			return cases.Camel(strings.Replace(strings.Replace(s, "*", "", 1), "[]", "", 1))
 		},
-		"dedupe": dedupe,
+		"dedupe":      dedupe,
+		"dedupeStats": dedupeStats,
 		"compressionFunc": func(f fields.Field) string {
 			if strings.Contains(f.Category(), "Optional") {
-				return "optionalFieldCompression"
+				return "parquet.OptionalFieldCompression"
 			}
-			return "fieldCompression"
+			return "parquet.RequiredFieldCompression"
+		},
+		// fieldIDs returns the option that sets the field IDs of f (if it has any)
+		"fieldIDs": func(f fields.Field) string {
+			ids := f.IDs()
+			if ids == nil {
+				return ""
+			}
+
+			strs := make([]string, len(ids))
+			for i, id := range ids {
+				strs[i] = fmt.Sprintf("%d", id)
+			}
+
+			opt := "parquet.RequiredFieldIDs"
+			if strings.Contains(f.Category(), "Optional") {
+				opt = "parquet.OptionalFieldIDs"
+			}
+			return fmt.Sprintf(", %s(%s)", opt, strings.Join(strs, ", "))
 		},
 		"funcName": func(f fields.Field) string {
-			return strings.Join(f.FieldNames(), "")
+			return f.FuncName()
 		},
 		"join": func(names []string) string {
 			return strings.Join(names, ".")
@@ -41,17 +60,24 @@ var (
 			}
 			return strings.Join(names, ", ")
 		},
-		"imports": func(fields []fields.Field) []string {
+		"imports": func(types []typeInput) []string {
 			var out []string
-			var intFound bool
-			for _, f := range fields {
-				if !intFound && strings.Contains(f.Type, "int") {
-					intFound = true
-					out = append(out, `"math"`)
+			for _, t := range types {
+				for _, f := range t.Parent.Fields() {
+					if strings.Contains(f.Type, "int") {
+						return append(out, `"math"`)
+					}
 				}
 			}
 			return out
 		},
+		// ident prefixes an unexported identifier: ident "Person" "begin" is personBegin
+		"ident": func(prefix, name string) string {
+			if prefix == "" {
+				return name
+			}
+			return strings.ToLower(prefix[:1]) + prefix[1:] + strings.ToUpper(name[:1]) + name[1:]
+		},
 		"maxType": func(f fields.Field) string {
 			var out string
 			switch f.Type {
@@ -70,11 +96,16 @@ var (
 			}
 			return out
 		},
-		"columnName":    func(f fields.Field) string { return strings.Join(f.ColumnNames(), ".") },
-		"writeFunc":     dremel.Write,
-		"readFunc":      dremel.Read,
-		"writeFuncName": func(f fields.Field) string { return fmt.Sprintf("write%s", strings.Join(f.FieldNames(), "")) },
-		"readFuncName":  func(f fields.Field) string { return fmt.Sprintf("read%s", strings.Join(f.FieldNames(), "")) },
+		"compareFunc":        compareFunc,
+		"columnName":         func(f fields.Field) string { return strings.Join(f.ColumnNames(), ".") },
+		"writeFunc":          dremel.Write,
+		"readFunc":           dremel.Read,
+		"writeFuncName":      func(f fields.Field) string { return fmt.Sprintf("write%s", f.FuncName()) },
+		"writeBatchFunc":     dremel.WriteBatch,
+		"writeBatchFuncName": func(f fields.Field) string { return fmt.Sprintf("scan%s", f.FuncName()) },
+		"readFuncName":       func(f fields.Field) string { return fmt.Sprintf("read%s", f.FuncName()) },
+		"readBatchFunc":      dremel.ReadBatch,
+		"readBatchFuncName":  func(f fields.Field) string { return fmt.Sprintf("add%s", f.FuncName()) },
 		"parquetType": func(f fields.Field) string {
 			if f.Optional() {
 				return "parquet.OptionalField"
@@ -135,3 +166,33 @@ var (
 		},
 	}
 )
+
+// compareFunc returns the body of a func that compares the values
+// of (non-repeated) field f of records a and b.  Missing values are
+// less than all other values.
+func compareFunc(f fields.Field) string {
+	out := fmt.Sprintf("x, y := read%[1]s(a), read%[1]s(b)\n", f.FuncName())
+	x, y := "x", "y"
+	if f.Optional() {
+		out = fmt.Sprintf(`xs, _, _ := read%[1]s(a, nil, nil, nil)
+		ys, _, _ := read%[1]s(b, nil, nil, nil)
+		if len(xs) == 0 || len(ys) == 0 {
+			return len(xs) - len(ys)
+		}
+		`, f.FuncName())
+		x, y = "xs[0]", "ys[0]"
+	}
+
+	less, greater := fmt.Sprintf("%s < %s", x, y), fmt.Sprintf("%s > %s", x, y)
+	if strings.TrimPrefix(f.Type, "*") == "bool" {
+		less, greater = fmt.Sprintf("!%s && %s", x, y), fmt.Sprintf("%s && !%s", x, y)
+	}
+
+	return out + fmt.Sprintf(`switch {
+		case %s:
+			return -1
+		case %s:
+			return 1
+		}
+		return 0`, less, greater)
+}
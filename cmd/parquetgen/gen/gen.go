@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"go/format"
 	"os"
+	"strings"
 	"text/template"
 
 	"github.com/parsyl/parquet"
@@ -26,31 +27,54 @@ var (
 )
 
 // FromStruct generates a parquet reader and writer based on the struct
-// of type 'typ' that is defined in the go file at 'pth'.
-func FromStruct(pth, outPth, typ, pkg, imp string, ignore bool) error {
-	result, err := parse.Fields(typ, pth)
-	if err != nil {
-		return err
-	}
-
-	if len(result.Errors) > 0 && !ignore {
-		return fmt.Errorf("not generating parquet.go (-ignore set to false), err: %v", result.Errors)
-	}
-
+// of type 'typ' that is defined in the go file at 'pth'.  'typ' may be a
+// comma separated list of types.  When more than one type is given every
+// generated type and function is prefixed with the name of its struct
+// (PersonParquetWriter, NewPersonParquetReader, etc) so that they can all
+// live in the same package.  If errs is true the generated Add and
+// Scan return an error.
+func FromStruct(pth, outPth, typ, pkg, imp string, ignore, errs bool) error {
+	types := strings.Split(typ, ",")
 	i := input{
 		Package: pkg,
-		Type:    typ,
 		Import:  getImport(imp),
-		Parent:  result.Parent,
+	}
+
+	for _, t := range types {
+		t = strings.TrimSpace(t)
+		result, err := parse.Fields(t, pth)
+		if err != nil {
+			return err
+		}
+
+		if len(result.Errors) > 0 && !ignore {
+			return fmt.Errorf("not generating parquet.go (-ignore set to false), err: %v", result.Errors)
+		}
+
+		if err := checkDepth(result.Parent); err != nil {
+			return err
+		}
+
+		if len(types) > 1 {
+			result.Parent.Prefix = getPrefix(t)
+		}
+
+		i.Types = append(i.Types, typeInput{
+			Prefix: result.Parent.Prefix,
+			Type:   t,
+			Parent: result.Parent,
+			Errors: errs,
+		})
 	}
 
 	tmpl := template.New("output").Funcs(funcs)
-	tmpl, err = tmpl.Parse(tpl)
+	tmpl, err := tmpl.Parse(tpl)
 	if err != nil {
 		return err
 	}
 
 	for _, t := range []string{
+		typeTpl,
 		requiredNumericTpl,
 		optionalNumericTpl,
 		stringTpl,
@@ -98,7 +122,11 @@ func FromStruct(pth, outPth, typ, pkg, imp string, ignore bool) error {
 
 // FromParquet generates a go struct, a reader, and a writer based
 // on the parquet file at 'parq'
-func FromParquet(parq, pth, outPth, typ, pkg, imp string, ignore bool) error {
+func FromParquet(parq, pth, outPth, typ, pkg, imp string, ignore, errs bool) error {
+	if strings.Contains(typ, ",") {
+		return fmt.Errorf("only one -type can be generated from a parquet file, got %s", typ)
+	}
+
 	pf, err := os.Open(parq)
 	if err != nil {
 		return err
@@ -144,14 +172,40 @@ func FromParquet(parq, pth, outPth, typ, pkg, imp string, ignore bool) error {
 	}
 
 	f.Close()
-	return FromStruct(pth, outPth, typ, pkg, imp, ignore)
+	return FromStruct(pth, outPth, typ, pkg, imp, ignore, errs)
+}
+
+// checkDepth returns an error if any of the columns of parent is
+// nested in more optional and repeated fields than the definition
+// and repetition levels can hold.
+func checkDepth(parent fields.Field) error {
+	for _, f := range parent.Fields() {
+		rts := f.RepetitionTypes()
+		types := make([]int, len(rts))
+		for i, rt := range rts {
+			types[i] = int(rt)
+		}
+
+		if err := parquet.CheckDepth(strings.Join(f.ColumnNames(), "."), types); err != nil {
+			return fmt.Errorf("not generating parquet.go, %s", err)
+		}
+	}
+	return nil
 }
 
 type input struct {
 	Package string
-	Type    string
 	Import  string
-	Parent  fields.Field
+	Types   []typeInput
+}
+
+type typeInput struct {
+	Prefix string
+	Type   string
+	Parent fields.Field
+
+	// Errors is true if Add and Scan return an error.
+	Errors bool
 }
 
 func getFieldType(se *sch.SchemaElement) (string, error) {
@@ -189,6 +243,30 @@ func dedupe(flds []fields.Field) []fields.Field {
 	return out
 }
 
+// dedupeStats returns one field for each of the stats types
+// that are needed by all the generated types.
+func dedupeStats(types []typeInput) []fields.Field {
+	seen := map[string]bool{}
+	var out []fields.Field
+	for _, t := range types {
+		for _, f := range t.Parent.Fields() {
+			k := f.Category() + strings.Replace(f.TypeName(), "*", "", 1)
+			if !seen[k] {
+				out = append(out, f)
+				seen[k] = true
+			}
+		}
+	}
+	return out
+}
+
+// getPrefix turns a (possibly package qualified)
+// type name into an identifier prefix.
+func getPrefix(typ string) string {
+	parts := strings.Split(typ, ".")
+	return strings.Title(parts[len(parts)-1])
+}
+
 func getImport(i string) string {
 	if i == "" {
 		return ""
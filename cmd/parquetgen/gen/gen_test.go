@@ -0,0 +1,395 @@
+package gen_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/parsyl/parquet"
+	"github.com/parsyl/parquet/cmd/parquetgen/gen"
+	"github.com/parsyl/parquet/cmd/parquetgen/gen/testcases/checked"
+	"github.com/parsyl/parquet/cmd/parquetgen/gen/testcases/deep"
+	"github.com/parsyl/parquet/cmd/parquetgen/gen/testcases/ids"
+	"github.com/parsyl/parquet/cmd/parquetgen/gen/testcases/multiple"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMultipleTypes verifies that the code generated for more than one
+// type in a single package can write and read each of the types.
+func TestMultipleTypes(t *testing.T) {
+	people := []multiple.Person{
+		{ID: 1, Name: "Fred", Age: pint32(30), Friends: []int64{2, 3}},
+		{ID: 2, Name: "Wilma"},
+	}
+
+	places := []multiple.Place{
+		{ID: 1, Name: pstring("Bedrock"), Latitude: 1.5, Longitude: -2.5, Visited: true, Tags: []string{"rocks"}},
+		{ID: 2, Latitude: 3.5, Longitude: 4.5},
+	}
+
+	var buf bytes.Buffer
+	pw, err := multiple.NewPersonParquetWriter(&buf, multiple.PersonMaxPageSize(1))
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, p := range people {
+		pw.Add(p)
+	}
+	assert.NoError(t, pw.Write())
+	assert.NoError(t, pw.Close())
+
+	pr, err := multiple.NewPersonParquetReader(bytes.NewReader(buf.Bytes()))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var outPeople []multiple.Person
+	for pr.Next() {
+		var p multiple.Person
+		pr.Scan(&p)
+		outPeople = append(outPeople, p)
+	}
+	assert.NoError(t, pr.Error())
+	assert.Equal(t, people, outPeople)
+
+	buf.Reset()
+	plw, err := multiple.NewPlaceParquetWriter(&buf, multiple.PlaceUncompressed)
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, p := range places {
+		plw.Add(p)
+	}
+	assert.NoError(t, plw.Write())
+	assert.NoError(t, plw.Close())
+
+	plr, err := multiple.NewPlaceParquetReader(bytes.NewReader(buf.Bytes()))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var outPlaces []multiple.Place
+	for plr.Next() {
+		var p multiple.Place
+		plr.Scan(&p)
+		outPlaces = append(outPlaces, p)
+	}
+	assert.NoError(t, plr.Error())
+	assert.Equal(t, places, outPlaces)
+}
+
+// TestErrors verifies that the code generated with -errors returns
+// an error from Add and Scan instead of failing silently.
+func TestErrors(t *testing.T) {
+	people := []checked.Person{
+		{ID: 1, Name: "Fred", Age: pint32(30), Friends: []int64{2, 3}},
+		{ID: 2, Name: "Wilma"},
+	}
+
+	var buf bytes.Buffer
+	w, err := checked.NewParquetWriter(&buf, checked.MaxPageSize(0))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.EqualError(t, w.Add(people[0]), "invalid max page size: 0")
+
+	buf.Reset()
+	w, err = checked.NewParquetWriter(&buf, checked.MaxPageSize(1))
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, p := range people {
+		assert.NoError(t, w.Add(p))
+	}
+	assert.NoError(t, w.Write())
+	assert.NoError(t, w.Close())
+
+	r, err := checked.NewParquetReader(bytes.NewReader(buf.Bytes()))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var out []checked.Person
+	for r.Next() {
+		var p checked.Person
+		assert.NoError(t, r.Scan(&p))
+		out = append(out, p)
+	}
+	assert.NoError(t, r.Error())
+	assert.Equal(t, people, out)
+
+	var p checked.Person
+	err = r.Scan(&p)
+	assert.EqualError(t, err, "column id has no more values: EOF")
+	assert.True(t, errors.Is(err, io.EOF))
+	assert.Equal(t, checked.Person{}, p)
+
+	// a file that is missing the friends column
+	buf.Reset()
+	fw, err := parquet.NewFileWriter(&buf, []parquet.Field{
+		{Path: []string{"id"}, Types: []int{0}, Type: parquet.Int32Type, RepetitionType: parquet.RepetitionRequired},
+		{Path: []string{"name"}, Types: []int{0}, Type: parquet.StringType, RepetitionType: parquet.RepetitionRequired},
+		{Path: []string{"age"}, Types: []int{1}, Type: parquet.Int32Type, RepetitionType: parquet.RepetitionOptional},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, fw.StartRowGroup(1))
+	assert.NoError(t, fw.WriteColumn("id", []int32{1}, nil, nil))
+	assert.NoError(t, fw.WriteColumn("name", []string{"Fred"}, nil, nil))
+	assert.NoError(t, fw.WriteColumn("age", []int32{}, []uint8{0}, nil))
+	assert.NoError(t, fw.Close())
+
+	_, err = checked.NewParquetReader(bytes.NewReader(buf.Bytes()))
+	assert.Equal(t, &parquet.RecordCountError{Column: "friends", Expected: 1}, err)
+}
+
+// TestDeep verifies that records that are nested more than 15
+// optional levels deep (which needs more than 4 bits for each
+// definition level) can be written and read.
+func TestDeep(t *testing.T) {
+	records := []deep.Record{
+		{ID: 1, Level: &deep.L1{Next: &deep.L2{Next: &deep.L3{Next: &deep.L4{Next: &deep.L5{Next: &deep.L6{Next: &deep.L7{Next: &deep.L8{Next: &deep.L9{Next: &deep.L10{Next: &deep.L11{Next: &deep.L12{Next: &deep.L13{Next: &deep.L14{Next: &deep.L15{Next: &deep.L16{Next: &deep.L17{Value: pint32(7), Values: []int32{1, 2, 3}}}}}}}}}}}}}}}}}}},
+		{ID: 2, Level: &deep.L1{Next: &deep.L2{Next: &deep.L3{}}}},
+		{ID: 3},
+	}
+
+	var buf bytes.Buffer
+	w, err := deep.NewParquetWriter(&buf, deep.MaxPageSize(2))
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, rec := range records {
+		w.Add(rec)
+	}
+	assert.NoError(t, w.Write())
+	assert.NoError(t, w.Close())
+
+	r, err := deep.NewParquetReader(bytes.NewReader(buf.Bytes()))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var out []deep.Record
+	for r.Next() {
+		var rec deep.Record
+		r.Scan(&rec)
+		out = append(out, rec)
+	}
+	assert.NoError(t, r.Error())
+	assert.Equal(t, records, out)
+}
+
+// TestMaxDepth verifies that parquetgen refuses to generate code
+// for a struct that is nested too deep for its levels.
+func TestMaxDepth(t *testing.T) {
+	dir, err := ioutil.TempDir("", "parquetgen")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	src := []string{"package deep", "type Record struct { Level *L0 `parquet:\"level\"` }"}
+	for i := 0; i < parquet.MaxDepth; i++ {
+		src = append(src, fmt.Sprintf("type L%d struct { Next *L%d `parquet:\"next\"` }", i, i+1))
+	}
+	src = append(src, fmt.Sprintf("type L%d struct { Value int32 `parquet:\"value\"` }", parquet.MaxDepth))
+
+	pth := filepath.Join(dir, "deep.go")
+	if !assert.NoError(t, ioutil.WriteFile(pth, []byte(strings.Join(src, "\n")), 0644)) {
+		return
+	}
+
+	err = gen.FromStruct(pth, filepath.Join(dir, "generated.go"), "Record", "deep", "", true, false)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "has 256 optional and repeated levels (at most 255 are supported)")
+	}
+}
+
+// TestFieldIDs verifies that the ids of parquet tags are written
+// to the schema and that columns that have been renamed are read
+// by their field IDs.
+func TestFieldIDs(t *testing.T) {
+	records := []ids.Record{
+		{ID: 1, Name: pstring("a"), Address: &ids.Address{City: "x"}, Note: "n"},
+		{ID: 2},
+	}
+
+	var buf bytes.Buffer
+	w, err := ids.NewParquetWriter(&buf)
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, rec := range records {
+		w.Add(rec)
+	}
+	assert.NoError(t, w.Write())
+	assert.NoError(t, w.Close())
+
+	footer, err := parquet.ReadMetaData(bytes.NewReader(buf.Bytes()))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	fieldIDs := map[string]*int32{}
+	for _, se := range footer.Schema {
+		fieldIDs[se.Name] = se.FieldID
+	}
+	assert.Equal(t, map[string]*int32{
+		"root":    nil,
+		"id":      pint32(1),
+		"name":    pint32(2),
+		"address": pint32(3),
+		"city":    pint32(4),
+		"note":    nil,
+	}, fieldIDs)
+
+	// the same columns with different names
+	type location struct {
+		Town string `parquet:"town,id=4"`
+	}
+
+	type renamed struct {
+		Key      int64     `parquet:"key,id=1"`
+		FullName *string   `parquet:"full_name,id=2"`
+		Location *location `parquet:"location,id=3"`
+		Note     string    `parquet:"note"`
+	}
+
+	buf.Reset()
+	rw, err := parquet.NewWriter(&buf, reflect.TypeOf(renamed{}))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, rw.Add(renamed{Key: 1, FullName: pstring("a"), Location: &location{Town: "x"}, Note: "n"}))
+	assert.NoError(t, rw.Add(renamed{Key: 2}))
+	assert.NoError(t, rw.Close())
+
+	r, err := ids.NewParquetReader(bytes.NewReader(buf.Bytes()))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var out []ids.Record
+	for r.Next() {
+		var rec ids.Record
+		r.Scan(&rec)
+		out = append(out, rec)
+	}
+	assert.NoError(t, r.Error())
+	assert.Equal(t, records, out)
+
+	// name (id 2) has been renamed to nickname and a new column
+	// took its old name
+	type collision struct {
+		Key      int64     `parquet:"key,id=1"`
+		Name     *string   `parquet:"name,id=5"`
+		Nickname *string   `parquet:"nickname,id=2"`
+		Location *location `parquet:"location,id=3"`
+		Note     string    `parquet:"note"`
+	}
+
+	buf.Reset()
+	rw, err = parquet.NewWriter(&buf, reflect.TypeOf(collision{}))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, rw.Add(collision{Key: 1, Name: pstring("b"), Nickname: pstring("a")}))
+	assert.NoError(t, rw.Close())
+
+	_, err = ids.NewParquetReader(bytes.NewReader(buf.Bytes()))
+	var se *parquet.ErrSchemaMismatch
+	if assert.True(t, errors.As(err, &se), err) {
+		assert.Equal(t, "name", se.Column)
+	}
+	assert.EqualError(t, err, "column name: the file's column nickname has the field's ID, but the file's column name has its name")
+}
+
+// TestAppendFieldIDs verifies that rows can only be appended to a
+// file whose field IDs match the generated code's.
+func TestAppendFieldIDs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "parquetgen")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	type address struct {
+		City string `parquet:"city,id=4"`
+	}
+
+	type record struct {
+		ID      int64    `parquet:"id,id=1"`
+		Name    *string  `parquet:"name,id=7"`
+		Address *address `parquet:"address,id=3"`
+		Note    string   `parquet:"note"`
+	}
+
+	for _, tc := range []struct {
+		name string
+		typ  reflect.Type
+		err  string
+	}{
+		{name: "same ids", typ: reflect.TypeOf(ids.Record{})},
+		{name: "different ids", typ: reflect.TypeOf(record{}), err: "column name: field IDs [2] don't match the file's [7]"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := ioutil.TempFile(dir, "ids")
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer f.Close()
+
+			rw, err := parquet.NewWriter(f, tc.typ)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.NoError(t, rw.Add(reflect.New(tc.typ).Elem().Interface()))
+			assert.NoError(t, rw.Close())
+
+			w, err := ids.NewParquetAppender(f)
+			if tc.err != "" {
+				var se *parquet.ErrSchemaMismatch
+				assert.True(t, errors.As(err, &se), err)
+				assert.EqualError(t, err, tc.err)
+				return
+			}
+
+			if !assert.NoError(t, err) {
+				return
+			}
+			w.Add(ids.Record{ID: 2})
+			assert.NoError(t, w.Write())
+			assert.NoError(t, w.Close())
+		})
+	}
+}
+
+// TestInvalidFieldID verifies that parquetgen refuses to generate
+// code for a tag with an invalid field ID.
+func TestInvalidFieldID(t *testing.T) {
+	dir, err := ioutil.TempDir("", "parquetgen")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	pth := filepath.Join(dir, "ids.go")
+	src := "package ids\ntype Record struct { ID int64 `parquet:\"id,id=x\"` }"
+	if !assert.NoError(t, ioutil.WriteFile(pth, []byte(src), 0644)) {
+		return
+	}
+
+	err = gen.FromStruct(pth, filepath.Join(dir, "generated.go"), "Record", "ids", "", true, false)
+	assert.EqualError(t, err, `field ID: invalid field ID "x", it must be a positive int32`)
+}
+
+func pint32(i int32) *int32    { return &i }
+func pstring(s string) *string { return &s }
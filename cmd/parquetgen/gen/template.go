@@ -1,44 +1,59 @@
 package gen
 
-var newFieldTpl = `{{define "newField"}}New{{.FieldType}}({{readFuncName .}}, {{writeFuncName .}}, []string{ {{.Path}} }{{if not .Required}}, []int{ {{joinTypes .RepetitionTypes}} }{{end}}, {{compressionFunc .}}(compression)),{{end}}`
+var newFieldTpl = `{{define "newField"}}New{{.FieldType}}({{readFuncName .}}, {{if .Required}}{{readBatchFuncName .}}, {{end}}{{writeFuncName .}}, {{if .Required}}{{writeBatchFuncName .}}, {{end}}[]string{ {{.Path}} }{{if not .Required}}, []int{ {{joinTypes .RepetitionTypes}} }{{end}}, {{compressionFunc .}}(compression){{fieldIDs .}}),{{end}}`
 
 var tpl = `package {{.Package}}
 
 // Code generated by github.com/parsyl/parquet.  DO NOT EDIT.
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"strings"
 	"encoding/binary"
+	"sort"
+	"sync"
 
 	"github.com/valyala/bytebufferpool"
 	"github.com/parsyl/parquet"
 	sch "github.com/parsyl/parquet/schema"
 	{{.Import}}
-	{{range imports .Parent.Fields}}{{.}}
+	{{range imports .Types}}{{.}}
 	{{end}}
 )
 
-type compression int
-
-const (
-	compressionUncompressed compression = 0
-	compressionSnappy       compression = 1
-	compressionGzip         compression = 2
-	compressionUnknown      compression = -1
-)
-
-var buffpool = bytebufferpool.Pool{}
+{{range .Types}}{{template "parquetType" .}}{{end}}
+{{range dedupeStats .Types}}
+{{if eq .Category "numeric"}}
+{{ template "requiredStats" .}}
+{{end}}
+{{if eq .Category "numericOptional"}}
+{{ template "optionalStats" .}}
+{{end}}
+{{if eq .Category "string"}}
+{{ template "stringStats" .}}
+{{end}}
+{{if eq .Category "stringOptional"}}
+{{ template "stringOptionalStats" .}}
+{{end}}
+{{if eq .Category "bool"}}
+{{ template "boolStats" .}}
+{{end}}
+{{if eq .Category "boolOptional"}}
+{{ template "boolOptionalStats" .}}
+{{end}}
+{{end}}
+`
 
-// ParquetWriter reprents a row group
-type ParquetWriter struct {
-	fields []Field
+var typeTpl = `{{define "parquetType"}}// {{.Prefix}}ParquetWriter reprents a row group
+type {{.Prefix}}ParquetWriter struct {
+	fields []{{.Prefix}}Field
 
 	len int
 
 	// child points to the next page
-	child *ParquetWriter
+	child *{{.Prefix}}ParquetWriter
 
 	// max is the number of Record items that can get written before
 	// a new set of column chunks is written
@@ -46,56 +61,97 @@ type ParquetWriter struct {
 
 	meta *parquet.Metadata
 	w    io.Writer
-	compression compression
+	compression sch.CompressionCodec
+
+	// concurrency is the number of columns that are
+	// encoded and compressed at the same time by Write
+	concurrency int
+
+	// checksums is true if each page header
+	// includes a CRC32 of the page's data
+	checksums bool
+
+	// encodings holds the encoding of each column
+	// that isn't PLAIN encoded
+	encodings map[string]sch.Encoding
+
+	// keyValues and createdBy are written to
+	// the footer
+	keyValues [][2]string
+	createdBy *string
+
+	// sortBy holds the columns that each row group is sorted by,
+	// along with their compare funcs, and records holds the records
+	// that have been added since the last Write
+	sortBy   []string
+	compares []func(a, b {{.Parent.StructType}}) int
+	records  []{{.Parent.StructType}}
+
+	// appended is the file that New{{.Prefix}}ParquetAppender
+	// writes to.  Close truncates it to the end of the new
+	// footer, which can be shorter than the old one.
+	appended {{ident .Prefix "truncater"}}
 }
 
-func Fields(compression compression) []Field {
-	return []Field{ {{range .Parent.Fields}}
+type {{ident .Prefix "truncater"}} interface {
+	io.Seeker
+	Truncate(size int64) error
+}
+
+func {{.Prefix}}Fields(compression sch.CompressionCodec) []{{.Prefix}}Field {
+	return []{{.Prefix}}Field{ {{range .Parent.Fields}}
 		{{template "newField" .}}{{end}}
 	}
 }
 
 {{range $i, $field := .Parent.Fields}}{{readFunc $field}}
 
-{{writeFunc $field}}
+{{if $field.Required}}{{readBatchFunc $field}}
 
-{{end}}
+{{end}}{{writeFunc $field}}
 
-func fieldCompression(c compression) func(*parquet.RequiredField) {
-	switch c {
-	case compressionUncompressed:
-		return parquet.RequiredFieldUncompressed
-	case compressionSnappy:
-		return parquet.RequiredFieldSnappy
-	case compressionGzip:
-		return parquet.RequiredFieldGzip
-	default:
-		return parquet.RequiredFieldUncompressed
-	}
+{{if $field.Required}}{{writeBatchFunc $field}}
+
+{{end}}{{end}}
+
+func New{{.Prefix}}ParquetWriter(w io.Writer, opts ...func(*{{.Prefix}}ParquetWriter) error) (*{{.Prefix}}ParquetWriter, error) {
+	return new{{.Prefix}}ParquetWriter(w, append(opts, {{ident .Prefix "begin"}})...)
 }
 
-func optionalFieldCompression(c compression) func(*parquet.OptionalField) {
-	switch c {
-	case compressionUncompressed:
-		return parquet.OptionalFieldUncompressed
-	case compressionSnappy:
-		return parquet.OptionalFieldSnappy
-	case compressionGzip:
-		return parquet.OptionalFieldGzip
-	default:
-		return parquet.OptionalFieldUncompressed
+// New{{.Prefix}}ParquetAppender creates a writer that adds row groups to the
+// existing parquet file f.  It reads f's footer, checks that the file's
+// schema matches {{.Type}}'s and then writes the new row groups over the
+// footer.  Close writes a footer that has both the existing and the new
+// row groups and truncates f to the end of it, so f must also have a
+// Truncate(int64) error method (like *os.File).
+func New{{.Prefix}}ParquetAppender(f io.ReadWriteSeeker, opts ...func(*{{.Prefix}}ParquetWriter) error) (*{{.Prefix}}ParquetWriter, error) {
+	t, ok := f.({{ident .Prefix "truncater"}})
+	if !ok {
+		return nil, fmt.Errorf("appending requires a file that can be truncated, got %T", f)
 	}
-}
 
-func NewParquetWriter(w io.Writer, opts ...func(*ParquetWriter) error) (*ParquetWriter, error) {
-	return newParquetWriter(w, append(opts, begin)...)
+	p, err := new{{.Prefix}}ParquetWriter(f, opts...)
+	if err != nil {
+		return nil, err
+	}
+	p.appended = t
+
+	offset, err := p.meta.AppendTo(f)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return p, nil
 }
 
-func newParquetWriter(w io.Writer, opts ...func(*ParquetWriter) error) (*ParquetWriter, error) {
-	p := &ParquetWriter{
+func new{{.Prefix}}ParquetWriter(w io.Writer, opts ...func(*{{.Prefix}}ParquetWriter) error) (*{{.Prefix}}ParquetWriter, error) {
+	p := &{{.Prefix}}ParquetWriter{
 		max:         1000,
 		w:           w,
-		compression: compressionSnappy,
+		compression: sch.CompressionCodec_SNAPPY,
 	}
 
 	for _, opt := range opts {
@@ -104,77 +160,183 @@ func newParquetWriter(w io.Writer, opts ...func(*ParquetWriter) error) (*Parquet
 		}
 	}
 
-	p.fields = Fields(p.compression)
+	p.fields = {{.Prefix}}Fields(p.compression)
 	if p.meta == nil {
-		ff := Fields(p.compression)
+		ff := {{.Prefix}}Fields(p.compression)
 		schema := make([]parquet.Field, len(ff))
 		for i, f := range ff {
 			schema[i] = f.Schema()
 		}
 		p.meta = parquet.New(schema...)
+		if p.checksums {
+			p.meta.EnableChecksums()
+		}
+		for col, enc := range p.encodings {
+			if err := p.meta.SetEncoding(col, enc); err != nil {
+				return nil, err
+			}
+		}
+		for _, kv := range p.keyValues {
+			p.meta.SetKeyValue(kv[0], kv[1])
+		}
+		if p.createdBy != nil {
+			p.meta.SetCreatedBy(*p.createdBy)
+		}
+		if len(p.sortBy) > 0 {
+			if err := p.meta.SetSortingColumns(p.sortBy...); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	return p, nil
 }
 
-// MaxPageSize is the maximum number of rows in each row groups' page.
-func MaxPageSize(m int) func(*ParquetWriter) error {
-	return func(p *ParquetWriter) error {
+// {{.Prefix}}MaxPageSize is the maximum number of rows in each row groups' page.
+func {{.Prefix}}MaxPageSize(m int) func(*{{.Prefix}}ParquetWriter) error {
+	return func(p *{{.Prefix}}ParquetWriter) error {
 		p.max = m
 		return nil
 	}
 }
 
-var par1 = []byte("PAR1")
+// {{.Prefix}}Concurrency sets the number of columns that are encoded and
+// compressed in parallel by Write.  Each column is written to its own
+// buffer and the buffers are then written in the order of the schema.
+func {{.Prefix}}Concurrency(n int) func(*{{.Prefix}}ParquetWriter) error {
+	return func(p *{{.Prefix}}ParquetWriter) error {
+		if n < 1 {
+			return fmt.Errorf("invalid concurrency: %d", n)
+		}
+		p.concurrency = n
+		return nil
+	}
+}
+
+// {{.Prefix}}Checksums adds a CRC32 of each page's data to its page header
+// so that readers can detect corrupt pages.
+func {{.Prefix}}Checksums(p *{{.Prefix}}ParquetWriter) error {
+	p.checksums = true
+	return nil
+}
 
-func begin(p *ParquetWriter) error {
-	_, err := p.w.Write(par1)
+// {{.Prefix}}Encoding sets the encoding of column's values (column is the
+// name of the column's fields, separated by dots).  New{{.Prefix}}ParquetWriter
+// returns an error if the column doesn't exist or if its type can't be
+// encoded with enc.
+func {{.Prefix}}Encoding(column string, enc sch.Encoding) func(*{{.Prefix}}ParquetWriter) error {
+	return func(p *{{.Prefix}}ParquetWriter) error {
+		if p.encodings == nil {
+			p.encodings = map[string]sch.Encoding{}
+		}
+		p.encodings[column] = enc
+		return nil
+	}
+}
+
+// {{.Prefix}}KeyValue adds a key/value pair to the metadata in the
+// file's footer.
+func {{.Prefix}}KeyValue(key, value string) func(*{{.Prefix}}ParquetWriter) error {
+	return func(p *{{.Prefix}}ParquetWriter) error {
+		p.keyValues = append(p.keyValues, [2]string{key, value})
+		return nil
+	}
+}
+
+// {{.Prefix}}CreatedBy sets the application that wrote the file (the
+// footer's created_by).
+func {{.Prefix}}CreatedBy(createdBy string) func(*{{.Prefix}}ParquetWriter) error {
+	return func(p *{{.Prefix}}ParquetWriter) error {
+		p.createdBy = &createdBy
+		return nil
+	}
+}
+
+// {{.Prefix}}SortBy makes Write sort the records of each row group by columns
+// (in ascending order, with missing values first) before they are encoded.
+// The sort order is recorded in the row groups' sorting_columns.  Repeated
+// columns can't be sorted by.
+func {{.Prefix}}SortBy(columns ...string) func(*{{.Prefix}}ParquetWriter) error {
+	return func(p *{{.Prefix}}ParquetWriter) error {
+		for _, col := range columns {
+			cmp, ok := {{ident .Prefix "compareFuncs"}}[col]
+			if !ok {
+				return fmt.Errorf("can't sort by column %s, it doesn't exist or is repeated", col)
+			}
+			p.compares = append(p.compares, cmp)
+		}
+		p.sortBy = columns
+		return nil
+	}
+}
+
+var {{ident .Prefix "compareFuncs"}} = map[string]func(a, b {{.Parent.StructType}}) int{ {{range .Parent.Fields}}{{if not .Repeated}}
+	"{{columnName .}}": func(a, b {{$.Parent.StructType}}) int {
+		{{compareFunc .}}
+	},{{end}}{{end}}
+}
+
+func {{ident .Prefix "begin"}}(p *{{.Prefix}}ParquetWriter) error {
+	_, err := io.WriteString(p.w, parquet.Magic)
 	return err
 }
 
-func withMeta(m *parquet.Metadata) func(*ParquetWriter) error {
-	return func(p *ParquetWriter) error {
+func {{ident .Prefix "withMeta"}}(m *parquet.Metadata) func(*{{.Prefix}}ParquetWriter) error {
+	return func(p *{{.Prefix}}ParquetWriter) error {
 		p.meta = m
 		return nil
 	}
 }
 
-func Uncompressed(p *ParquetWriter) error {
-	p.compression = compressionUncompressed
+func {{.Prefix}}Uncompressed(p *{{.Prefix}}ParquetWriter) error {
+	p.compression = sch.CompressionCodec_UNCOMPRESSED
 	return nil
 }
 
-func Snappy(p *ParquetWriter) error {
-	p.compression = compressionSnappy
+func {{.Prefix}}Snappy(p *{{.Prefix}}ParquetWriter) error {
+	p.compression = sch.CompressionCodec_SNAPPY
 	return nil
 }
 
-func Gzip(p *ParquetWriter) error {
-	p.compression = compressionGzip
+func {{.Prefix}}Gzip(p *{{.Prefix}}ParquetWriter) error {
+	p.compression = sch.CompressionCodec_GZIP
 	return nil
 }
 
-func withCompression(c compression) func(*ParquetWriter) error {
-	return func(p *ParquetWriter) error {
+func {{ident .Prefix "withCompression"}}(c sch.CompressionCodec) func(*{{.Prefix}}ParquetWriter) error {
+	return func(p *{{.Prefix}}ParquetWriter) error {
 		p.compression = c
 		return nil
 	}
 }
 
-func (p *ParquetWriter) Write() error {
-	for i, f := range p.fields {
-		if err := f.Write(p.w, p.meta); err != nil {
+func (p *{{.Prefix}}ParquetWriter) Write() error {
+	return p.WriteContext(context.Background())
+}
+
+// WriteContext is Write with a context.  ctx is checked before each
+// page is written and ctx.Err() is returned if it is done (after
+// which the file is incomplete and should be discarded).
+func (p *{{.Prefix}}ParquetWriter) WriteContext(ctx context.Context) error {
+	if p.compares != nil {
+		if err := p.addSorted(); err != nil {
 			return err
 		}
+	}
 
-		for child := p.child; child != nil; child = child.child {
-			if err := child.fields[i].Write(p.w, p.meta); err != nil {
+	if p.concurrency > 1 {
+		if err := p.writeConcurrently(ctx); err != nil {
+			return err
+		}
+	} else {
+		for i := range p.fields {
+			if err := p.writeColumn(ctx, p.w, i); err != nil {
 				return err
 			}
 		}
 	}
 
-	p.fields = Fields(p.compression)
+	p.fields = {{.Prefix}}Fields(p.compression)
 	p.child = nil
 	p.len = 0
 
@@ -186,20 +348,155 @@ func (p *ParquetWriter) Write() error {
 	return nil
 }
 
-func (p *ParquetWriter) Close() error {
-	if err := p.meta.Footer(p.w); err != nil {
+// addSorted sorts the records that have been added since the
+// last Write and adds them to the row group.
+func (p *{{.Prefix}}ParquetWriter) addSorted() error {
+	recs := p.records
+	p.records = nil
+	sort.SliceStable(recs, func(i, j int) bool {
+		for _, cmp := range p.compares {
+			if c := cmp(recs[i], recs[j]); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	return p.addBatch(recs)
+}
+
+// writeColumn writes the pages of the i'th column.
+func (p *{{.Prefix}}ParquetWriter) writeColumn(ctx context.Context, w io.Writer, i int) error {
+	if err := ctx.Err(); err != nil {
 		return err
 	}
 
-	_, err := p.w.Write(par1)
-	return err
+	if err := p.fields[i].Write(w, p.meta); err != nil {
+		return err
+	}
+
+	for child := p.child; child != nil; child = child.child {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := child.fields[i].Write(w, p.meta); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeConcurrently writes each column to its own buffer (using
+// up to p.concurrency goroutines) and then writes the buffers to
+// p.w in order.
+func (p *{{.Prefix}}ParquetWriter) writeConcurrently(ctx context.Context) error {
+	bufs := make([]*bytebufferpool.ByteBuffer, len(p.fields))
+	errs := make([]error, len(p.fields))
+	sem := make(chan struct{}, p.concurrency)
+
+	var wg sync.WaitGroup
+	for i := range p.fields {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			bufs[i] = parquet.GetBuffer()
+			errs[i] = p.writeColumn(ctx, bufs[i], i)
+		}(i)
+	}
+	wg.Wait()
+
+	defer func() {
+		for _, buf := range bufs {
+			parquet.PutBuffer(buf)
+		}
+	}()
+
+	for i, buf := range bufs {
+		if errs[i] != nil {
+			return errs[i]
+		}
+
+		if _, err := p.w.Write(buf.B); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *{{.Prefix}}ParquetWriter) Close() error {
+	return p.CloseContext(context.Background())
+}
+
+// CloseContext is Close with a context.  It returns ctx.Err() if
+// ctx is done before the metadata is written.
+func (p *{{.Prefix}}ParquetWriter) CloseContext(ctx context.Context) error {
+	if err := p.meta.FooterContext(ctx, p.w); err != nil {
+		return err
+	}
+
+	if _, err := io.WriteString(p.w, parquet.Magic); err != nil {
+		return err
+	}
+
+	if p.appended == nil {
+		return nil
+	}
+
+	end, err := p.appended.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	return p.appended.Truncate(end)
+}
+
+{{if .Errors}}
+// Add adds rec to the current row group.
+func (p *{{.Prefix}}ParquetWriter) Add(rec {{.Parent.StructType}}) error {
+	if p.max <= 0 {
+		return fmt.Errorf("invalid max page size: %d", p.max)
+	}
+
+	if p.compares != nil {
+		p.records = append(p.records, rec)
+		return nil
+	}
+
+	if p.len == p.max {
+		if p.child == nil {
+			child, err := new{{.Prefix}}ParquetWriter(p.w, {{.Prefix}}MaxPageSize(p.max), {{ident .Prefix "withMeta"}}(p.meta), {{ident .Prefix "withCompression"}}(p.compression))
+			if err != nil {
+				return err
+			}
+			p.child = child
+		}
+
+		return p.child.Add(rec)
+	}
+
+	p.meta.NextDoc()
+	for _, f := range p.fields {
+		f.Add(rec)
+	}
+
+	p.len++
+	return nil
 }
+{{else}}
+func (p *{{.Prefix}}ParquetWriter) Add(rec {{.Parent.StructType}}) {
+	if p.compares != nil {
+		p.records = append(p.records, rec)
+		return
+	}
 
-func (p *ParquetWriter) Add(rec {{.Parent.StructType}}) {
 	if p.len == p.max {
 		if p.child == nil {
 			// an error can't happen here
-			p.child, _ = newParquetWriter(p.w, MaxPageSize(p.max), withMeta(p.meta), withCompression(p.compression))
+			p.child, _ = new{{.Prefix}}ParquetWriter(p.w, {{.Prefix}}MaxPageSize(p.max), {{ident .Prefix "withMeta"}}(p.meta), {{ident .Prefix "withCompression"}}(p.compression))
 		}
 
 		p.child.Add(rec)
@@ -213,28 +510,78 @@ func (p *ParquetWriter) Add(rec {{.Parent.StructType}}) {
 
 	p.len++
 }
+{{end}}
 
-type Field interface {
+// AddBatch adds recs to the current row group.  Unlike calling
+// Add for each record it adds the records one column (and one page)
+// at a time, so each required column grows with a single append
+// per page.
+func (p *{{.Prefix}}ParquetWriter) AddBatch(recs []{{.Parent.StructType}}) error {
+	if p.max <= 0 {
+		return fmt.Errorf("invalid max page size: %d", p.max)
+	}
+
+	if p.compares != nil {
+		p.records = append(p.records, recs...)
+		return nil
+	}
+	return p.addBatch(recs)
+}
+
+func (p *{{.Prefix}}ParquetWriter) addBatch(recs []{{.Parent.StructType}}) error {
+	w := p
+	for len(recs) > 0 {
+		for w.len == w.max {
+			if w.child == nil {
+				child, err := new{{.Prefix}}ParquetWriter(p.w, {{.Prefix}}MaxPageSize(p.max), {{ident .Prefix "withMeta"}}(p.meta), {{ident .Prefix "withCompression"}}(p.compression))
+				if err != nil {
+					return err
+				}
+				w.child = child
+			}
+			w = w.child
+		}
+
+		n := w.max - w.len
+		if n > len(recs) {
+			n = len(recs)
+		}
+
+		batch := recs[:n]
+		for _, f := range w.fields {
+			f.AddBatch(batch)
+		}
+
+		p.meta.NextDocs(int64(n))
+
+		w.len += n
+		recs = recs[n:]
+	}
+	return nil
+}
+
+type {{.Prefix}}Field interface {
 	Add(r {{.Parent.StructType}})
+	AddBatch(rs []{{.Parent.StructType}})
 	Write(w io.Writer, meta *parquet.Metadata) error
 	Schema() parquet.Field
-	Scan(r *{{.Parent.StructType}})
+	Scan(r *{{.Parent.StructType}}) bool
+	ScanBatch(rs []{{.Parent.StructType}}) int
 	Read(r io.ReadSeeker, pg parquet.Page) error
 	Name() string
 	Levels() ([]uint8, []uint8)
 }
 
-func getFields(ff []Field) map[string]Field {
-	m := make(map[string]Field, len(ff))
+func {{ident .Prefix "getFields"}}(ff []{{.Prefix}}Field) map[string]{{.Prefix}}Field {
+	m := make(map[string]{{.Prefix}}Field, len(ff))
 	for _, f := range ff {
 		m[f.Name()] = f
 	}
 	return m
 }
 
-func NewParquetReader(r io.ReadSeeker, opts ...func(*ParquetReader)) (*ParquetReader, error) {
-	ff := Fields(compressionUnknown)
-	pr := &ParquetReader{
+func New{{.Prefix}}ParquetReader(r io.ReadSeeker, opts ...func(*{{.Prefix}}ParquetReader)) (*{{.Prefix}}ParquetReader, error) {
+	pr := &{{.Prefix}}ParquetReader{
 		r: r,
 	}
 
@@ -242,42 +589,91 @@ func NewParquetReader(r io.ReadSeeker, opts ...func(*ParquetReader)) (*ParquetRe
 		opt(pr)
 	}
 
-	schema := make([]parquet.Field, len(ff))
-	for i, f := range ff {
-		pr.fieldNames = append(pr.fieldNames, f.Name())
-		schema[i] = f.Schema()
+	if pr.concurrency > 0 || pr.prefetch {
+		ra, ok := r.(io.ReaderAt)
+		if !ok {
+			return nil, fmt.Errorf("concurrent reads require an io.ReaderAt, got %T", r)
+		}
+		pr.ra = ra
 	}
 
-	meta := parquet.New(schema...)
+	meta := pr.metadata()
 	if err := meta.ReadFooter(r); err != nil {
 		return nil, err
 	}
-	pr.rows = meta.Rows()
-	var err error
-	pr.pages, err = meta.Pages()
-	if err != nil {
+
+	return pr, pr.start(meta)
+}
+
+// New{{.Prefix}}ParquetReaderAt creates a reader that only reads the file (of the
+// given size) with ReadAt, so, unlike New{{.Prefix}}ParquetReader, it doesn't depend
+// on the seek position of r.
+func New{{.Prefix}}ParquetReaderAt(r io.ReaderAt, size int64, opts ...func(*{{.Prefix}}ParquetReader)) (*{{.Prefix}}ParquetReader, error) {
+	pr := &{{.Prefix}}ParquetReader{
+		ra: r,
+	}
+
+	for _, opt := range opts {
+		opt(pr)
+	}
+
+	meta := pr.metadata()
+	if err := meta.ReadFooterAt(r, size); err != nil {
 		return nil, err
 	}
 
-	pr.rowGroups = meta.RowGroups()
-	_, err = r.Seek(4, io.SeekStart)
+	return pr, pr.start(meta)
+}
+
+func (p *{{.Prefix}}ParquetReader) metadata() *parquet.Metadata {
+	ff := {{.Prefix}}Fields(sch.CompressionCodec_UNCOMPRESSED)
+	schema := make([]parquet.Field, len(ff))
+	for i, f := range ff {
+		p.fieldNames = append(p.fieldNames, f.Name())
+		schema[i] = f.Schema()
+	}
+	return parquet.New(schema...)
+}
+
+func (p *{{.Prefix}}ParquetReader) start(meta *parquet.Metadata) error {
+	p.rows = meta.Rows()
+	var err error
+	p.pages, err = meta.Pages()
 	if err != nil {
-		return nil, err
+		return err
 	}
-	pr.meta = meta
 
-	return pr, pr.readRowGroup()
+	p.rowGroups = meta.RowGroups()
+	p.meta = meta
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	return p.readRowGroup(context.Background())
 }
 
-func readerIndex(i int) func(*ParquetReader) {
-	return func(p *ParquetReader) {
+func {{ident .Prefix "readerIndex"}}(i int) func(*{{.Prefix}}ParquetReader) {
+	return func(p *{{.Prefix}}ParquetReader) {
 		p.index = i
 	}
 }
 
-// ParquetReader reads one page from a row group.
-type ParquetReader struct {
-	fields         map[string]Field
+// {{.Prefix}}ReadConcurrency makes the reader read and decode up to n columns
+// of a row group at the same time.  The io.ReadSeeker passed to
+// New{{.Prefix}}ParquetReader must also be an io.ReaderAt.
+func {{.Prefix}}ReadConcurrency(n int) func(*{{.Prefix}}ParquetReader) {
+	return func(p *{{.Prefix}}ParquetReader) {
+		p.concurrency = n
+	}
+}
+
+// {{.Prefix}}Prefetch makes the reader read and decode the next row group in
+// the background while the current one is being scanned.  The io.ReadSeeker
+// passed to New{{.Prefix}}ParquetReader must also be an io.ReaderAt.
+func {{.Prefix}}Prefetch(p *{{.Prefix}}ParquetReader) {
+	p.prefetch = true
+}
+
+// {{.Prefix}}ParquetReader reads one page from a row group.
+type {{.Prefix}}ParquetReader struct {
+	fields         map[string]{{.Prefix}}Field
 	fieldNames     []string
 	index          int
 	cursor         int64
@@ -290,21 +686,28 @@ type ParquetReader struct {
 
 	r         io.ReadSeeker
 	rowGroups []parquet.RowGroup
-}
 
-type Levels struct {
-	Name string
-	Defs []uint8
-	Reps []uint8
+	// ra is set when the reader was created by New{{.Prefix}}ParquetReaderAt
+	// or when the row groups are read concurrently (see
+	// {{.Prefix}}ReadConcurrency and {{.Prefix}}Prefetch).
+	ra          io.ReaderAt
+	concurrency int
+	prefetch    bool
+	prefetched  <-chan {{ident .Prefix "fetchedRowGroup"}}
+
+	// ctx bounds the row groups that are read in the background.
+	// It is cancelled by Close or once the reader fails.
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
-func (p *ParquetReader) Levels() []Levels {
-	var out []Levels
+func (p *{{.Prefix}}ParquetReader) Levels() []parquet.Levels {
+	var out []parquet.Levels
 	//for {
 	for _, name := range p.fieldNames {
 		f := p.fields[name]
 		d, r := f.Levels()
-		out = append(out, Levels{Name: f.Name(), Defs: d, Reps: r})
+		out = append(out, parquet.Levels{Name: f.Name(), Defs: d, Reps: r})
 	}
 	//	if err := p.readRowGroup(); err != nil {
 	//		break
@@ -313,11 +716,31 @@ func (p *ParquetReader) Levels() []Levels {
 	return out
 }
 
-func (p *ParquetReader) Error() error {
+func (p *{{.Prefix}}ParquetReader) Error() error {
 	return p.err
 }
 
-func (p *ParquetReader) readRowGroup() error {
+// Close stops reading row groups in the background (see
+// {{.Prefix}}Prefetch).  It doesn't close the underlying reader.
+func (p *{{.Prefix}}ParquetReader) Close() error {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	return nil
+}
+
+// KeyValueMetadata returns the key/value metadata of the file.
+func (p *{{.Prefix}}ParquetReader) KeyValueMetadata() map[string]string {
+	return p.meta.KeyValueMetadata()
+}
+
+// CreatedBy returns the application that wrote the file (if the
+// file says so).
+func (p *{{.Prefix}}ParquetReader) CreatedBy() string {
+	return p.meta.CreatedBy()
+}
+
+func (p *{{.Prefix}}ParquetReader) readRowGroup(ctx context.Context) error {
 	p.rowGroupCursor = 0
 
 	if len(p.rowGroups) == 0 {
@@ -326,40 +749,207 @@ func (p *ParquetReader) readRowGroup() error {
 	}
 
 	rg := p.rowGroups[0]
-	p.fields = getFields(Fields(compressionUnknown))
 	p.rowGroupCount = rg.Rows
-	p.rowGroupCursor = 0
+{{- if .Errors}}
+	if err := p.checkColumns(rg); err != nil {
+		return err
+	}
+{{- end}}
+	if p.ra != nil {
+		return p.readRowGroupAt(ctx)
+	}
+
+	p.fields = {{ident .Prefix "getFields"}}({{.Prefix}}Fields(sch.CompressionCodec_UNCOMPRESSED))
 	for _, col := range rg.Columns() {
 		name := strings.Join(col.MetaData.PathInSchema, ".")
 		f, ok := p.fields[name]
 		if !ok {
-			return fmt.Errorf("unknown field: %s", name)
+			return &parquet.ErrSchemaMismatch{Column: name, Reason: "unknown field"}
 		}
 		pages := p.pages[name]
 		if len(pages) <= p.index {
 			break
 		}
 
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		pg := pages[0]
+		if _, err := p.r.Seek(pg.Offset, io.SeekStart); err != nil {
+			return err
+		}
+
 		if err := f.Read(p.r, pg); err != nil {
-			return fmt.Errorf("unable to read field %s, err: %s", f.Name(), err)
+			return fmt.Errorf("unable to read field %s, err: %w", f.Name(), err)
+		}
+
+		_, reps := f.Levels()
+		if err := parquet.CheckRecords(name, pg, reps, rg.Rows); err != nil {
+			return err
 		}
 		p.pages[name] = p.pages[name][1:]
 	}
 	p.rowGroups = p.rowGroups[1:]
 	return nil
 }
+{{if .Errors}}
+// checkColumns makes sure rg has a column chunk for each field.
+func (p *{{.Prefix}}ParquetReader) checkColumns(rg parquet.RowGroup) error {
+	cols := map[string]bool{}
+	for _, col := range rg.Columns() {
+		cols[strings.Join(col.MetaData.PathInSchema, ".")] = true
+	}
+
+	for _, name := range p.fieldNames {
+		if !cols[name] {
+			return &parquet.RecordCountError{Column: name, Expected: rg.Rows}
+		}
+	}
+	return nil
+}
+{{end}}
+
+type {{ident .Prefix "fetchedRowGroup"}} struct {
+	fields map[string]{{.Prefix}}Field
+	err    error
+}
+
+// readRowGroupAt reads the next row group (unless it has already
+// been prefetched) and, if p.prefetch is set, starts fetching the
+// one after that.  The row groups are fetched under p.ctx, ctx only
+// bounds how long readRowGroupAt waits for the next one.
+func (p *{{.Prefix}}ParquetReader) readRowGroupAt(ctx context.Context) error {
+	next := p.prefetched
+	p.prefetched = nil
+	if next == nil {
+		next = p.fetchRowGroup(p.ctx, p.rowGroups[0])
+	}
+
+	p.rowGroups = p.rowGroups[1:]
+	if p.prefetch && len(p.rowGroups) > 0 {
+		p.prefetched = p.fetchRowGroup(p.ctx, p.rowGroups[0])
+	}
+
+	var res {{ident .Prefix "fetchedRowGroup"}}
+	select {
+	case res = <-next:
+	case <-ctx.Done():
+		p.cancel()
+		return ctx.Err()
+	}
+
+	if res.err != nil {
+		p.cancel()
+		return res.err
+	}
+
+	p.fields = res.fields
+	return nil
+}
+
+// fetchRowGroup reads and decodes the columns of rg in the
+// background, up to p.concurrency columns at a time.  It stops
+// reading columns once ctx is done.
+func (p *{{.Prefix}}ParquetReader) fetchRowGroup(ctx context.Context, rg parquet.RowGroup) <-chan {{ident .Prefix "fetchedRowGroup"}} {
+	type column struct {
+		f  {{.Prefix}}Field
+		pg parquet.Page
+	}
 
-func (p *ParquetReader) Rows() int64 {
+	out := make(chan {{ident .Prefix "fetchedRowGroup"}}, 1)
+	fields := {{ident .Prefix "getFields"}}({{.Prefix}}Fields(sch.CompressionCodec_UNCOMPRESSED))
+	var cols []column
+	for _, col := range rg.Columns() {
+		name := strings.Join(col.MetaData.PathInSchema, ".")
+		f, ok := fields[name]
+		if !ok {
+			out <- {{ident .Prefix "fetchedRowGroup"}}{err: &parquet.ErrSchemaMismatch{Column: name, Reason: "unknown field"}}
+			return out
+		}
+		pages := p.pages[name]
+		if len(pages) <= p.index {
+			break
+		}
+
+		cols = append(cols, column{f: f, pg: pages[0]})
+		p.pages[name] = p.pages[name][1:]
+	}
+
+	n := p.concurrency
+	if n < 1 {
+		n = 1
+	}
+
+	go func() {
+		errs := make([]error, len(cols))
+		sem := make(chan struct{}, n)
+		var wg sync.WaitGroup
+		for i, c := range cols {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(i int, c column) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+
+				if err := ctx.Err(); err != nil {
+					errs[i] = err
+					return
+				}
+
+				r := io.NewSectionReader(p.ra, c.pg.Offset, int64(c.pg.Size))
+				if err := c.f.Read(r, c.pg); err != nil {
+					errs[i] = fmt.Errorf("unable to read field %s, err: %w", c.f.Name(), err)
+					return
+				}
+
+				_, reps := c.f.Levels()
+				errs[i] = parquet.CheckRecords(c.f.Name(), c.pg, reps, rg.Rows)
+			}(i, c)
+		}
+		wg.Wait()
+
+		for _, err := range errs {
+			if err != nil {
+				out <- {{ident .Prefix "fetchedRowGroup"}}{err: err}
+				return
+			}
+		}
+		out <- {{ident .Prefix "fetchedRowGroup"}}{fields: fields}
+	}()
+	return out
+}
+
+func (p *{{.Prefix}}ParquetReader) Rows() int64 {
 	return p.rows
 }
 
-func (p *ParquetReader) Next() bool {
-	if p.err == nil && p.cursor >= p.rows {
+func (p *{{.Prefix}}ParquetReader) Next() bool {
+	return p.NextContext(context.Background())
+}
+
+// NextContext is Next with a context.  It returns false (and Error
+// returns ctx.Err()) once ctx is done, even if every record has
+// already been read, and it keeps returning false once the reader
+// has failed.
+func (p *{{.Prefix}}ParquetReader) NextContext(ctx context.Context) bool {
+	if p.err != nil {
+		return false
+	}
+
+	if err := ctx.Err(); err != nil {
+		p.err = err
+		return false
+	}
+
+	if p.cursor >= p.rows {
 		return false
 	}
+
 	if p.rowGroupCursor >= p.rowGroupCount {
-		p.err = p.readRowGroup()
+		p.err = p.readRowGroup(ctx)
 		if p.err != nil {
 			return false
 		}
@@ -370,7 +960,28 @@ func (p *ParquetReader) Next() bool {
 	return true
 }
 
-func (p *ParquetReader) Scan(x *{{.Parent.StructType}}) {
+{{if .Errors}}
+// Scan writes the current record to x.  It returns the reader's
+// error, if any, or an error that wraps io.EOF if one of the
+// columns has run out of values (x is only assigned once every
+// column has been scanned).
+func (p *{{.Prefix}}ParquetReader) Scan(x *{{.Parent.StructType}}) error {
+	if p.err != nil {
+		return p.err
+	}
+
+	rec := *x
+	for _, name := range p.fieldNames {
+		if !p.fields[name].Scan(&rec) {
+			return fmt.Errorf("column %s has no more values: %w", name, io.EOF)
+		}
+	}
+
+	*x = rec
+	return nil
+}
+{{else}}
+func (p *{{.Prefix}}ParquetReader) Scan(x *{{.Parent.StructType}}) {
 	if p.err != nil {
 		return
 	}
@@ -380,6 +991,57 @@ func (p *ParquetReader) Scan(x *{{.Parent.StructType}}) {
 		f.Scan(x)
 	}
 }
+{{end}}
+
+// ReadBatch reads up to len(dst) records into dst, one column at a
+// time, and returns the number of records read.  Each record is
+// reset before it is read into, so dst can be reused.  It returns
+// io.EOF once all the records have been read.
+func (p *{{.Prefix}}ParquetReader) ReadBatch(dst []{{.Parent.StructType}}) (int, error) {
+	var n int
+	for n < len(dst) && p.err == nil && p.cursor < p.rows {
+		if p.rowGroupCursor >= p.rowGroupCount {
+			p.err = p.readRowGroup(context.Background())
+			if p.err != nil || p.rowGroupCount == 0 {
+				break
+			}
+		}
+
+		m := int64(len(dst) - n)
+		if r := p.rowGroupCount - p.rowGroupCursor; r < m {
+			m = r
+		}
+
+		batch := dst[n : n+int(m)]
+		for i := range batch {
+			batch[i] = {{.Parent.StructType}}{}
+		}
+
+		for _, name := range p.fieldNames {
+			if k := p.fields[name].ScanBatch(batch); k < len(batch) {
+				p.err = fmt.Errorf("column %s has no more values: %w", name, io.EOF)
+				break
+			}
+		}
+
+		if p.err != nil {
+			break
+		}
+
+		p.cursor += m
+		p.rowGroupCursor += m
+		n += int(m)
+	}
+
+	if p.err != nil {
+		return n, p.err
+	}
+
+	if n == 0 && len(dst) > 0 {
+		return 0, io.EOF
+	}
+	return n, nil
+}
 
 {{range dedupe .Parent.Fields}}
 {{if eq .Category "numeric"}}
@@ -401,102 +1063,4 @@ func (p *ParquetReader) Scan(x *{{.Parent.StructType}}) {
 {{ template "boolOptionalField" .}}
 {{end}}
 {{end}}
-
-{{range dedupe .Parent.Fields}}
-{{if eq .Category "numeric"}}
-{{ template "requiredStats" .}}
-{{end}}
-{{if eq .Category "numericOptional"}}
-{{ template "optionalStats" .}}
-{{end}}
-{{if eq .Category "string"}}
-{{ template "stringStats" .}}
-{{end}}
-{{if eq .Category "stringOptional"}}
-{{ template "stringOptionalStats" .}}
-{{end}}
-{{if eq .Category "bool"}}
-{{ template "boolStats" .}}
-{{end}}
-{{if eq .Category "boolOptional"}}
-{{ template "boolOptionalStats" .}}
-{{end}}
-{{end}}
-
-func pint32(i int32) *int32       { return &i }
-func puint32(i uint32) *uint32    { return &i }
-func pint64(i int64) *int64       { return &i }
-func puint64(i uint64) *uint64    { return &i }
-func pbool(b bool) *bool          { return &b }
-func pstring(s string) *string    { return &s }
-func pfloat32(f float32) *float32 { return &f }
-func pfloat64(f float64) *float64 { return &f }
-
-// keeps track of the indices of repeated fields
-// that have already been handled by a previous field
-type indices []int
-
-func (i indices) rep(rep uint8) {
-	if rep > 0 {
-		r := int(rep) - 1
-		i[r] = i[r] + 1
-		for j := int(rep); j < len(i); j++ {
-			i[j] = 0
-		}
-	}
-}
-
-func maxDef(types []int) uint8 {
-	var out uint8
-	for _, typ := range types {
-		if typ > 0 {
-			out++
-		}
-	}
-	return out
-}
-
-func Int32Type(se *sch.SchemaElement) {
-	t := sch.Type_INT32
-	se.Type = &t
-}
-
-func Uint32Type(se *sch.SchemaElement) {
-	t := sch.Type_INT32
-	se.Type = &t
-	ct := sch.ConvertedType_UINT_32
-	se.ConvertedType = &ct
-}
-
-func Int64Type(se *sch.SchemaElement) {
-	t := sch.Type_INT64
-	se.Type = &t
-}
-
-func Uint64Type(se *sch.SchemaElement) {
-	t := sch.Type_INT64
-	se.Type = &t
-	ct := sch.ConvertedType_UINT_64
-	se.ConvertedType = &ct
-}
-
-func Float32Type(se *sch.SchemaElement) {
-	t := sch.Type_FLOAT
-	se.Type = &t
-}
-
-func Float64Type(se *sch.SchemaElement) {
-	t := sch.Type_DOUBLE
-	se.Type = &t
-}
-
-func BoolType(se *sch.SchemaElement) {
-	t := sch.Type_BOOLEAN
-	se.Type = &t
-}
-
-func StringType(se *sch.SchemaElement) {
-	t := sch.Type_BYTE_ARRAY
-	se.Type = &t
-}
-`
+{{end}}`
@@ -1,27 +1,31 @@
 package gen
 
-var boolTpl = `{{define "boolField"}}type BoolField struct {
+var boolTpl = `{{define "boolField"}}type {{.FieldType}} struct {
 	{{parquetType .}}
 	vals []bool
 	read  func(r {{.StructType}}) {{.TypeName}}
+	readBatch func(rs []{{.StructType}}, vals []{{removeStar .TypeName}}) []{{removeStar .TypeName}}
 	write func(r *{{.StructType}}, vals []{{removeStar .TypeName}})
+	writeBatch func(rs []{{.StructType}}, vals []{{removeStar .TypeName}})
     stats *boolStats
 }
 
-func NewBoolField(read func(r {{.StructType}}) {{.TypeName}}, write func(r *{{.StructType}}, vals []{{removeStar .TypeName}}), path []string, opts ...func(*{{parquetType .}})) *BoolField {
-	return &BoolField{
+func New{{.FieldType}}(read func(r {{.StructType}}) {{.TypeName}}, readBatch func(rs []{{.StructType}}, vals []{{removeStar .TypeName}}) []{{removeStar .TypeName}}, write func(r *{{.StructType}}, vals []{{removeStar .TypeName}}), writeBatch func(rs []{{.StructType}}, vals []{{removeStar .TypeName}}), path []string, opts ...func(*{{parquetType .}})) *{{.FieldType}} {
+	return &{{.FieldType}}{
 		read:          read,
+		readBatch:     readBatch,
 		write:         write,
+		writeBatch:    writeBatch,
 		RequiredField: parquet.NewRequiredField(path, opts...),
 	}
 }
 
-func (f *BoolField) Schema() parquet.Field {
-	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: BoolType, RepetitionType: parquet.RepetitionRequired, Types: []int{0}}
+func (f *{{.FieldType}}) Schema() parquet.Field {
+	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: parquet.BoolType, RepetitionType: parquet.RepetitionRequired, Types: []int{0}, IDs: f.IDs()}
 }
 
 
-func (f *BoolField) Write(w io.Writer, meta *parquet.Metadata) error {
+func (f *{{.FieldType}}) Write(w io.Writer, meta *parquet.Metadata) error {
 	ln := len(f.vals)
 	n := (ln + 7) / 8
 	rawBuf := make([]byte, n)
@@ -35,7 +39,7 @@ func (f *BoolField) Write(w io.Writer, meta *parquet.Metadata) error {
 	return f.DoWrite(w, meta, rawBuf, len(f.vals), newBoolStats())
 }
 
-func (f *BoolField) Read(r io.ReadSeeker, pg parquet.Page) error {
+func (f *{{.FieldType}}) Read(r io.ReadSeeker, pg parquet.Page) error {
 	rr, sizes, err := f.DoRead(r, pg)
 	if err != nil {
 		return err
@@ -45,21 +49,43 @@ func (f *BoolField) Read(r io.ReadSeeker, pg parquet.Page) error {
 	return err
 }
 
-func (f *BoolField) Scan(r *{{.StructType}}) {
+// Scan writes the next value to r.  It returns false if there
+// are no more values.
+func (f *{{.FieldType}}) Scan(r *{{.StructType}}) bool {
 	if len(f.vals) == 0 {
-		return
+		return false
 	}
 
 	f.write(r, f.vals)
     f.vals = f.vals[1:]
+	return true
 }
 
-func (f *BoolField) Add(r {{.StructType}}) {
+// ScanBatch writes the next len(rs) values to rs and returns the
+// number of records it wrote, which is less than len(rs) if the
+// column has run out of values.
+func (f *{{.FieldType}}) ScanBatch(rs []{{.StructType}}) int {
+	n := len(rs)
+	if n > len(f.vals) {
+		n = len(f.vals)
+	}
+
+	f.writeBatch(rs[:n], f.vals[:n])
+	f.vals = f.vals[n:]
+	return n
+}
+
+func (f *{{.FieldType}}) Add(r {{.StructType}}) {
 	v := f.read(r)
 	f.vals = append(f.vals, v)
 }
 
-func (f *BoolField) Levels() ([]uint8, []uint8) {
+// AddBatch adds the values of each of rs with a single append.
+func (f *{{.FieldType}}) AddBatch(rs []{{.StructType}}) {
+	f.vals = f.readBatch(rs, f.vals)
+}
+
+func (f *{{.FieldType}}) Levels() ([]uint8, []uint8) {
 	return nil, nil
 }
 {{end}}`
@@ -1,6 +1,6 @@
 package gen
 
-var boolOptionalTpl = `{{define "boolOptionalField"}}type BoolOptionalField struct {
+var boolOptionalTpl = `{{define "boolOptionalField"}}type {{.FieldType}} struct {
 	parquet.OptionalField
 	vals  []bool
 	read   func(r {{.StructType}}, vals []{{removeStar .TypeName}}, defs, reps []uint8) ([]{{removeStar .TypeName}}, []uint8, []uint8)
@@ -8,20 +8,21 @@ var boolOptionalTpl = `{{define "boolOptionalField"}}type BoolOptionalField stru
 	stats *boolOptionalStats
 }
 
-func NewBoolOptionalField(read func(r {{.StructType}}, vals []{{removeStar .TypeName}}, defs, reps []uint8) ([]{{removeStar .TypeName}}, []uint8, []uint8), write func(r *{{.StructType}}, vals []{{removeStar .TypeName}}, defs, reps []uint8) (int, int), path []string, types []int, opts ...func(*parquet.OptionalField)) *BoolOptionalField {
-	return &BoolOptionalField{
+func New{{.FieldType}}(read func(r {{.StructType}}, vals []{{removeStar .TypeName}}, defs, reps []uint8) ([]{{removeStar .TypeName}}, []uint8, []uint8), write func(r *{{.StructType}}, vals []{{removeStar .TypeName}}, defs, reps []uint8) (int, int), path []string, types []int, opts ...func(*parquet.OptionalField)) *{{.FieldType}} {
+	f := &{{.FieldType}}{
 		read:          read,
 		write:         write,
 		OptionalField: parquet.NewOptionalField(path, types, opts...),
-		stats:         newBoolOptionalStats(maxDef(types)),
 	}
+	f.stats = newBoolOptionalStats(f.MaxLevels.Def)
+	return f
 }
 
-func (f *BoolOptionalField) Schema() parquet.Field {
-	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: BoolType, RepetitionType: f.RepetitionType, Types: f.Types}
+func (f *{{.FieldType}}) Schema() parquet.Field {
+	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: parquet.BoolType, RepetitionType: f.RepetitionType, Types: f.Types, IDs: f.IDs()}
 }
 
-func (f *BoolOptionalField) Read(r io.ReadSeeker, pg parquet.Page) error {
+func (f *{{.FieldType}}) Read(r io.ReadSeeker, pg parquet.Page) error {
 	rr, sizes, err := f.DoRead(r, pg)
 	if err != nil {
 		return err
@@ -32,9 +33,11 @@ func (f *BoolOptionalField) Read(r io.ReadSeeker, pg parquet.Page) error {
 	return err
 }
 
-func (f *BoolOptionalField) Scan(r *{{.StructType}}) {
+// Scan writes the values of the next record to r.  It returns
+// false if there are no more records.
+func (f *{{.FieldType}}) Scan(r *{{.StructType}}) bool {
 	if len(f.Defs) == 0 {
-		return
+		return false
 	}
 
 	v, l := f.write(r, f.vals, f.Defs, f.Reps)
@@ -43,9 +46,34 @@ func (f *BoolOptionalField) Scan(r *{{.StructType}}) {
 	if len(f.Reps) > 0 {
 		f.Reps = f.Reps[l:]
 	}
+	return true
 }
 
-func (f *BoolOptionalField) Add(r {{.StructType}}) {
+// ScanBatch writes the values of the next len(rs) records to rs
+// and returns the number of records it wrote, which is less than
+// len(rs) if the column has run out of values.
+func (f *{{.FieldType}}) ScanBatch(rs []{{.StructType}}) int {
+	var n, v, l int
+	for ; n < len(rs) && l < len(f.Defs); n++ {
+		var reps []uint8
+		if len(f.Reps) > 0 {
+			reps = f.Reps[l:]
+		}
+
+		vv, ll := f.write(&rs[n], f.vals[v:], f.Defs[l:], reps)
+		v += vv
+		l += ll
+	}
+
+	f.vals = f.vals[v:]
+	f.Defs = f.Defs[l:]
+	if len(f.Reps) > 0 {
+		f.Reps = f.Reps[l:]
+	}
+	return n
+}
+
+func (f *{{.FieldType}}) Add(r {{.StructType}}) {
 	vals, defs, reps := f.read(r, f.vals, f.Defs, f.Reps)
 	f.stats.add(vals[len(f.vals):], defs[len(f.Defs):])
 	f.vals = vals
@@ -53,7 +81,38 @@ func (f *BoolOptionalField) Add(r {{.StructType}}) {
 	f.Reps = reps
 }
 
-func (f *BoolOptionalField) Write(w io.Writer, meta *parquet.Metadata) error {
+// AddBatch adds the values and levels of each of rs.  It
+// assumes each record has (at least) one value.
+func (f *{{.FieldType}}) AddBatch(rs []{{.StructType}}) {
+	if n := len(f.vals) + len(rs); n > cap(f.vals) {
+		vals := make([]bool, len(f.vals), n)
+		copy(vals, f.vals)
+		f.vals = vals
+	}
+
+	if n := len(f.Defs) + len(rs); n > cap(f.Defs) {
+		defs := make([]uint8, len(f.Defs), n)
+		copy(defs, f.Defs)
+		f.Defs = defs
+	}
+
+	if n := len(f.Reps) + len(rs); f.MaxLevels.Rep > 0 && n > cap(f.Reps) {
+		reps := make([]uint8, len(f.Reps), n)
+		copy(reps, f.Reps)
+		f.Reps = reps
+	}
+
+	vals, defs, reps := f.vals, f.Defs, f.Reps
+	for _, r := range rs {
+		vals, defs, reps = f.read(r, vals, defs, reps)
+	}
+	f.stats.add(vals[len(f.vals):], defs[len(f.Defs):])
+	f.vals = vals
+	f.Defs = defs
+	f.Reps = reps
+}
+
+func (f *{{.FieldType}}) Write(w io.Writer, meta *parquet.Metadata) error {
 	ln := len(f.vals)
 	byteNum := (ln + 7) / 8
 	rawBuf := make([]byte, byteNum)
@@ -67,7 +126,7 @@ func (f *BoolOptionalField) Write(w io.Writer, meta *parquet.Metadata) error {
 	return f.DoWrite(w, meta, rawBuf, len(f.Defs), f.stats)
 }
 
-func (f *BoolOptionalField) Levels() ([]uint8, []uint8) {
+func (f *{{.FieldType}}) Levels() ([]uint8, []uint8) {
 	return f.Defs, f.Reps
 }
 {{end}}`
@@ -13,21 +13,22 @@ type {{.FieldType}} struct {
 }
 
 func New{{.FieldType}}(read func(r {{.StructType}}, vals []{{removeStar .TypeName}}, defs, reps []uint8) ([]{{removeStar .TypeName}}, []uint8, []uint8), write func(r *{{.StructType}}, vals []{{removeStar .TypeName}}, defs, reps []uint8) (int, int), path []string, types []int, opts ...func(*parquet.OptionalField)) *{{.FieldType}} {
-	return &{{.FieldType}}{
+	f := &{{.FieldType}}{
 		read:          read,
 		write:         write,
 		OptionalField: parquet.NewOptionalField(path, types, opts...),
-		stats:         new{{removeStar .TypeName}}optionalStats(maxDef(types)),
 	}
+	f.stats = new{{removeStar .TypeName}}optionalStats(f.MaxLevels.Def)
+	return f
 }
 
 func (f *{{.FieldType}}) Schema() parquet.Field {
-	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: {{.ParquetType}}, RepetitionType: f.RepetitionType, Types: f.Types}
+	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: parquet.{{.ParquetType}}, RepetitionType: f.RepetitionType, Types: f.Types, IDs: f.IDs()}
 }
 
 func (f *{{.FieldType}}) Write(w io.Writer, meta *parquet.Metadata) error {
-	buf := buffpool.Get()
-	defer buffpool.Put(buf)
+	buf := parquet.GetBuffer()
+	defer parquet.PutBuffer(buf)
 
 	bs := make([]byte, {{byteSize .}})
 	for _, v := range f.vals {
@@ -59,9 +60,42 @@ func (f *{{.FieldType}}) Add(r {{.StructType}}) {
 	f.Reps = reps
 }
 
-func (f *{{.FieldType}}) Scan(r *{{.StructType}}) {
+// AddBatch adds the values and levels of each of rs.  It
+// assumes each record has (at least) one value.
+func (f *{{.FieldType}}) AddBatch(rs []{{.StructType}}) {
+	if n := len(f.vals) + len(rs); n > cap(f.vals) {
+		vals := make([]{{removeStar .TypeName}}, len(f.vals), n)
+		copy(vals, f.vals)
+		f.vals = vals
+	}
+
+	if n := len(f.Defs) + len(rs); n > cap(f.Defs) {
+		defs := make([]uint8, len(f.Defs), n)
+		copy(defs, f.Defs)
+		f.Defs = defs
+	}
+
+	if n := len(f.Reps) + len(rs); f.MaxLevels.Rep > 0 && n > cap(f.Reps) {
+		reps := make([]uint8, len(f.Reps), n)
+		copy(reps, f.Reps)
+		f.Reps = reps
+	}
+
+	vals, defs, reps := f.vals, f.Defs, f.Reps
+	for _, r := range rs {
+		vals, defs, reps = f.read(r, vals, defs, reps)
+	}
+	f.stats.add(vals[len(f.vals):], defs[len(f.Defs):])
+	f.vals = vals
+	f.Defs = defs
+	f.Reps = reps
+}
+
+// Scan writes the values of the next record to r.  It returns
+// false if there are no more records.
+func (f *{{.FieldType}}) Scan(r *{{.StructType}}) bool {
 	if len(f.Defs) == 0 {
-		return
+		return false
 	}
 
 	v, l := f.write(r, f.vals, f.Defs, f.Reps)
@@ -70,6 +104,31 @@ func (f *{{.FieldType}}) Scan(r *{{.StructType}}) {
 	if len(f.Reps) > 0 {
 		f.Reps = f.Reps[l:]
 	}
+	return true
+}
+
+// ScanBatch writes the values of the next len(rs) records to rs
+// and returns the number of records it wrote, which is less than
+// len(rs) if the column has run out of values.
+func (f *{{.FieldType}}) ScanBatch(rs []{{.StructType}}) int {
+	var n, v, l int
+	for ; n < len(rs) && l < len(f.Defs); n++ {
+		var reps []uint8
+		if len(f.Reps) > 0 {
+			reps = f.Reps[l:]
+		}
+
+		vv, ll := f.write(&rs[n], f.vals[v:], f.Defs[l:], reps)
+		v += vv
+		l += ll
+	}
+
+	f.vals = f.vals[v:]
+	f.Defs = f.Defs[l:]
+	if len(f.Reps) > 0 {
+		f.Reps = f.Reps[l:]
+	}
+	return n
 }
 
 func (f *{{.FieldType}}) Levels() ([]uint8, []uint8) {
@@ -5,21 +5,25 @@ type {{.FieldType}} struct {
 	vals []{{.TypeName}}
 	parquet.RequiredField
 	read  func(r {{.StructType}}) {{.TypeName}}
+	readBatch func(rs []{{.StructType}}, vals []{{removeStar .TypeName}}) []{{removeStar .TypeName}}
 	write func(r *{{.StructType}}, vals []{{removeStar .TypeName}})
+	writeBatch func(rs []{{.StructType}}, vals []{{removeStar .TypeName}})
 	stats *{{.TypeName}}stats
 }
 
-func New{{.FieldType}}(read func(r {{.StructType}}) {{.TypeName}}, write func(r *{{.StructType}}, vals []{{removeStar .TypeName}}), path []string, opts ...func(*parquet.RequiredField)) *{{.FieldType}} {
+func New{{.FieldType}}(read func(r {{.StructType}}) {{.TypeName}}, readBatch func(rs []{{.StructType}}, vals []{{removeStar .TypeName}}) []{{removeStar .TypeName}}, write func(r *{{.StructType}}, vals []{{removeStar .TypeName}}), writeBatch func(rs []{{.StructType}}, vals []{{removeStar .TypeName}}), path []string, opts ...func(*parquet.RequiredField)) *{{.FieldType}} {
 	return &{{.FieldType}}{
 		read:           read,
+		readBatch:      readBatch,
 		write:          write,
+		writeBatch:    writeBatch,
 		RequiredField: parquet.NewRequiredField(path, opts...),
 		stats:         new{{camelCase .TypeName}}stats(),
 	}
 }
 
 func (f *{{.FieldType}}) Schema() parquet.Field {
-	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: {{.ParquetType}}, RepetitionType: parquet.RepetitionRequired, Types: []int{0}}
+	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: parquet.{{.ParquetType}}, RepetitionType: parquet.RepetitionRequired, Types: []int{0}, IDs: f.IDs()}
 }
 
 func (f *{{.FieldType}}) Read(r io.ReadSeeker, pg parquet.Page) error {
@@ -35,8 +39,8 @@ func (f *{{.FieldType}}) Read(r io.ReadSeeker, pg parquet.Page) error {
 }
 
 func (f *{{.FieldType}}) Write(w io.Writer, meta *parquet.Metadata) error {
-	buf := buffpool.Get()
-	defer buffpool.Put(buf)
+	buf := parquet.GetBuffer()
+	defer parquet.PutBuffer(buf)
 
 	bs := make([]byte, {{byteSize .}})
 	for _, v := range f.vals {
@@ -48,13 +52,30 @@ func (f *{{.FieldType}}) Write(w io.Writer, meta *parquet.Metadata) error {
 	return f.DoWrite(w, meta, buf.Bytes(), len(f.vals), f.stats)
 }
 
-func (f *{{.FieldType}}) Scan(r *{{.StructType}}) {
+// Scan writes the next value to r.  It returns false if there
+// are no more values.
+func (f *{{.FieldType}}) Scan(r *{{.StructType}}) bool {
 	if len(f.vals) == 0 {
-		return
+		return false
 	}
 
 	f.write(r, f.vals)
 	f.vals = f.vals[1:]
+	return true
+}
+
+// ScanBatch writes the next len(rs) values to rs and returns the
+// number of records it wrote, which is less than len(rs) if the
+// column has run out of values.
+func (f *{{.FieldType}}) ScanBatch(rs []{{.StructType}}) int {
+	n := len(rs)
+	if n > len(f.vals) {
+		n = len(f.vals)
+	}
+
+	f.writeBatch(rs[:n], f.vals[:n])
+	f.vals = f.vals[n:]
+	return n
 }
 
 func (f *{{.FieldType}}) Add(r {{.Parent.StructType}}) {
@@ -63,6 +84,15 @@ func (f *{{.FieldType}}) Add(r {{.Parent.StructType}}) {
 	f.vals = append(f.vals, v)
 }
 
+// AddBatch adds the values of each of rs with a single append.
+func (f *{{.FieldType}}) AddBatch(rs []{{.StructType}}) {
+	n := len(f.vals)
+	f.vals = f.readBatch(rs, f.vals)
+	for _, v := range f.vals[n:] {
+		f.stats.add(v)
+	}
+}
+
 func (f *{{.FieldType}}) Levels() ([]uint8, []uint8) {
 	return nil, nil
 }
@@ -1,30 +1,34 @@
 package gen
 
 var stringTpl = `{{define "stringField"}}
-type StringField struct {
+type {{.FieldType}} struct {
 	parquet.RequiredField
 	vals []string
 	read  func(r {{.StructType}}) {{.TypeName}}
+	readBatch func(rs []{{.StructType}}, vals []{{removeStar .TypeName}}) []{{removeStar .TypeName}}
 	write func(r *{{.StructType}}, vals []{{removeStar .TypeName}})
+	writeBatch func(rs []{{.StructType}}, vals []{{removeStar .TypeName}})
 	stats *stringStats
 }
 
-func NewStringField(read func(r {{.StructType}}) {{.TypeName}}, write func(r *{{.StructType}}, vals []{{removeStar .TypeName}}), path []string, opts ...func(*parquet.RequiredField)) *StringField {
-	return &StringField{
+func New{{.FieldType}}(read func(r {{.StructType}}) {{.TypeName}}, readBatch func(rs []{{.StructType}}, vals []{{removeStar .TypeName}}) []{{removeStar .TypeName}}, write func(r *{{.StructType}}, vals []{{removeStar .TypeName}}), writeBatch func(rs []{{.StructType}}, vals []{{removeStar .TypeName}}), path []string, opts ...func(*parquet.RequiredField)) *{{.FieldType}} {
+	return &{{.FieldType}}{
 		read:           read,
+		readBatch:      readBatch,
 		write:          write,
+		writeBatch:    writeBatch,
 		RequiredField: parquet.NewRequiredField(path, opts...),
 		stats:         newStringStats(),
 	}
 }
 
-func (f *StringField) Schema() parquet.Field {
-	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: StringType, RepetitionType: parquet.RepetitionRequired, Types: []int{0}}
+func (f *{{.FieldType}}) Schema() parquet.Field {
+	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: parquet.StringType, RepetitionType: parquet.RepetitionRequired, Types: []int{0}, IDs: f.IDs()}
 }
 
-func (f *StringField) Write(w io.Writer, meta *parquet.Metadata) error {
-	buf := buffpool.Get()
-	defer buffpool.Put(buf)
+func (f *{{.FieldType}}) Write(w io.Writer, meta *parquet.Metadata) error {
+	buf := parquet.GetBuffer()
+	defer parquet.PutBuffer(buf)
 
 	bs := make([]byte, 4)
 	for _, s := range f.vals {
@@ -38,7 +42,7 @@ func (f *StringField) Write(w io.Writer, meta *parquet.Metadata) error {
 	return f.DoWrite(w, meta, buf.Bytes(), len(f.vals), f.stats)
 }
 
-func (f *StringField) Read(r io.ReadSeeker, pg parquet.Page) error {
+func (f *{{.FieldType}}) Read(r io.ReadSeeker, pg parquet.Page) error {
 	rr, _, err := f.DoRead(r, pg)
 	if err != nil {
 		return err
@@ -50,7 +54,7 @@ func (f *StringField) Read(r io.ReadSeeker, pg parquet.Page) error {
 			return err
 		}
 		s := make([]byte, x)
-		if _, err := rr.Read(s); err != nil {
+		if _, err := io.ReadFull(rr, s); err != nil {
 			return err
 		}
 
@@ -59,22 +63,48 @@ func (f *StringField) Read(r io.ReadSeeker, pg parquet.Page) error {
 	return nil
 }
 
-func (f *StringField) Scan(r *{{.StructType}}) {
+// Scan writes the next value to r.  It returns false if there
+// are no more values.
+func (f *{{.FieldType}}) Scan(r *{{.StructType}}) bool {
 	if len(f.vals) == 0 {
-		return
+		return false
 	}
 
 	f.write(r, f.vals)
 	f.vals = f.vals[1:]
+	return true
 }
 
-func (f *StringField) Add(r {{.StructType}}) {
+// ScanBatch writes the next len(rs) values to rs and returns the
+// number of records it wrote, which is less than len(rs) if the
+// column has run out of values.
+func (f *{{.FieldType}}) ScanBatch(rs []{{.StructType}}) int {
+	n := len(rs)
+	if n > len(f.vals) {
+		n = len(f.vals)
+	}
+
+	f.writeBatch(rs[:n], f.vals[:n])
+	f.vals = f.vals[n:]
+	return n
+}
+
+func (f *{{.FieldType}}) Add(r {{.StructType}}) {
 	v := f.read(r)
 	f.stats.add(v)
 	f.vals = append(f.vals, v)
 }
 
-func (f *StringField) Levels() ([]uint8, []uint8) {
+// AddBatch adds the values of each of rs with a single append.
+func (f *{{.FieldType}}) AddBatch(rs []{{.StructType}}) {
+	n := len(f.vals)
+	f.vals = f.readBatch(rs, f.vals)
+	for _, v := range f.vals[n:] {
+		f.stats.add(v)
+	}
+}
+
+func (f *{{.FieldType}}) Levels() ([]uint8, []uint8) {
 	return nil, nil
 }
 {{end}}`
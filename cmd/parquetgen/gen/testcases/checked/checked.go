@@ -0,0 +1,10 @@
+package checked
+
+//go:generate parquetgen -input checked.go -type Person -package checked -output generated.go -errors
+
+type Person struct {
+	ID      int32   `parquet:"id"`
+	Name    string  `parquet:"name"`
+	Age     *int32  `parquet:"age"`
+	Friends []int64 `parquet:"friends"`
+}
@@ -0,0 +1,79 @@
+package deep
+
+//go:generate parquetgen -input deep.go -type Record -package deep -output generated.go
+
+// Record is nested more than 15 optional levels deep, so its
+// definition levels need more than 4 bits.
+type Record struct {
+	ID    int32 `parquet:"id"`
+	Level *L1   `parquet:"level"`
+}
+
+type L1 struct {
+	Next *L2 `parquet:"next"`
+}
+
+type L2 struct {
+	Next *L3 `parquet:"next"`
+}
+
+type L3 struct {
+	Next *L4 `parquet:"next"`
+}
+
+type L4 struct {
+	Next *L5 `parquet:"next"`
+}
+
+type L5 struct {
+	Next *L6 `parquet:"next"`
+}
+
+type L6 struct {
+	Next *L7 `parquet:"next"`
+}
+
+type L7 struct {
+	Next *L8 `parquet:"next"`
+}
+
+type L8 struct {
+	Next *L9 `parquet:"next"`
+}
+
+type L9 struct {
+	Next *L10 `parquet:"next"`
+}
+
+type L10 struct {
+	Next *L11 `parquet:"next"`
+}
+
+type L11 struct {
+	Next *L12 `parquet:"next"`
+}
+
+type L12 struct {
+	Next *L13 `parquet:"next"`
+}
+
+type L13 struct {
+	Next *L14 `parquet:"next"`
+}
+
+type L14 struct {
+	Next *L15 `parquet:"next"`
+}
+
+type L15 struct {
+	Next *L16 `parquet:"next"`
+}
+
+type L16 struct {
+	Next *L17 `parquet:"next"`
+}
+
+type L17 struct {
+	Value  *int32  `parquet:"value"`
+	Values []int32 `parquet:"values"`
+}
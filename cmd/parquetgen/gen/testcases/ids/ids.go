@@ -0,0 +1,15 @@
+package ids
+
+//go:generate parquetgen -input ids.go -type Record -package ids -output generated.go
+
+// Record has field IDs for all of its columns except for note.
+type Record struct {
+	ID      int64    `parquet:"id,id=1"`
+	Name    *string  `parquet:"name,id=2"`
+	Address *Address `parquet:"address,id=3"`
+	Note    string   `parquet:"note"`
+}
+
+type Address struct {
+	City string `parquet:"city,id=4"`
+}
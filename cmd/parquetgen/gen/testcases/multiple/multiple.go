@@ -0,0 +1,19 @@
+package multiple
+
+//go:generate parquetgen -input multiple.go -type Person,Place -package multiple -output generated.go
+
+type Person struct {
+	ID      int32   `parquet:"id"`
+	Name    string  `parquet:"name"`
+	Age     *int32  `parquet:"age"`
+	Friends []int64 `parquet:"friends"`
+}
+
+type Place struct {
+	ID        int32    `parquet:"id"`
+	Name      *string  `parquet:"name"`
+	Latitude  float64  `parquet:"latitude"`
+	Longitude float64  `parquet:"longitude"`
+	Visited   bool     `parquet:"visited"`
+	Tags      []string `parquet:"tags"`
+}
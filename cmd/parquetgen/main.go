@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"flag"
 	"io"
+	"io/ioutil"
 	"log"
 	"os"
+	"path/filepath"
 
 	"github.com/parsyl/parquet"
 	"github.com/parsyl/parquet/cmd/parquetgen/gen"
@@ -15,7 +17,7 @@ import (
 var (
 	metadata     = flag.Bool("metadata", false, "print the metadata of a parquet file (-parquet) and exit")
 	pageheaders  = flag.Bool("pageheaders", false, "print the page headers of a parquet file (-parquet) and exit (also prints the metadata)")
-	typ          = flag.String("type", "", "name of the struct that will used for writing and reading")
+	typ          = flag.String("type", "", "name of the struct that will used for writing and reading (a comma separated list generates code for each struct, prefixed with the struct's name)")
 	pkg          = flag.String("package", "", "package of the generated code")
 	imp          = flag.String("import", "", "import statement of -type if it doesn't live in -package")
 	pth          = flag.String("input", "", "path to the go file that defines -type")
@@ -23,9 +25,15 @@ var (
 	ignore       = flag.Bool("ignore", true, "ignore unsupported fields in -type, otherwise log.Fatal is called when an unsupported type is encountered")
 	parq         = flag.String("parquet", "", "path to a parquet file (if you are generating code based on an existing parquet file or printing the file metadata or page headers)")
 	structOutPth = flag.String("struct-output", "generated_struct.go", "name of the file that is produced, defaults to parquet.go")
+	errs         = flag.Bool("errors", false, "generate an Add and a Scan that return an error instead of failing silently")
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "merge" {
+		merge(os.Args[2:])
+		return
+	}
+
 	flag.Parse()
 
 	if *pth != "" && *parq != "" {
@@ -38,9 +46,9 @@ func main() {
 	} else if *pageheaders {
 		readPageHeaders()
 	} else if *parq == "" {
-		err = gen.FromStruct(*pth, *outPth, *typ, *pkg, *imp, *ignore)
+		err = gen.FromStruct(*pth, *outPth, *typ, *pkg, *imp, *ignore, *errs)
 	} else {
-		err = gen.FromParquet(*parq, *structOutPth, *outPth, *typ, *pkg, *imp, *ignore)
+		err = gen.FromParquet(*parq, *structOutPth, *outPth, *typ, *pkg, *imp, *ignore, *errs)
 	}
 
 	if err != nil {
@@ -48,6 +56,66 @@ func main() {
 	}
 }
 
+// merge implements the merge sub command:
+//
+//	parquetgen merge -o out.parquet in1.parquet in2.parquet ...
+func merge(args []string) {
+	fs := flag.NewFlagSet("merge", flag.ExitOnError)
+	out := fs.String("o", "", "path of the merged parquet file")
+	coalesce := fs.Int64("coalesce", 0, "combine consecutive row groups with fewer than this many rows (re-encodes them)")
+	fs.Parse(args)
+
+	if *out == "" || fs.NArg() == 0 {
+		log.Fatal("usage: parquetgen merge -o out.parquet [-coalesce rows] in1.parquet in2.parquet ...")
+	}
+
+	var opts []func(*parquet.Merger) error
+	if *coalesce > 0 {
+		opts = append(opts, parquet.MergeCoalesce(*coalesce))
+	}
+
+	files := make([]io.ReadSeeker, fs.NArg())
+	for i, pth := range fs.Args() {
+		f, err := os.Open(pth)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		files[i] = f
+	}
+
+	if err := mergeTo(*out, files, opts...); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// mergeTo merges files into a temporary file next to out and then
+// renames it to out, so a failed merge doesn't leave a partially
+// written file behind.
+func mergeTo(out string, files []io.ReadSeeker, opts ...func(*parquet.Merger) error) error {
+	f, err := ioutil.TempFile(filepath.Dir(out), filepath.Base(out)+".*.tmp")
+	if err != nil {
+		return err
+	}
+
+	err = f.Chmod(0644)
+	if err == nil {
+		err = parquet.Merge(f, files, opts...)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	if err == nil {
+		err = os.Rename(f.Name(), out)
+	}
+
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
 func readPageHeaders() {
 	f := openParquet()
 	footer := getFooter(f)
@@ -208,6 +208,17 @@ func TestFields(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "tags with field ids",
+			typ:  "TaggedIDs",
+			expected: fields.Field{
+				Children: []fields.Field{
+					{Type: "int32", Name: "ID", ColumnName: "id", RepetitionType: fields.Required, ID: 1},
+					{Type: "string", Name: "Name", ColumnName: "name", RepetitionType: fields.Required, ID: 2},
+					{Type: "string", Name: "Note", ColumnName: "note", RepetitionType: fields.Required},
+				},
+			},
+		},
 		{
 			name: "omit tag",
 			typ:  "IgnoreMe",
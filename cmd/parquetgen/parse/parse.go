@@ -5,10 +5,13 @@ import (
 	"go/parser"
 	"go/token"
 	"log"
+	"reflect"
+	"strconv"
 	"strings"
 
 	"go/ast"
 
+	"github.com/parsyl/parquet"
 	"github.com/parsyl/parquet/cmd/parquetgen/fields"
 	flds "github.com/parsyl/parquet/cmd/parquetgen/fields"
 )
@@ -102,6 +105,7 @@ func getChildren(parent *flds.Field, fields map[string]flds.Field) []error {
 		f.Name = child.Name
 		f.Type = child.Type
 		f.ColumnName = child.ColumnName
+		f.ID = child.ID
 		f.Children = child.Children
 		f.RepetitionType = child.RepetitionType
 
@@ -129,6 +133,7 @@ func isPrivate(x *ast.Field) bool {
 
 func getFields(n map[string]ast.Node) (map[string]fields.Field, error) {
 	fields := map[string]flds.Field{}
+	var err error
 	for k, n := range n {
 		_, ok := n.(*ast.TypeSpec)
 		if !ok {
@@ -147,12 +152,18 @@ func getFields(n map[string]ast.Node) (map[string]fields.Field, error) {
 			switch x := n.(type) {
 			case *ast.Field:
 				if len(x.Names) == 1 && !isPrivate(x) {
-					f, skip := getField(x.Names[0].Name, x, nil)
+					f, skip, fErr := getField(x.Names[0].Name, x, nil)
+					if fErr != nil && err == nil {
+						err = fErr
+					}
 					if !skip {
 						parent.Children = append(parent.Children, f)
 					}
 				} else if len(x.Names) == 0 && !isPrivate(x) {
-					f, skip := getField(fmt.Sprintf("%s", x.Type), x, nil)
+					f, skip, fErr := getField(fmt.Sprintf("%s", x.Type), x, nil)
+					if fErr != nil && err == nil {
+						err = fErr
+					}
 					f.Embedded = true
 					if !skip {
 						parent.Children = append(parent.Children, f)
@@ -165,7 +176,7 @@ func getFields(n map[string]ast.Node) (map[string]fields.Field, error) {
 		fields[k] = parent
 	}
 
-	return fields, nil
+	return fields, err
 }
 
 func getType(typ string) string {
@@ -173,14 +184,19 @@ func getType(typ string) string {
 	return parts[len(parts)-1]
 }
 
-func getField(name string, x ast.Node, parent *flds.Field) (flds.Field, bool) {
+func getField(name string, x ast.Node, parent *flds.Field) (flds.Field, bool, error) {
 	var typ, tag string
+	var id int32
 	var optional, repeated bool
+	var err error
 	ast.Inspect(x, func(n ast.Node) bool {
 		switch t := n.(type) {
 		case *ast.Field:
 			if t.Tag != nil {
-				tag = parseTag(t.Tag.Value)
+				tag, id, err = parseTag(t.Tag.Value)
+				if err != nil {
+					err = fmt.Errorf("field %s: %w", name, err)
+				}
 			}
 			typ = fmt.Sprintf("%s", t.Type)
 		case *ast.ArrayType:
@@ -217,16 +233,18 @@ func getField(name string, x ast.Node, parent *flds.Field) (flds.Field, bool) {
 		Name:           name,
 		ColumnName:     tag,
 		RepetitionType: rt,
-	}, tag == "-"
+		ID:             id,
+	}, tag == "-", err
 }
 
-func parseTag(t string) string {
-	i := strings.Index(t, `parquet:"`)
-	if i == -1 {
-		return ""
+// parseTag returns the column name and the field ID of the
+// parquet key of the struct tag t (a go string literal).
+func parseTag(t string) (string, int32, error) {
+	tag, err := strconv.Unquote(t)
+	if err != nil {
+		return "", 0, err
 	}
-	t = t[i+9:]
-	return t[:strings.Index(t, `"`)]
+	return parquet.ParseTag(reflect.StructTag(tag).Get("parquet"))
 }
 
 type visitorFunc func(n ast.Node) ast.Visitor
@@ -41,6 +41,12 @@ type Tagged struct {
 	Name string `parquet:"name"`
 }
 
+type TaggedIDs struct {
+	ID   int32  `parquet:"id,id=1"`
+	Name string `parquet:"name,id=2"`
+	Note string `parquet:"note"`
+}
+
 type Private struct {
 	Being
 	name string
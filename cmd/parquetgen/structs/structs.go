@@ -53,7 +53,11 @@ func field(elem *sch.SchemaElement) string {
 	if elem.RepetitionType != nil && *elem.RepetitionType == sch.FieldRepetitionType_OPTIONAL {
 		ptr = "*"
 	}
-	return fmt.Sprintf("%s %s%s `parquet:\"%s\"`", n, ptr, t, elem.Name)
+	tag := elem.Name
+	if elem.FieldID != nil && *elem.FieldID > 0 {
+		tag = fmt.Sprintf("%s,id=%d", tag, *elem.FieldID)
+	}
+	return fmt.Sprintf("%s %s%s `parquet:\"%s\"`", n, ptr, t, tag)
 }
 
 func getType(t string) string {
@@ -26,6 +26,14 @@ func TestStruct(t *testing.T) {
 			},
 			expected: "type Root struct {\n	Id int32 `parquet:\"id\"`\n}",
 		},
+		{
+			name: "field id",
+			schema: []*sch.SchemaElement{
+				{Name: "root", NumChildren: pint32(1)},
+				{Name: "id", Type: pt(sch.Type_INT32), RepetitionType: prt(sch.FieldRepetitionType_REQUIRED), FieldID: pint32(7)},
+			},
+			expected: "type Root struct {\n	Id int32 `parquet:\"id,id=7\"`\n}",
+		},
 		{
 			name: "single nested field",
 			schema: []*sch.SchemaElement{
@@ -0,0 +1,389 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+
+	sch "github.com/parsyl/parquet/schema"
+)
+
+// column buffers the values and levels of a single column
+// until they are written as a column chunk.  It is used by
+// the code that writes parquet files without generated code.
+type column struct {
+	field       Field
+	se          sch.SchemaElement
+	maxDef      uint8
+	maxRep      uint8
+	compression sch.CompressionCodec
+	pages       []*page
+}
+
+// page holds the PLAIN encoded values of one data page.
+type page struct {
+	buf   bytes.Buffer
+	bools []bool
+	n     int
+	defs  []uint8
+	reps  []uint8
+	stats *columnStats
+}
+
+func newColumn(f Field, compression sch.CompressionCodec) *column {
+	var z int32
+	se := sch.SchemaElement{TypeLength: &z, Scale: &z, Precision: &z}
+	f.Type(&se)
+	f.RepetitionType(&se)
+	rts := getRepetitionTypes(f.Types)
+	c := &column{
+		field:       f,
+		se:          se,
+		maxDef:      rts.MaxDef(),
+		maxRep:      rts.MaxRep(),
+		compression: compression,
+	}
+	c.newPage()
+	return c
+}
+
+func (c *column) newPage() {
+	c.pages = append(c.pages, &page{stats: newColumnStats(c.se, c.maxDef > 0)})
+}
+
+func (c *column) page() *page {
+	return c.pages[len(c.pages)-1]
+}
+
+// reset drops all the pages that have been written.
+func (c *column) reset() {
+	c.pages = nil
+	c.newPage()
+}
+
+// levels adds the definition and repetition levels of a value
+// (or a missing value) to the current page.
+func (c *column) levels(def, rep uint8) {
+	if c.maxDef == 0 {
+		return
+	}
+
+	pg := c.page()
+	pg.defs = append(pg.defs, def)
+	if c.maxRep > 0 {
+		pg.reps = append(pg.reps, rep)
+	}
+	if def < c.maxDef {
+		pg.stats.nulls++
+	}
+}
+
+// add PLAIN encodes v and appends it to the current page.
+func (c *column) add(v reflect.Value) error {
+	pg := c.page()
+	var b [8]byte
+	switch v.Kind() {
+	case reflect.Int32:
+		binary.LittleEndian.PutUint32(b[:4], uint32(v.Int()))
+		pg.value(b[:4])
+	case reflect.Uint32:
+		binary.LittleEndian.PutUint32(b[:4], uint32(v.Uint()))
+		pg.value(b[:4])
+	case reflect.Int64:
+		binary.LittleEndian.PutUint64(b[:], uint64(v.Int()))
+		pg.value(b[:])
+	case reflect.Uint64:
+		binary.LittleEndian.PutUint64(b[:], v.Uint())
+		pg.value(b[:])
+	case reflect.Float32:
+		binary.LittleEndian.PutUint32(b[:4], math.Float32bits(float32(v.Float())))
+		pg.value(b[:4])
+	case reflect.Float64:
+		binary.LittleEndian.PutUint64(b[:], math.Float64bits(v.Float()))
+		pg.value(b[:])
+	case reflect.Bool:
+		pg.bools = append(pg.bools, v.Bool())
+		pg.n++
+	case reflect.String:
+		s := v.String()
+		binary.LittleEndian.PutUint32(b[:4], uint32(len(s)))
+		pg.buf.Write(b[:4])
+		pg.buf.WriteString(s)
+		pg.stats.add([]byte(s))
+		pg.n++
+	default:
+		return fmt.Errorf("unsupported type %s for column %s", v.Type(), c.field.Name)
+	}
+	return nil
+}
+
+func (p *page) value(b []byte) {
+	p.buf.Write(b)
+	p.stats.add(b)
+	p.n++
+}
+
+// write writes each of the column's pages to w.
+func (c *column) write(w io.Writer, meta *Metadata) error {
+	for _, pg := range c.pages {
+		vals := pg.buf.Bytes()
+		if pg.bools != nil {
+			vals = packBools(pg.bools)
+		}
+
+		if c.maxDef == 0 {
+			f := NewRequiredField(c.field.Path, func(r *RequiredField) { r.compression = c.compression })
+			if err := f.DoWrite(w, meta, vals, pg.n, pg.stats); err != nil {
+				return err
+			}
+			continue
+		}
+
+		f := NewOptionalField(c.field.Path, c.field.Types, func(o *OptionalField) { o.compression = c.compression })
+		f.Defs = pg.defs
+		f.Reps = pg.reps
+		if err := f.DoWrite(w, meta, vals, len(pg.defs), pg.stats); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func packBools(vals []bool) []byte {
+	out := make([]byte, (len(vals)+7)/8)
+	for i, v := range vals {
+		if v {
+			out[i/8] = out[i/8] | (1 << uint32(i%8))
+		}
+	}
+	return out
+}
+
+// columnStats implements Stats for columns that are written
+// without generated code.  Values are compared according to
+// the column's physical (and converted) type.
+type columnStats struct {
+	se       sch.SchemaElement
+	optional bool
+	min      []byte
+	max      []byte
+	nulls    int64
+}
+
+func newColumnStats(se sch.SchemaElement, optional bool) *columnStats {
+	return &columnStats{se: se, optional: optional}
+}
+
+func (s *columnStats) add(b []byte) {
+	if s.min == nil || compareValues(s.se, b, s.min) < 0 {
+		s.min = append([]byte{}, b...)
+	}
+	if s.max == nil || compareValues(s.se, b, s.max) > 0 {
+		s.max = append([]byte{}, b...)
+	}
+}
+
+// NullCount returns the number of missing values.
+func (s *columnStats) NullCount() *int64 {
+	if !s.optional {
+		return nil
+	}
+	n := s.nulls
+	return &n
+}
+
+// DistinctCount isn't tracked.
+func (s *columnStats) DistinctCount() *int64 {
+	return nil
+}
+
+// Min returns the smallest value of the page.
+func (s *columnStats) Min() []byte {
+	return s.min
+}
+
+// Max returns the largest value of the page.
+func (s *columnStats) Max() []byte {
+	return s.max
+}
+
+// compareValues compares two PLAIN encoded values (byte arrays
+// without their length prefix).
+func compareValues(se sch.SchemaElement, a, b []byte) int {
+	unsigned := se.ConvertedType != nil && (*se.ConvertedType == sch.ConvertedType_UINT_32 || *se.ConvertedType == sch.ConvertedType_UINT_64)
+	switch *se.Type {
+	case sch.Type_INT32:
+		x, y := binary.LittleEndian.Uint32(a), binary.LittleEndian.Uint32(b)
+		if unsigned {
+			return compareOrdered(x < y, x > y)
+		}
+		return compareOrdered(int32(x) < int32(y), int32(x) > int32(y))
+	case sch.Type_INT64:
+		x, y := binary.LittleEndian.Uint64(a), binary.LittleEndian.Uint64(b)
+		if unsigned {
+			return compareOrdered(x < y, x > y)
+		}
+		return compareOrdered(int64(x) < int64(y), int64(x) > int64(y))
+	case sch.Type_FLOAT:
+		x, y := math.Float32frombits(binary.LittleEndian.Uint32(a)), math.Float32frombits(binary.LittleEndian.Uint32(b))
+		return compareOrdered(x < y, x > y)
+	case sch.Type_DOUBLE:
+		x, y := math.Float64frombits(binary.LittleEndian.Uint64(a)), math.Float64frombits(binary.LittleEndian.Uint64(b))
+		return compareOrdered(x < y, x > y)
+	default:
+		return bytes.Compare(a, b)
+	}
+}
+
+func compareOrdered(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// columnValues holds the decoded values and levels of
+// a column chunk.
+type columnValues struct {
+	vals interface{}
+	defs []uint8
+	reps []uint8
+	n    int
+
+	// vi and li are the positions of the next value and
+	// level to be read.
+	vi int
+	li int
+}
+
+// record calls fn with the definition level and the index of
+// each repeated field (ind[i] is the index at repetition level i+1)
+// of each of the values (or missing values) of the current record.
+func (c *columnValues) record(maxRep uint8, fn func(def uint8, ind []int)) {
+	if c.defs == nil {
+		if c.vi < c.n {
+			fn(0, nil)
+		}
+		return
+	}
+
+	ind := make([]int, maxRep)
+	for i := 0; c.li < len(c.defs); i++ {
+		var rep uint8
+		if c.reps != nil {
+			rep = c.reps[c.li]
+		}
+
+		if i > 0 && rep == 0 {
+			break
+		}
+
+		if rep > 0 {
+			ind[rep-1]++
+			for j := int(rep); j < len(ind); j++ {
+				ind[j] = 0
+			}
+		}
+
+		fn(c.defs[c.li], ind)
+		c.li++
+	}
+}
+
+// readColumn reads a column chunk and decodes its values.
+func readColumn(r io.ReadSeeker, f Field, pg Page) (*columnValues, error) {
+	var z int32
+	se := sch.SchemaElement{TypeLength: &z, Scale: &z, Precision: &z}
+	f.Type(&se)
+
+	rts := getRepetitionTypes(f.Types)
+	if rts.MaxDef() == 0 {
+		rf := NewRequiredField(f.Path)
+		rr, sizes, err := rf.DoRead(r, pg)
+		if err != nil {
+			return nil, err
+		}
+
+		vals, err := decodePlain(*se.Type, rr, pg.N, sizes)
+		return &columnValues{vals: vals, n: pg.N}, err
+	}
+
+	of := NewOptionalField(f.Path, f.Types)
+	rr, sizes, err := of.DoRead(r, pg)
+	if err != nil {
+		return nil, err
+	}
+
+	n := of.Values()
+	vals, err := decodePlain(*se.Type, rr, n, sizes)
+	return &columnValues{vals: vals, defs: of.Defs, reps: of.Reps, n: n}, err
+}
+
+// decodePlain decodes n PLAIN encoded values of type t.
+func decodePlain(t sch.Type, r io.Reader, n int, sizes []int) (interface{}, error) {
+	switch t {
+	case sch.Type_INT32:
+		out := make([]int32, n)
+		return out, binary.Read(r, binary.LittleEndian, &out)
+	case sch.Type_INT64:
+		out := make([]int64, n)
+		return out, binary.Read(r, binary.LittleEndian, &out)
+	case sch.Type_FLOAT:
+		out := make([]float32, n)
+		return out, binary.Read(r, binary.LittleEndian, &out)
+	case sch.Type_DOUBLE:
+		out := make([]float64, n)
+		return out, binary.Read(r, binary.LittleEndian, &out)
+	case sch.Type_BOOLEAN:
+		return GetBools(r, n, sizes)
+	case sch.Type_BYTE_ARRAY:
+		out := make([]string, n)
+		for i := range out {
+			var l int32
+			if err := binary.Read(r, binary.LittleEndian, &l); err != nil {
+				return nil, err
+			}
+			s := make([]byte, l)
+			if _, err := io.ReadFull(r, s); err != nil {
+				return nil, err
+			}
+			out[i] = string(s)
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("unsupported column type: %s", t)
+	}
+}
+
+// setValue sets v to the i'th value in vals.
+func setValue(v reflect.Value, vals interface{}, i int) {
+	switch vals := vals.(type) {
+	case []int32:
+		if v.Kind() == reflect.Uint32 {
+			v.SetUint(uint64(uint32(vals[i])))
+		} else {
+			v.SetInt(int64(vals[i]))
+		}
+	case []int64:
+		if v.Kind() == reflect.Uint64 {
+			v.SetUint(uint64(vals[i]))
+		} else {
+			v.SetInt(vals[i])
+		}
+	case []float32:
+		v.SetFloat(float64(vals[i]))
+	case []float64:
+		v.SetFloat(vals[i])
+	case []bool:
+		v.SetBool(vals[i])
+	case []string:
+		v.SetString(vals[i])
+	}
+}
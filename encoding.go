@@ -0,0 +1,214 @@
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/parsyl/parquet/internal/bitpack"
+	"github.com/parsyl/parquet/internal/bytestreamsplit"
+	"github.com/parsyl/parquet/internal/delta"
+	"github.com/parsyl/parquet/internal/rle"
+	sch "github.com/parsyl/parquet/schema"
+)
+
+// encodingTypes holds the column types that can be written
+// with each of the supported encodings (other than PLAIN).
+var encodingTypes = map[sch.Encoding][]sch.Type{
+	sch.Encoding_DELTA_BINARY_PACKED:     {sch.Type_INT32, sch.Type_INT64},
+	sch.Encoding_DELTA_LENGTH_BYTE_ARRAY: {sch.Type_BYTE_ARRAY},
+	sch.Encoding_DELTA_BYTE_ARRAY:        {sch.Type_BYTE_ARRAY},
+	sch.Encoding_BYTE_STREAM_SPLIT:       {sch.Type_FLOAT, sch.Type_DOUBLE},
+	sch.Encoding_RLE:                     {sch.Type_BOOLEAN},
+}
+
+// typeWidths holds the number of bytes of each fixed width type.
+var typeWidths = map[sch.Type]int{
+	sch.Type_INT32:  4,
+	sch.Type_INT64:  8,
+	sch.Type_FLOAT:  4,
+	sch.Type_DOUBLE: 8,
+}
+
+// checkEncoding returns an error that wraps ErrUnsupportedEncoding
+// if a column of type t can't be written with enc.
+func checkEncoding(column string, t sch.Type, enc sch.Encoding) error {
+	if enc == sch.Encoding_PLAIN {
+		return nil
+	}
+
+	for _, et := range encodingTypes[enc] {
+		if et == t {
+			return nil
+		}
+	}
+	return fmt.Errorf("column %s: %w: %s can't be used with %s", column, ErrUnsupportedEncoding, enc, t)
+}
+
+func hasEncoding(encs []sch.Encoding, enc sch.Encoding) bool {
+	for _, e := range encs {
+		if e == enc {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeValues transcodes n PLAIN encoded values of type t to enc.
+func encodeValues(enc sch.Encoding, t sch.Type, plain []byte, n int) ([]byte, error) {
+	switch {
+	case enc == sch.Encoding_PLAIN:
+		return plain, nil
+	case enc == sch.Encoding_DELTA_BINARY_PACKED && t == sch.Type_INT32:
+		vals := make([]int32, n)
+		if err := binary.Read(bytes.NewReader(plain), binary.LittleEndian, vals); err != nil {
+			return nil, err
+		}
+		return delta.EncodeInt32(vals), nil
+	case enc == sch.Encoding_DELTA_BINARY_PACKED && t == sch.Type_INT64:
+		vals := make([]int64, n)
+		if err := binary.Read(bytes.NewReader(plain), binary.LittleEndian, vals); err != nil {
+			return nil, err
+		}
+		return delta.EncodeInt64(vals), nil
+	case enc == sch.Encoding_DELTA_LENGTH_BYTE_ARRAY && t == sch.Type_BYTE_ARRAY:
+		vals, err := plainByteArrays(plain, n)
+		if err != nil {
+			return nil, err
+		}
+		return delta.EncodeLengthByteArray(vals), nil
+	case enc == sch.Encoding_DELTA_BYTE_ARRAY && t == sch.Type_BYTE_ARRAY:
+		vals, err := plainByteArrays(plain, n)
+		if err != nil {
+			return nil, err
+		}
+		return delta.EncodeByteArray(vals), nil
+	case enc == sch.Encoding_BYTE_STREAM_SPLIT && (t == sch.Type_FLOAT || t == sch.Type_DOUBLE):
+		if len(plain) != n*typeWidths[t] {
+			return nil, fmt.Errorf("got %d bytes for %d %s values", len(plain), n, t)
+		}
+		return bytestreamsplit.Encode(plain, typeWidths[t])
+	case enc == sch.Encoding_RLE && t == sch.Type_BOOLEAN:
+		vals, ok := bitpack.UnpackUint64(1, plain, n)
+		if !ok {
+			return nil, fmt.Errorf("got %d bytes for %d %s values", len(plain), n, t)
+		}
+
+		r, err := rle.New(1, n/8+1)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range vals {
+			r.WriteUint64(v)
+		}
+		return r.Bytes(), nil
+	default:
+		return nil, fmt.Errorf("%w: %s for %s", ErrUnsupportedEncoding, enc, t)
+	}
+}
+
+// decodeValues transcodes n values of type t that are encoded
+// with enc to PLAIN.
+func decodeValues(enc sch.Encoding, t sch.Type, data []byte, n int) ([]byte, error) {
+	var vals interface{}
+	var l int
+	var err error
+	switch {
+	case enc == sch.Encoding_PLAIN:
+		return data, nil
+	case enc == sch.Encoding_BYTE_STREAM_SPLIT && (t == sch.Type_FLOAT || t == sch.Type_DOUBLE):
+		if len(data) != n*typeWidths[t] {
+			return nil, fmt.Errorf("got %d bytes for %d %s values", len(data), n, t)
+		}
+		return bytestreamsplit.Decode(data, typeWidths[t])
+	case enc == sch.Encoding_RLE && t == sch.Type_BOOLEAN:
+		r, err := rle.New(1, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		vals, _, err := r.ReadUint64(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+
+		// the last bit packed run is padded to a multiple of 8
+		if len(vals) < n {
+			return nil, fmt.Errorf("got %d %s values, expected %d", len(vals), enc, n)
+		}
+		return bitpack.PackUint64(nil, 1, vals[:n]), nil
+	case enc == sch.Encoding_DELTA_BINARY_PACKED && t == sch.Type_INT32:
+		var v []int32
+		v, _, err = delta.DecodeInt32(data)
+		vals, l = v, len(v)
+	case enc == sch.Encoding_DELTA_BINARY_PACKED && t == sch.Type_INT64:
+		var v []int64
+		v, _, err = delta.DecodeInt64(data)
+		vals, l = v, len(v)
+	case enc == sch.Encoding_DELTA_LENGTH_BYTE_ARRAY && t == sch.Type_BYTE_ARRAY:
+		var v [][]byte
+		v, _, err = delta.DecodeLengthByteArray(data)
+		vals, l = v, len(v)
+	case enc == sch.Encoding_DELTA_BYTE_ARRAY && t == sch.Type_BYTE_ARRAY:
+		var v [][]byte
+		v, _, err = delta.DecodeByteArray(data)
+		vals, l = v, len(v)
+	default:
+		return nil, fmt.Errorf("%w: %s for %s", ErrUnsupportedEncoding, enc, t)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if l != n {
+		return nil, fmt.Errorf("got %d %s values, expected %d", l, enc, n)
+	}
+
+	if v, ok := vals.([][]byte); ok {
+		return plainByteArrayBytes(v), nil
+	}
+
+	var buf bytes.Buffer
+	err = binary.Write(&buf, binary.LittleEndian, vals)
+	return buf.Bytes(), err
+}
+
+// plainByteArrays splits n PLAIN encoded byte arrays (each one
+// is prefixed with its 4 byte length).
+func plainByteArrays(plain []byte, n int) ([][]byte, error) {
+	out := make([][]byte, n)
+	for i := range out {
+		if len(plain) < 4 {
+			return nil, fmt.Errorf("got %d byte arrays, expected %d", i, n)
+		}
+
+		l := binary.LittleEndian.Uint32(plain)
+		plain = plain[4:]
+		if uint64(l) > uint64(len(plain)) {
+			return nil, fmt.Errorf("byte array of %d bytes is longer than the data", l)
+		}
+
+		out[i] = plain[:l]
+		plain = plain[l:]
+	}
+	return out, nil
+}
+
+// plainByteArrayBytes PLAIN encodes vals.
+func plainByteArrayBytes(vals [][]byte) []byte {
+	size := 4 * len(vals)
+	for _, v := range vals {
+		size += len(v)
+	}
+
+	out := make([]byte, 0, size)
+	var l [4]byte
+	for _, v := range vals {
+		binary.LittleEndian.PutUint32(l[:], uint32(len(v)))
+		out = append(out, l[:]...)
+		out = append(out, v...)
+	}
+	return out
+}
@@ -0,0 +1,106 @@
+package parquet
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrUnsupportedCodec is returned (wrapped, along with the
+	// name of the codec) when a page is compressed with a codec
+	// that isn't supported.
+	ErrUnsupportedCodec = errors.New("unsupported column chunk codec")
+
+	// ErrUnsupportedPageType is returned (wrapped, along with the
+	// type of the page) when a column chunk contains a page that
+	// can't be read, a dictionary page for example.
+	ErrUnsupportedPageType = errors.New("unsupported page type")
+
+	// ErrUnsupportedEncoding is returned (wrapped, along with
+	// the name of the encoding) when a page's values are encoded
+	// with an encoding that isn't supported, or, when writing, the
+	// encoding can't be used for the type of the column.
+	ErrUnsupportedEncoding = errors.New("unsupported encoding")
+
+	// ErrChecksum is returned (wrapped in an *ErrCorruptPage)
+	// when the CRC32 of a page's data doesn't match the checksum
+	// in its page header.
+	ErrChecksum = errors.New("page checksum mismatch")
+)
+
+// ErrCorruptPage is returned when a page of a column chunk can't be
+// decoded.  Offset is the position of the page in the file and Err
+// is the underlying error.  Errors returned by the io.Reader itself
+// are not wrapped in an ErrCorruptPage.
+type ErrCorruptPage struct {
+	Column string
+	Offset int64
+	Err    error
+}
+
+func (e *ErrCorruptPage) Error() string {
+	return fmt.Sprintf("corrupt page at offset %d of column %s: %s", e.Offset, e.Column, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *ErrCorruptPage) Unwrap() error {
+	return e.Err
+}
+
+// ErrSchemaMismatch is returned when a column doesn't match the
+// schema that it is being written or read with.
+type ErrSchemaMismatch struct {
+	Column string
+	Reason string
+}
+
+func (e *ErrSchemaMismatch) Error() string {
+	return fmt.Sprintf("column %s: %s", e.Column, e.Reason)
+}
+
+// RecordCountError is returned when a column doesn't have the
+// same number of records as its row group.
+type RecordCountError struct {
+	Column   string
+	Records  int64
+	Expected int64
+}
+
+func (e *RecordCountError) Error() string {
+	return fmt.Sprintf("column %s has %d records, expected %d", e.Column, e.Records, e.Expected)
+}
+
+// CheckRecords returns a *RecordCountError if a column chunk
+// of pg.N values (and the repetition levels reps, if the column
+// is repeated) doesn't have the given number of records.
+func CheckRecords(name string, pg Page, reps []uint8, records int64) error {
+	n := int64(pg.N)
+	if len(reps) > 0 {
+		n = 0
+		for _, rep := range reps {
+			if rep == 0 {
+				n++
+			}
+		}
+	}
+
+	if n != records {
+		return &RecordCountError{Column: name, Records: n, Expected: records}
+	}
+	return nil
+}
+
+// pageError turns an error that happened while reading the page
+// at offset into an *ErrCorruptPage, unless it was returned by
+// the reader itself or is because of an unsupported feature.
+func pageError(column string, offset int64, rc *readCounter, err error) error {
+	if rc.err != nil {
+		return rc.err
+	}
+
+	if errors.Is(err, ErrUnsupportedCodec) || errors.Is(err, ErrUnsupportedPageType) || errors.Is(err, ErrUnsupportedEncoding) {
+		return err
+	}
+
+	return &ErrCorruptPage{Column: column, Offset: offset, Err: err}
+}
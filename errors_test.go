@@ -0,0 +1,126 @@
+package parquet_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/parsyl/parquet"
+	sch "github.com/parsyl/parquet/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+var errBoom = errors.New("boom")
+
+// boomReader fails every read of the bytes from start to end.
+type boomReader struct {
+	*bytes.Reader
+	start, end int64
+}
+
+func (r *boomReader) Read(p []byte) (int, error) {
+	pos := r.Size() - int64(r.Len())
+	if pos >= r.start && pos < r.end {
+		return 0, errBoom
+	}
+	return r.Reader.Read(p)
+}
+
+func TestErrors(t *testing.T) {
+	b, err := generatedWrite(people, 2)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	footer, err := parquet.ReadMetaData(bytes.NewReader(b))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var ch *sch.ColumnChunk
+	for _, c := range footer.RowGroups[0].Columns {
+		if c.MetaData.PathInSchema[0] == "age" {
+			ch = c
+		}
+	}
+	if !assert.NotNil(t, ch) {
+		return
+	}
+
+	readAge := func(b []byte, r func(*bytes.Reader) io.ReadSeeker) error {
+		f, err := parquet.OpenFile(r(bytes.NewReader(b)))
+		if err != nil {
+			return err
+		}
+		_, err = f.RowGroup(0).Column("age")
+		return err
+	}
+
+	t.Run("corrupt page", func(t *testing.T) {
+		corrupt := append([]byte{}, b...)
+		for i := int64(0); i < 8; i++ {
+			corrupt[ch.MetaData.DataPageOffset+i] = 0xff
+		}
+
+		err := readAge(corrupt, func(r *bytes.Reader) io.ReadSeeker { return r })
+		var pe *parquet.ErrCorruptPage
+		if assert.True(t, errors.As(err, &pe), err) {
+			assert.Equal(t, "age", pe.Column)
+			assert.Equal(t, ch.MetaData.DataPageOffset, pe.Offset)
+			assert.NotNil(t, pe.Unwrap())
+		}
+	})
+
+	t.Run("io error", func(t *testing.T) {
+		err := readAge(b, func(r *bytes.Reader) io.ReadSeeker {
+			return &boomReader{Reader: r, start: ch.MetaData.DataPageOffset, end: ch.MetaData.DataPageOffset + ch.MetaData.TotalCompressedSize}
+		})
+		assert.True(t, errors.Is(err, errBoom), err)
+		var pe *parquet.ErrCorruptPage
+		assert.False(t, errors.As(err, &pe))
+	})
+
+	t.Run("unsupported codec", func(t *testing.T) {
+		lzo, err := rewriteFooter(b, func(footer *sch.FileMetaData) {
+			for _, rg := range footer.RowGroups {
+				for _, c := range rg.Columns {
+					c.MetaData.Codec = sch.CompressionCodec_LZO
+				}
+			}
+		})
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		err = readAge(lzo, func(r *bytes.Reader) io.ReadSeeker { return r })
+		assert.True(t, errors.Is(err, parquet.ErrUnsupportedCodec), err)
+
+		_, err = NewParquetReader(bytes.NewReader(lzo))
+		assert.True(t, errors.Is(err, parquet.ErrUnsupportedCodec), err)
+		assert.EqualError(t, err, "unable to read field id, err: unsupported column chunk codec: LZO")
+	})
+
+	t.Run("schema mismatch", func(t *testing.T) {
+		var buf bytes.Buffer
+		w, err := parquet.NewFileWriter(&buf, []parquet.Field{
+			{Path: []string{"bogus"}, Types: []int{0}, Type: parquet.Int32Type, RepetitionType: parquet.RepetitionRequired},
+		})
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		assert.NoError(t, w.StartRowGroup(1))
+		err = w.WriteColumn("bogus", []string{"a"}, nil, nil)
+		var se *parquet.ErrSchemaMismatch
+		if assert.True(t, errors.As(err, &se), err) {
+			assert.Equal(t, "bogus", se.Column)
+		}
+
+		assert.NoError(t, w.WriteColumn("bogus", []int32{1}, nil, nil))
+		assert.NoError(t, w.Close())
+
+		_, err = NewParquetReader(bytes.NewReader(buf.Bytes()))
+		assert.Equal(t, &parquet.ErrSchemaMismatch{Column: "bogus", Reason: "not in the schema"}, err)
+	})
+}
@@ -3,6 +3,8 @@ package parquet
 import (
 	"bytes"
 	"compress/gzip"
+	"hash/crc32"
+	"math"
 	"math/bits"
 	"strings"
 
@@ -11,6 +13,7 @@ import (
 	"fmt"
 
 	"io"
+	"io/ioutil"
 
 	"github.com/golang/snappy"
 	"github.com/parsyl/parquet/internal/rle"
@@ -32,6 +35,22 @@ var (
 	buffpool = bytebufferpool.Pool{}
 )
 
+// GetBuffer returns an empty buffer from the pool that is used
+// to encode pages.  Give it back with PutBuffer.
+func GetBuffer() *bytebufferpool.ByteBuffer {
+	return buffpool.Get()
+}
+
+// PutBuffer returns a buffer from GetBuffer to the pool.
+func PutBuffer(b *bytebufferpool.ByteBuffer) {
+	buffpool.Put(b)
+}
+
+// MaxDepth is the largest definition (and repetition) level, so
+// a column can have at most MaxDepth optional and repeated fields
+// in its path.
+const MaxDepth = math.MaxUint8
+
 type RepetitionTypes []RepetitionType
 
 // MaxDef returns the largest definition level
@@ -56,9 +75,27 @@ func (r RepetitionTypes) MaxRep() uint8 {
 	return out
 }
 
+// CheckDepth returns an error if the column name, which has the
+// given repetition types, is nested in more than MaxDepth optional
+// and repeated fields.
+func CheckDepth(name string, types []int) error {
+	var n int
+	for _, t := range types {
+		if RepetitionType(t) != Required {
+			n++
+		}
+	}
+
+	if n > MaxDepth {
+		return fmt.Errorf("column %s has %d optional and repeated levels (at most %d are supported)", name, n, MaxDepth)
+	}
+	return nil
+}
+
 // RequiredField writes the raw data for required columns
 type RequiredField struct {
 	pth         []string
+	ids         []int32
 	compression sch.CompressionCodec
 }
 
@@ -92,22 +129,40 @@ func RequiredFieldUncompressed(r *RequiredField) {
 	r.compression = sch.CompressionCodec_UNCOMPRESSED
 }
 
+// RequiredFieldCompression sets the compression for a column to
+// c.  It is an optional arg to NewRequiredField
+func RequiredFieldCompression(c sch.CompressionCodec) func(*RequiredField) {
+	return func(r *RequiredField) {
+		r.compression = c
+	}
+}
+
+// RequiredFieldIDs sets the field ID of each element of the
+// field's path (0 if an element doesn't have one).  It is an
+// optional arg to NewRequiredField.
+func RequiredFieldIDs(ids ...int32) func(*RequiredField) {
+	return func(r *RequiredField) {
+		r.ids = ids
+	}
+}
+
 // DoWrite writes the actual raw data.
 func (f *RequiredField) DoWrite(w io.Writer, meta *Metadata, vals []byte, count int, stats Stats) error {
 	buff := buffpool.Get()
 	defer buffpool.Put(buff)
 
-	l, cl, vals, err := compress(f.compression, buff, vals)
+	enc, t := meta.encoding(f.pth)
+	vals, err := encodeValues(enc, t, vals, count)
 	if err != nil {
 		return err
 	}
 
-	if err := meta.WritePageHeader(w, f.pth, l, cl, count, count, 0, 0, f.compression, stats); err != nil {
+	l, _, vals, err := compress(f.compression, buff, vals)
+	if err != nil {
 		return err
 	}
 
-	_, err = w.Write(vals)
-	return err
+	return meta.writePage(w, f.pth, l, vals, count, f.compression, enc, stats)
 }
 
 // DoRead reads the actual raw data.
@@ -115,17 +170,26 @@ func (f *RequiredField) DoRead(r io.ReadSeeker, pg Page) (io.Reader, []int, erro
 	var nRead int
 	var out []byte
 	var sizes []int
+	rc := &readCounter{r: r}
 	for nRead < pg.N {
-		ph, err := PageHeader(r)
+		offset := pg.Offset + rc.n
+		ph, err := dataPageHeader(rc)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, pageError(f.Name(), offset, rc, err)
 		}
 
 		sizes = append(sizes, int(ph.DataPageHeader.NumValues))
 
-		data, err := pageData(r, ph, pg)
+		data, err := pageData(rc, ph, pg)
+		if err == nil {
+			data, err = decodeValues(ph.DataPageHeader.Encoding, pg.Type, data, int(ph.DataPageHeader.NumValues))
+		}
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, pageError(f.Name(), offset, rc, err)
+		}
+
+		if rc.n > int64(pg.Size) {
+			return nil, nil, &ErrCorruptPage{Column: f.Name(), Offset: offset, Err: fmt.Errorf("column chunk of %d bytes has fewer than %d values", pg.Size, pg.N)}
 		}
 
 		out = append(out, data...)
@@ -134,6 +198,34 @@ func (f *RequiredField) DoRead(r io.ReadSeeker, pg Page) (io.Reader, []int, erro
 	return bytes.NewBuffer(out), sizes, nil
 }
 
+// dataPageHeader reads the next data page header, skipping
+// any index pages.
+func dataPageHeader(r io.Reader) (*sch.PageHeader, error) {
+	for {
+		ph, err := PageHeader(r)
+		if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case ph.Type == sch.PageType_INDEX_PAGE:
+			if _, err := io.CopyN(ioutil.Discard, r, int64(ph.CompressedPageSize)); err != nil {
+				return nil, err
+			}
+		case ph.Type != sch.PageType_DATA_PAGE || ph.DataPageHeader == nil:
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedPageType, ph.Type)
+		default:
+			return ph, nil
+		}
+	}
+}
+
+// DoReadAt is DoRead for an io.ReaderAt.  It reads the column
+// chunk at pg.Offset without depending on a seek position.
+func (f *RequiredField) DoReadAt(r io.ReaderAt, pg Page) (io.Reader, []int, error) {
+	return f.DoRead(io.NewSectionReader(r, pg.Offset, int64(pg.Size)), pg)
+}
+
 // Name returns the column name of this field
 func (f *RequiredField) Name() string {
 	return strings.Join(f.pth, ".")
@@ -144,6 +236,12 @@ func (f *RequiredField) Path() []string {
 	return f.pth
 }
 
+// IDs returns the field IDs of the elements of the field's path
+// (nil if it doesn't have any).
+func (f *RequiredField) IDs() []int32 {
+	return f.ids
+}
+
 // MaxLevel holds the maximum definition and
 // repeptition level for a given field.
 type MaxLevel struct {
@@ -151,12 +249,39 @@ type MaxLevel struct {
 	Rep uint8
 }
 
+// Levels holds the definition and repetition
+// levels of a column.
+type Levels struct {
+	Name string
+	Defs []uint8
+	Reps []uint8
+}
+
+// Indices keeps track of the indices of repeated fields
+// that have already been handled by a previous field
+// (it is used by the code that parquetgen generates).
+type Indices []int
+
+// Rep moves the index of the repeated field at
+// repetition level rep to its next element and
+// resets the indices of the fields nested in it.
+func (i Indices) Rep(rep uint8) {
+	if rep > 0 {
+		r := int(rep) - 1
+		i[r] = i[r] + 1
+		for j := int(rep); j < len(i); j++ {
+			i[j] = 0
+		}
+	}
+}
+
 // OptionalField is any exported field in a
 // struct that is a pointer.
 type OptionalField struct {
 	Defs           []uint8
 	Reps           []uint8
 	pth            []string
+	ids            []int32
 	MaxLevels      MaxLevel
 	compression    sch.CompressionCodec
 	RepetitionType FieldFunc
@@ -211,6 +336,23 @@ func OptionalFieldUncompressed(o *OptionalField) {
 	o.compression = sch.CompressionCodec_UNCOMPRESSED
 }
 
+// OptionalFieldCompression sets the compression for a column to
+// c.  It is an optional arg to NewOptionalField
+func OptionalFieldCompression(c sch.CompressionCodec) func(*OptionalField) {
+	return func(o *OptionalField) {
+		o.compression = c
+	}
+}
+
+// OptionalFieldIDs sets the field ID of each element of the
+// field's path (0 if an element doesn't have one).  It is an
+// optional arg to NewOptionalField.
+func OptionalFieldIDs(ids ...int32) func(*OptionalField) {
+	return func(o *OptionalField) {
+		o.ids = ids
+	}
+}
+
 // Values reads the definition levels and uses them
 // to return the values from the page data.
 func (f *OptionalField) Values() int {
@@ -232,42 +374,38 @@ func (f *OptionalField) valsFromDefs(defs []uint8, max uint8) int {
 func (f *OptionalField) DoWrite(w io.Writer, meta *Metadata, vals []byte, count int, stats Stats) error {
 	buf := buffpool.Get()
 	defer buffpool.Put(buf)
-	wc := &writeCounter{w: buf}
-
-	var repLen int64
 
 	if f.repeated {
-		err := writeLevels(wc, f.Reps, int32(bits.Len(uint(f.MaxLevels.Rep))))
+		err := writeLevels(buf, f.Reps, int32(bits.Len(uint(f.MaxLevels.Rep))))
 		if err != nil {
 			return err
 		}
-		repLen = wc.n
 	}
 
-	err := writeLevels(wc, f.Defs, int32(bits.Len(uint(f.MaxLevels.Def))))
+	err := writeLevels(buf, f.Defs, int32(bits.Len(uint(f.MaxLevels.Def))))
 	if err != nil {
 		return err
 	}
 
-	defLen := wc.n - repLen
+	enc, t := meta.encoding(f.pth)
+	vals, err = encodeValues(enc, t, vals, f.Values())
+	if err != nil {
+		return err
+	}
 
-	if _, err = wc.Write(vals); err != nil {
+	if _, err = buf.Write(vals); err != nil {
 		return err
 	}
 
 	compressed := buffpool.Get()
 	defer buffpool.Put(compressed)
 
-	l, cl, vals, err := compress(f.compression, compressed, buf.Bytes())
+	l, _, vals, err := compress(f.compression, compressed, buf.Bytes())
 	if err != nil {
 		return err
 	}
 
-	if err := meta.WritePageHeader(w, f.pth, l, cl, len(f.Defs), count, defLen, repLen, f.compression, stats); err != nil {
-		return err
-	}
-	_, err = w.Write(vals)
-	return err
+	return meta.writePage(w, f.pth, l, vals, count, f.compression, enc, stats)
 }
 
 // DoRead is called by all optional fields.  It reads the definition levels and uses
@@ -279,43 +417,62 @@ func (f *OptionalField) DoRead(r io.ReadSeeker, pg Page) (io.Reader, []int, erro
 	var rc *readCounter
 
 	for nRead < pg.Size {
+		offset := pg.Offset + int64(nRead)
 		rc = &readCounter{r: r}
-		ph, err := PageHeader(rc)
+		ph, err := dataPageHeader(rc)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, pageError(f.Name(), offset, rc, err)
 		}
 
 		data, err := pageData(rc, ph, pg)
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, pageError(f.Name(), offset, rc, err)
 		}
 
 		var l int
+		n := int(ph.DataPageHeader.NumValues)
 
 		if f.repeated {
 			reps, l2, err := readLevels(bytes.NewBuffer(data[l:]), int32(bits.Len(uint(f.MaxLevels.Rep))))
+			if err == nil && len(reps) < n {
+				err = fmt.Errorf("got %d repetition levels, expected %d", len(reps), n)
+			}
 			if err != nil {
-				return nil, nil, err
+				return nil, nil, &ErrCorruptPage{Column: f.Name(), Offset: offset, Err: err}
 			}
-			f.Reps = append(f.Reps, reps[:int(ph.DataPageHeader.NumValues)]...)
+			f.Reps = append(f.Reps, reps[:n]...)
 			l += l2
 		}
 
 		defs, l2, err := readLevels(bytes.NewBuffer(data[l:]), int32(bits.Len(uint(f.MaxLevels.Def))))
+		if err == nil && len(defs) < n {
+			err = fmt.Errorf("got %d definition levels, expected %d", len(defs), n)
+		}
 		if err != nil {
-			return nil, nil, err
+			return nil, nil, &ErrCorruptPage{Column: f.Name(), Offset: offset, Err: err}
 		}
-		f.Defs = append(f.Defs, defs[:int(ph.DataPageHeader.NumValues)]...)
+		f.Defs = append(f.Defs, defs[:n]...)
 		l += l2
 
-		n := f.valsFromDefs(defs, uint8(f.MaxLevels.Def))
-		sizes = append(sizes, n)
-		out = append(out, data[l:]...)
+		nVals := f.valsFromDefs(defs[:n], uint8(f.MaxLevels.Def))
+		vals, err := decodeValues(ph.DataPageHeader.Encoding, pg.Type, data[l:], nVals)
+		if err != nil {
+			return nil, nil, pageError(f.Name(), offset, rc, err)
+		}
+
+		sizes = append(sizes, nVals)
+		out = append(out, vals...)
 		nRead += int(rc.n)
 	}
 	return bytes.NewBuffer(out), sizes, nil
 }
 
+// DoReadAt is DoRead for an io.ReaderAt.  It reads the column
+// chunk at pg.Offset without depending on a seek position.
+func (f *OptionalField) DoReadAt(r io.ReaderAt, pg Page) (io.Reader, []int, error) {
+	return f.DoRead(io.NewSectionReader(r, pg.Offset, int64(pg.Size)), pg)
+}
+
 // Name returns the column name of this field
 func (f *OptionalField) Name() string {
 	return strings.Join(f.pth, ".")
@@ -326,79 +483,68 @@ func (f *OptionalField) Path() []string {
 	return f.pth
 }
 
-// writeCounter keeps track of the number of bytes written
-// it is used for calls to binary.Write, which does not
-// return the number of bytes written.
-type writeCounter struct {
-	n int64
-	w io.Writer
-}
-
-// Write makes writeCounter an io.Writer
-func (w *writeCounter) Write(p []byte) (int, error) {
-	n, err := w.w.Write(p)
-	w.n += int64(n)
-	return n, err
+// IDs returns the field IDs of the elements of the field's path
+// (nil if it doesn't have any).
+func (f *OptionalField) IDs() []int32 {
+	return f.ids
 }
 
 // readCounter keeps track of the number of bytes written
-// it is used for calls to binary.Write.
+// it is used for calls to binary.Write.  err is the last
+// error (other than io.EOF) returned by r.
 type readCounter struct {
-	n int64
-	r io.Reader
+	n   int64
+	r   io.Reader
+	err error
 }
 
 // Write makes writeCounter an io.Writer
 func (r *readCounter) Read(p []byte) (int, error) {
 	n, err := r.r.Read(p)
 	r.n += int64(n)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
 	return n, err
 }
 
+// pageData reads the data of the page with the header ph,
+// verifies its checksum (if the header has one) and then
+// decompresses it.
 func pageData(r io.Reader, ph *sch.PageHeader, pg Page) ([]byte, error) {
-	var data []byte
 	switch pg.Codec {
-	case sch.CompressionCodec_SNAPPY:
-		compressed := make([]byte, ph.CompressedPageSize)
-		if _, err := r.Read(compressed); err != nil {
-			return nil, err
-		}
+	case sch.CompressionCodec_SNAPPY, sch.CompressionCodec_GZIP, sch.CompressionCodec_UNCOMPRESSED:
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedCodec, pg.Codec)
+	}
 
-		var err error
-		data, err = snappy.Decode(nil, compressed)
-		if err != nil {
-			return nil, err
-		}
-	case sch.CompressionCodec_GZIP:
-		var buf bytes.Buffer
-		_, err := io.CopyN(&buf, r, int64(ph.CompressedPageSize))
-		if err != nil {
-			return nil, err
-		}
+	compressed := make([]byte, ph.CompressedPageSize)
+	if _, err := io.ReadFull(r, compressed); err != nil {
+		return nil, err
+	}
 
-		zr, err := gzip.NewReader(&buf)
+	if ph.Crc != nil && uint32(*ph.Crc) != crc32.ChecksumIEEE(compressed) {
+		return nil, ErrChecksum
+	}
+
+	switch pg.Codec {
+	case sch.CompressionCodec_SNAPPY:
+		return snappy.Decode(nil, compressed)
+	case sch.CompressionCodec_GZIP:
+		zr, err := gzip.NewReader(bytes.NewReader(compressed))
 		if err != nil {
 			return nil, err
 		}
 
-		data, err = io.ReadAll(zr)
+		data, err := io.ReadAll(zr)
 		if err != nil {
 			return nil, err
 		}
 
-		if err := zr.Close(); err != nil {
-			return nil, err
-		}
-	case sch.CompressionCodec_UNCOMPRESSED:
-		data = make([]byte, ph.UncompressedPageSize)
-		if _, err := r.Read(data); err != nil {
-			return nil, err
-		}
+		return data, zr.Close()
 	default:
-		return nil, fmt.Errorf("unsupported column chunk codec: %s", pg.Codec)
+		return compressed, nil
 	}
-
-	return data, nil
 }
 
 func compress(codec sch.CompressionCodec, buf *bytebufferpool.ByteBuffer, vals []byte) (int, int, []byte, error) {
@@ -430,24 +576,34 @@ func compress(codec sch.CompressionCodec, buf *bytebufferpool.ByteBuffer, vals [
 		}
 
 		vals = buf.Bytes()
+	case sch.CompressionCodec_UNCOMPRESSED:
+	default:
+		return l, 0, vals, fmt.Errorf("%w: %s", ErrUnsupportedCodec, codec)
 	}
 	return l, len(vals), vals, err
 }
 
 // writeLevels writes vals to w as RLE/bitpack encoded data
 func writeLevels(w io.Writer, levels []uint8, width int32) error {
-	enc, _ := rle.New(width, len(levels)) //TODO: len(levels) is probably too big.  Chop it down a bit?
+	enc, err := rle.New(width, len(levels)) //TODO: len(levels) is probably too big.  Chop it down a bit?
+	if err != nil {
+		return err
+	}
+
 	for _, l := range levels {
 		enc.Write(l)
 	}
-	_, err := w.Write(enc.Bytes())
+	_, err = w.Write(enc.Bytes())
 	return err
 }
 
 // readLevels reads the RLE/bitpack encoded definition and repetition levels
 func readLevels(in io.Reader, width int32) ([]uint8, int, error) {
-	var out []uint8
-	dec, _ := rle.New(width, 0)
+	dec, err := rle.New(width, 0)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	out, n, err := dec.Read(in)
 	if err != nil {
 		return nil, 0, err
@@ -0,0 +1,294 @@
+package parquet
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	sch "github.com/parsyl/parquet/schema"
+)
+
+// File gives access to the row groups and column chunks of a
+// parquet file so that individual columns can be read without
+// reading whole records.
+type File struct {
+	r       io.ReadSeeker
+	meta    *sch.FileMetaData
+	columns []*rowColumn
+}
+
+// OpenFile reads the metadata of a parquet file.
+func OpenFile(r io.ReadSeeker) (*File, error) {
+	meta, err := ReadMetaData(r)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := schemaColumns(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	return &File{r: r, meta: meta, columns: columns}, nil
+}
+
+// MetaData returns the FileMetaData of the file.
+func (f *File) MetaData() *sch.FileMetaData {
+	return f.meta
+}
+
+// KeyValueMetadata returns the key/value metadata of the file.
+func (f *File) KeyValueMetadata() map[string]string {
+	return keyValueMetadata(f.meta)
+}
+
+// CreatedBy returns the application that wrote the file (if
+// the file says so).
+func (f *File) CreatedBy() string {
+	if f.meta.CreatedBy == nil {
+		return ""
+	}
+	return *f.meta.CreatedBy
+}
+
+// Fields returns the primitive columns of the file.
+func (f *File) Fields() []Field {
+	out := make([]Field, len(f.columns))
+	for i, c := range f.columns {
+		out[i] = c.field
+	}
+	return out
+}
+
+// NumRowGroups returns the number of row groups in the file.
+func (f *File) NumRowGroups() int {
+	return len(f.meta.RowGroups)
+}
+
+// RowGroup returns the i'th row group of the file.  It panics
+// if i is out of range.
+func (f *File) RowGroup(i int) *RowGroupReader {
+	return &RowGroupReader{file: f, rowGroup: f.meta.RowGroups[i]}
+}
+
+// RowGroupReader reads the column chunks of a row group.
+type RowGroupReader struct {
+	file     *File
+	rowGroup *sch.RowGroup
+}
+
+// Rows returns the number of rows in the row group.
+func (r *RowGroupReader) Rows() int64 {
+	return r.rowGroup.NumRows
+}
+
+// Column reads the column chunk of the column at pth (the
+// names of the fields separated by dots, for example
+// "hobby.skills.name").  All of the chunk's pages are read and
+// decoded before Column returns.
+func (r *RowGroupReader) Column(pth string) (*ColumnReader, error) {
+	var col *rowColumn
+	for _, c := range r.file.columns {
+		if c.field.Name == pth {
+			col = c
+			break
+		}
+	}
+
+	if col == nil {
+		return nil, fmt.Errorf("no such column: %s", pth)
+	}
+
+	var ch *sch.ColumnChunk
+	for i, c := range r.rowGroup.Columns {
+		if c.MetaData == nil {
+			return nil, fmt.Errorf("column chunk %d has no metadata", i)
+		}
+
+		if strings.Join(c.MetaData.PathInSchema, ".") == pth {
+			ch = c
+			break
+		}
+	}
+
+	if ch == nil {
+		return nil, fmt.Errorf("missing column chunk for %s", pth)
+	}
+
+	pg := chunkPage(ch)
+	if _, err := r.file.r.Seek(pg.Offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	vals, err := readColumn(r.file.r, col.field, pg)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read field %s, err: %w", pth, err)
+	}
+
+	rts := getRepetitionTypes(col.field.Types)
+	return &ColumnReader{
+		se:     col.pth[len(col.pth)-1].se,
+		field:  col.field,
+		vals:   vals,
+		maxDef: rts.MaxDef(),
+		maxRep: rts.MaxRep(),
+		li:     -1,
+		vi:     -1,
+	}, nil
+}
+
+// ColumnReader iterates over the values of a column chunk along
+// with their definition and repetition levels.  Missing values
+// (values with a definition level less than MaxDef) are
+// included, in which case Null returns true.  The values are
+// held in memory: the whole chunk is decoded by Column rather
+// than one page at a time.
+type ColumnReader struct {
+	se     *sch.SchemaElement
+	field  Field
+	vals   *columnValues
+	maxDef uint8
+	maxRep uint8
+
+	// li and vi are the positions of the current level
+	// and value.
+	li int
+	vi int
+}
+
+// Field returns the column's Field.
+func (c *ColumnReader) Field() Field {
+	return c.field
+}
+
+// Type returns the physical type of the column.
+func (c *ColumnReader) Type() sch.Type {
+	return *c.se.Type
+}
+
+// MaxDef returns the maximum definition level of the column.
+func (c *ColumnReader) MaxDef() uint8 {
+	return c.maxDef
+}
+
+// MaxRep returns the maximum repetition level of the column.
+func (c *ColumnReader) MaxRep() uint8 {
+	return c.maxRep
+}
+
+// Len returns the number of entries (values plus missing values)
+// in the column chunk.
+func (c *ColumnReader) Len() int {
+	if c.vals.defs == nil {
+		return c.vals.n
+	}
+	return len(c.vals.defs)
+}
+
+// Next advances to the next entry of the column chunk.
+func (c *ColumnReader) Next() bool {
+	if c.li+1 >= c.Len() {
+		return false
+	}
+
+	c.li++
+	if !c.Null() {
+		c.vi++
+	}
+	return true
+}
+
+// Levels returns the definition and repetition levels of the
+// current entry.
+func (c *ColumnReader) Levels() (def, rep uint8) {
+	if c.vals.defs != nil {
+		def = c.vals.defs[c.li]
+	}
+	if c.vals.reps != nil {
+		rep = c.vals.reps[c.li]
+	}
+	return def, rep
+}
+
+// Null returns true if the current entry is a missing value.
+func (c *ColumnReader) Null() bool {
+	def, _ := c.Levels()
+	return def < c.maxDef
+}
+
+// Value returns the current value as an int32, uint32, int64,
+// uint64, float32, float64, bool or string (or nil if the value
+// is missing).
+func (c *ColumnReader) Value() interface{} {
+	if c.Null() {
+		return nil
+	}
+	return columnValue(c.vals.vals, c.vi, c.se.ConvertedType)
+}
+
+// Int32 returns the current value of an INT32 column.  It
+// panics if the column is of a different type.  Int32 and the
+// other typed accessors should only be called when Null is false.
+func (c *ColumnReader) Int32() int32 {
+	return c.vals.vals.([]int32)[c.vi]
+}
+
+// Uint32 returns the current value of an INT32 column as
+// an uint32.  It panics if the column is of a different type.
+func (c *ColumnReader) Uint32() uint32 {
+	return uint32(c.vals.vals.([]int32)[c.vi])
+}
+
+// Int64 returns the current value of an INT64 column.  It
+// panics if the column is of a different type.
+func (c *ColumnReader) Int64() int64 {
+	return c.vals.vals.([]int64)[c.vi]
+}
+
+// Uint64 returns the current value of an INT64 column as
+// an uint64.  It panics if the column is of a different type.
+func (c *ColumnReader) Uint64() uint64 {
+	return uint64(c.vals.vals.([]int64)[c.vi])
+}
+
+// Float32 returns the current value of a FLOAT column.  It
+// panics if the column is of a different type.
+func (c *ColumnReader) Float32() float32 {
+	return c.vals.vals.([]float32)[c.vi]
+}
+
+// Float64 returns the current value of a DOUBLE column.  It
+// panics if the column is of a different type.
+func (c *ColumnReader) Float64() float64 {
+	return c.vals.vals.([]float64)[c.vi]
+}
+
+// Bool returns the current value of a BOOLEAN column.  It
+// panics if the column is of a different type.
+func (c *ColumnReader) Bool() bool {
+	return c.vals.vals.([]bool)[c.vi]
+}
+
+// ByteArray returns the current value of a BYTE_ARRAY column.  It
+// panics if the column is of a different type.
+func (c *ColumnReader) ByteArray() string {
+	return c.vals.vals.([]string)[c.vi]
+}
+
+// Values returns all of the column chunk's (non-missing) values
+// as a []int32, []int64, []float32, []float64, []bool or []string.
+func (c *ColumnReader) Values() interface{} {
+	return c.vals.vals
+}
+
+// DefinitionLevels returns all of the column chunk's definition
+// levels (nil if the column is required).
+func (c *ColumnReader) DefinitionLevels() []uint8 {
+	return c.vals.defs
+}
+
+// RepetitionLevels returns all of the column chunk's repetition
+// levels (nil if the column isn't repeated).
+func (c *ColumnReader) RepetitionLevels() []uint8 {
+	return c.vals.reps
+}
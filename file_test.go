@@ -0,0 +1,100 @@
+package parquet_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/parsyl/parquet"
+	sch "github.com/parsyl/parquet/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestColumnReader(t *testing.T) {
+	b, err := generatedWrite(people, 2)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	f, err := parquet.OpenFile(bytes.NewReader(b))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	if !assert.Equal(t, 2, f.NumRowGroups()) {
+		return
+	}
+
+	rg := f.RowGroup(0)
+	assert.Equal(t, int64(5), rg.Rows())
+
+	c, err := rg.Column("age")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, sch.Type_INT32, c.Type())
+	assert.Equal(t, uint8(1), c.MaxDef())
+	assert.Equal(t, uint8(0), c.MaxRep())
+
+	var ages []interface{}
+	for c.Next() {
+		ages = append(ages, c.Value())
+	}
+	assert.Equal(t, []interface{}{int32(-10), nil, nil, nil, nil}, ages)
+
+	c, err = rg.Column("birthday")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var sum uint32
+	for c.Next() {
+		sum += c.Uint32()
+	}
+	assert.Equal(t, uint32(55), sum)
+
+	c, err = rg.Column("hobby.skills.name")
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	type entry struct {
+		val      interface{}
+		def, rep uint8
+	}
+
+	var entries []entry
+	for c.Next() {
+		def, rep := c.Levels()
+		entries = append(entries, entry{c.Value(), def, rep})
+	}
+
+	assert.Equal(t, []entry{
+		{nil, 0, 0},
+		{nil, 0, 0},
+		{nil, 0, 0},
+		{nil, 0, 0},
+		{"meditation", 2, 0},
+		{"calmness", 2, 1},
+	}, entries)
+	assert.Equal(t, []string{"meditation", "calmness"}, c.Values())
+
+	_, err = rg.Column("hobby.skills")
+	assert.EqualError(t, err, "no such column: hobby.skills")
+
+	// a chunk whose metadata isn't in the footer
+	b, err = rewriteFooter(b, func(footer *sch.FileMetaData) {
+		footer.RowGroups[0].Columns[0].MetaData = nil
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	f, err = parquet.OpenFile(bytes.NewReader(b))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = f.RowGroup(0).Column("age")
+	assert.EqualError(t, err, "column chunk 0 has no metadata")
+}
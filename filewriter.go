@@ -0,0 +1,347 @@
+package parquet
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	sch "github.com/parsyl/parquet/schema"
+)
+
+// FileWriter writes a parquet file column by column.  The
+// schema is declared up front as a list of Fields, then, for
+// each row group, every column is written (in the order of the
+// Fields) as one or more batches of values along with their
+// definition and repetition levels.
+type FileWriter struct {
+	w           io.Writer
+	meta        *Metadata
+	fields      []Field
+	columns     []*column
+	compression sch.CompressionCodec
+	checksums   bool
+	encodings   map[string]sch.Encoding
+	keyValues   [][2]string
+	createdBy   *string
+
+	// rows is the number of rows in the current row group (0
+	// if no row group has been started), col is the index of the
+	// column currently being written and records counts the records
+	// written to each column of the current row group.
+	rows    int64
+	col     int
+	records []int64
+	started bool
+}
+
+// NewFileWriter creates a FileWriter for the schema defined by
+// fields.  The Types of each Field must have one RepetitionType
+// per element of its Path.
+func NewFileWriter(w io.Writer, fields []Field, opts ...func(*FileWriter) error) (*FileWriter, error) {
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("a FileWriter needs at least one field")
+	}
+
+	fw := &FileWriter{
+		w:           w,
+		fields:      make([]Field, len(fields)),
+		records:     make([]int64, len(fields)),
+		compression: sch.CompressionCodec_SNAPPY,
+	}
+
+	for i, f := range fields {
+		if len(f.Path) == 0 || len(f.Types) != len(f.Path) || f.Type == nil || f.RepetitionType == nil {
+			return nil, fmt.Errorf("invalid field %v, it must have a Path, Type, RepetitionType and one of Types per element of Path", f.Path)
+		}
+		if f.Name == "" {
+			f.Name = strings.Join(f.Path, ".")
+		}
+		if err := CheckDepth(f.Name, f.Types); err != nil {
+			return nil, err
+		}
+		fw.fields[i] = f
+	}
+
+	for _, opt := range opts {
+		if err := opt(fw); err != nil {
+			return nil, err
+		}
+	}
+
+	fw.columns = make([]*column, len(fw.fields))
+	for i, f := range fw.fields {
+		fw.columns[i] = newColumn(f, fw.compression)
+	}
+
+	fw.meta = New(fw.fields...)
+	if fw.checksums {
+		fw.meta.EnableChecksums()
+	}
+	for col, enc := range fw.encodings {
+		if err := fw.meta.SetEncoding(col, enc); err != nil {
+			return nil, err
+		}
+	}
+	for _, kv := range fw.keyValues {
+		fw.meta.SetKeyValue(kv[0], kv[1])
+	}
+	if fw.createdBy != nil {
+		fw.meta.SetCreatedBy(*fw.createdBy)
+	}
+
+	_, err := w.Write(par1)
+	return fw, err
+}
+
+// FileWriterUncompressed turns off the compression of the pages.
+func FileWriterUncompressed(w *FileWriter) error {
+	w.compression = sch.CompressionCodec_UNCOMPRESSED
+	return nil
+}
+
+// FileWriterSnappy compresses the pages with snappy (the default).
+func FileWriterSnappy(w *FileWriter) error {
+	w.compression = sch.CompressionCodec_SNAPPY
+	return nil
+}
+
+// FileWriterGzip compresses the pages with gzip.
+func FileWriterGzip(w *FileWriter) error {
+	w.compression = sch.CompressionCodec_GZIP
+	return nil
+}
+
+// FileWriterChecksums adds a CRC32 of each page's data to its
+// page header.
+func FileWriterChecksums(w *FileWriter) error {
+	w.checksums = true
+	return nil
+}
+
+// FileWriterEncoding sets the encoding of the values of column
+// (the names of its fields separated by dots).  NewFileWriter
+// returns an error if the column doesn't exist or its type can't
+// be encoded with enc.
+func FileWriterEncoding(column string, enc sch.Encoding) func(*FileWriter) error {
+	return func(w *FileWriter) error {
+		if w.encodings == nil {
+			w.encodings = map[string]sch.Encoding{}
+		}
+		w.encodings[column] = enc
+		return nil
+	}
+}
+
+// FileWriterKeyValue adds a key/value pair to the metadata in the
+// file's footer.
+func FileWriterKeyValue(key, value string) func(*FileWriter) error {
+	return func(w *FileWriter) error {
+		w.keyValues = append(w.keyValues, [2]string{key, value})
+		return nil
+	}
+}
+
+// FileWriterCreatedBy sets the application that wrote the file
+// (the footer's created_by).
+func FileWriterCreatedBy(createdBy string) func(*FileWriter) error {
+	return func(w *FileWriter) error {
+		w.createdBy = &createdBy
+		return nil
+	}
+}
+
+// StartRowGroup starts a row group of the given number of rows.
+// Every column of the previous row group (if any) must have
+// been written.
+func (w *FileWriter) StartRowGroup(rows int64) error {
+	if rows <= 0 {
+		return fmt.Errorf("a row group must have at least one row")
+	}
+
+	if err := w.endRowGroup(); err != nil {
+		return err
+	}
+
+	if w.started {
+		w.meta.StartRowGroup(w.fields...)
+	}
+
+	w.started = true
+	w.rows = rows
+	w.col = 0
+	w.meta.NextDocs(rows)
+	return nil
+}
+
+func (w *FileWriter) endRowGroup() error {
+	if w.rows == 0 {
+		return nil
+	}
+
+	for i, f := range w.fields {
+		if w.records[i] != w.rows {
+			return &RecordCountError{Column: f.Name, Records: w.records[i], Expected: w.rows}
+		}
+	}
+
+	for i := range w.records {
+		w.records[i] = 0
+	}
+
+	w.rows = 0
+	return nil
+}
+
+// WriteColumn writes a page of values to the column at pth (the
+// names of the fields separated by dots) of the current row
+// group.  vals must be a []int32, []uint32, []int64, []uint64,
+// []float32, []float64, []bool or []string that matches the type
+// of the column.  defs (nil for required columns) has a
+// definition level for each value and each missing value, and reps
+// (nil for columns that aren't repeated) has a repetition level for
+// each definition level.  A column may be written with multiple
+// calls to WriteColumn, but all of a row group's columns must be
+// written in the order of the FileWriter's fields.
+func (w *FileWriter) WriteColumn(pth string, vals interface{}, defs, reps []uint8) error {
+	if w.rows == 0 {
+		return fmt.Errorf("StartRowGroup must be called before WriteColumn")
+	}
+
+	i := w.col
+	for i < len(w.fields) && w.fields[i].Name != pth {
+		i++
+	}
+
+	if i == len(w.fields) {
+		return fmt.Errorf("no such column %s (columns must be written in the order of the fields)", pth)
+	}
+
+	for j := w.col; j < i; j++ {
+		if w.records[j] != w.rows {
+			return &RecordCountError{Column: w.fields[j].Name, Records: w.records[j], Expected: w.rows}
+		}
+	}
+	w.col = i
+
+	c := w.columns[i]
+	v := reflect.ValueOf(vals)
+	if err := c.check(v, defs, reps); err != nil {
+		return err
+	}
+
+	if w.records[i] == 0 && len(reps) > 0 && reps[0] != 0 {
+		return fmt.Errorf("column %s: the first repetition level of a row group must be 0", pth)
+	}
+
+	var n int64
+	switch {
+	case c.maxRep > 0:
+		for _, rep := range reps {
+			if rep == 0 {
+				n++
+			}
+		}
+	case c.maxDef > 0:
+		n = int64(len(defs))
+	default:
+		n = int64(v.Len())
+	}
+
+	if w.records[i]+n > w.rows {
+		return &RecordCountError{Column: pth, Records: w.records[i] + n, Expected: w.rows}
+	}
+
+	defer c.reset()
+	for j, def := range defs {
+		var rep uint8
+		if reps != nil {
+			rep = reps[j]
+		}
+		c.levels(def, rep)
+	}
+
+	for j := 0; j < v.Len(); j++ {
+		if err := c.add(v.Index(j)); err != nil {
+			return err
+		}
+	}
+
+	w.records[i] += n
+	return c.write(w.w, w.meta)
+}
+
+// check makes sure the type of vals matches the column and the
+// levels are valid.
+func (c *column) check(v reflect.Value, defs, reps []uint8) error {
+	if v.Kind() != reflect.Slice {
+		return fmt.Errorf("column %s: values must be a slice, got %s", c.field.Name, v.Kind())
+	}
+
+	var z int32
+	se := sch.SchemaElement{TypeLength: &z, Scale: &z, Precision: &z}
+	if f, ok := typeFuncs[v.Type().Elem().Kind()]; ok {
+		f(&se)
+	}
+
+	if se.Type == nil || *se.Type != *c.se.Type || (se.ConvertedType == nil) != (c.se.ConvertedType == nil) ||
+		(se.ConvertedType != nil && *se.ConvertedType != *c.se.ConvertedType) {
+		return &ErrSchemaMismatch{Column: c.field.Name, Reason: fmt.Sprintf("can't write %s to a column of type %s", v.Type(), c.se.Type)}
+	}
+
+	if c.maxDef == 0 {
+		if defs != nil || reps != nil {
+			return fmt.Errorf("column %s is required, it has no definition or repetition levels", c.field.Name)
+		}
+		return nil
+	}
+
+	var n int
+	for _, def := range defs {
+		if def > c.maxDef {
+			return fmt.Errorf("column %s: definition level %d is greater than the max of %d", c.field.Name, def, c.maxDef)
+		}
+		if def == c.maxDef {
+			n++
+		}
+	}
+
+	if n != v.Len() {
+		return fmt.Errorf("column %s: got %d values but the definition levels define %d", c.field.Name, v.Len(), n)
+	}
+
+	if c.maxRep == 0 {
+		if reps != nil {
+			return fmt.Errorf("column %s isn't repeated, it has no repetition levels", c.field.Name)
+		}
+		return nil
+	}
+
+	if len(reps) != len(defs) {
+		return fmt.Errorf("column %s: got %d repetition levels and %d definition levels", c.field.Name, len(reps), len(defs))
+	}
+
+	for _, rep := range reps {
+		if rep > c.maxRep {
+			return fmt.Errorf("column %s: repetition level %d is greater than the max of %d", c.field.Name, rep, c.maxRep)
+		}
+	}
+
+	return nil
+}
+
+// Close checks that every column of the current row group has
+// been written and then writes the parquet metadata at the end
+// of the file.
+func (w *FileWriter) Close() error {
+	if err := w.endRowGroup(); err != nil {
+		return err
+	}
+
+	if err := w.meta.Footer(w.w); err != nil {
+		return err
+	}
+
+	_, err := w.w.Write(par1)
+	return err
+}
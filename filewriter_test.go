@@ -0,0 +1,121 @@
+package parquet_test
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/parsyl/parquet"
+	"github.com/stretchr/testify/assert"
+)
+
+type friend struct {
+	ID   int32   `parquet:"id"`
+	Nick *string `parquet:"nick"`
+}
+
+type member struct {
+	ID      int32    `parquet:"id"`
+	Age     *uint32  `parquet:"age"`
+	Friends []friend `parquet:"friends"`
+}
+
+func TestFileWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := parquet.NewFileWriter(&buf, []parquet.Field{
+		{Path: []string{"id"}, Types: []int{0}, Type: parquet.Int32Type, RepetitionType: parquet.RepetitionRequired},
+		{Path: []string{"age"}, Types: []int{1}, Type: parquet.Uint32Type, RepetitionType: parquet.RepetitionOptional},
+		{Path: []string{"friends", "id"}, Types: []int{2, 0}, Type: parquet.Int32Type, RepetitionType: parquet.RepetitionRequired},
+		{Path: []string{"friends", "nick"}, Types: []int{2, 1}, Type: parquet.StringType, RepetitionType: parquet.RepetitionOptional},
+	}, parquet.FileWriterUncompressed)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.NoError(t, w.StartRowGroup(3))
+	assert.NoError(t, w.WriteColumn("id", []int32{1, 2}, nil, nil))
+	assert.NoError(t, w.WriteColumn("id", []int32{3}, nil, nil))
+	assert.NoError(t, w.WriteColumn("age", []uint32{10, 30}, []uint8{1, 0, 1}, nil))
+	assert.NoError(t, w.WriteColumn("friends.id", []int32{2, 3, 1}, []uint8{1, 1, 0, 1}, []uint8{0, 1, 0, 0}))
+	assert.NoError(t, w.WriteColumn("friends.nick", []string{"tres"}, []uint8{1, 2, 0, 1}, []uint8{0, 1, 0, 0}))
+
+	assert.NoError(t, w.StartRowGroup(1))
+	assert.EqualError(t, w.WriteColumn("age", []uint32{1}, []uint8{1}, nil), "column id has 0 records, expected 1")
+	assert.EqualError(t, w.WriteColumn("id", []int64{4}, nil, nil), "column id: can't write []int64 to a column of type INT32")
+	assert.EqualError(t, w.WriteColumn("id", []int32{4, 5}, nil, nil), "column id has 2 records, expected 1")
+	assert.NoError(t, w.WriteColumn("id", []int32{4}, nil, nil))
+	assert.EqualError(t, w.WriteColumn("age", []uint32{1}, nil, nil), "column age: got 1 values but the definition levels define 0")
+	assert.NoError(t, w.WriteColumn("age", []uint32{}, []uint8{0}, nil))
+	assert.NoError(t, w.WriteColumn("friends.id", []int32{}, []uint8{0}, []uint8{0}))
+	assert.EqualError(t, w.Close(), "column friends.nick has 0 records, expected 1")
+	assert.NoError(t, w.WriteColumn("friends.nick", []string{}, []uint8{0}, []uint8{0}))
+	if !assert.NoError(t, w.Close()) {
+		return
+	}
+
+	r, err := parquet.NewReader(bytes.NewReader(buf.Bytes()), reflect.TypeOf(member{}))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var out []member
+	for r.Next() {
+		var m member
+		assert.NoError(t, r.Scan(&m))
+		out = append(out, m)
+	}
+	assert.NoError(t, r.Error())
+
+	age := func(a uint32) *uint32 { return &a }
+	assert.Equal(t, []member{
+		{ID: 1, Age: age(10), Friends: []friend{{ID: 2}, {ID: 3, Nick: pstring("tres")}}},
+		{ID: 2},
+		{ID: 3, Age: age(30), Friends: []friend{{ID: 1}}},
+		{ID: 4},
+	}, out)
+}
+
+func TestFileWriterDepth(t *testing.T) {
+	field := func(depth int) parquet.Field {
+		f := parquet.Field{Type: parquet.Int32Type, RepetitionType: parquet.RepetitionOptional}
+		for i := 0; i < depth; i++ {
+			f.Path = append(f.Path, fmt.Sprintf("l%d", i))
+			f.Types = append(f.Types, 1)
+		}
+		return f
+	}
+
+	_, err := parquet.NewFileWriter(&bytes.Buffer{}, []parquet.Field{field(parquet.MaxDepth + 1)})
+	assert.Error(t, err)
+
+	// 20 optional levels need 5 bits for each definition level
+	f := field(20)
+	name := strings.Join(f.Path, ".")
+	var buf bytes.Buffer
+	w, err := parquet.NewFileWriter(&buf, []parquet.Field{f})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.NoError(t, w.StartRowGroup(3))
+	assert.NoError(t, w.WriteColumn(name, []int32{7}, []uint8{20, 5, 0}, nil))
+	if !assert.NoError(t, w.Close()) {
+		return
+	}
+
+	pf, err := parquet.OpenFile(bytes.NewReader(buf.Bytes()))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	c, err := pf.RowGroup(0).Column(name)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, uint8(20), c.MaxDef())
+	assert.Equal(t, []uint8{20, 5, 0}, c.DefinitionLevels())
+	assert.Equal(t, []int32{7}, c.Values())
+}
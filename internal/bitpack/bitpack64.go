@@ -0,0 +1,92 @@
+package bitpack
+
+// PackUint64 appends vals to b, each one packed into width (at
+// most 64) bits, starting with the least significant bit of the
+// first byte.  Unlike Pack, any number of values can be packed;
+// the bits of the last byte that aren't used are zero.
+func PackUint64(b []byte, width int, vals []uint64) []byte {
+	if width == 0 {
+		return b
+	}
+
+	var buf uint64
+	var n int
+	for _, v := range vals {
+		if width < 64 {
+			v &= 1<<uint(width) - 1
+		}
+
+		// buf holds n (< 8) bits, so at most 7 + 64 bits are
+		// pending: write out whole bytes as they fill up.
+		rem := width
+		for rem > 0 {
+			take := 64 - n
+			if take > rem {
+				take = rem
+			}
+
+			buf |= (v & mask(take)) << uint(n)
+			v = shift(v, take)
+			n += take
+			rem -= take
+			for n >= 8 {
+				b = append(b, byte(buf))
+				buf >>= 8
+				n -= 8
+			}
+		}
+	}
+
+	if n > 0 {
+		b = append(b, byte(buf))
+	}
+	return b
+}
+
+// UnpackUint64 unpacks count values of width (at most 64) bits
+// that were packed by PackUint64.  It returns false if b is too
+// short to hold count values.
+func UnpackUint64(width int, b []byte, count int) ([]uint64, bool) {
+	out := make([]uint64, count)
+	if width == 0 {
+		return out, true
+	}
+
+	if len(b)*8 < width*count {
+		return nil, false
+	}
+
+	var bit int
+	for i := range out {
+		var v uint64
+		var got int
+		for got < width {
+			byt := bit / 8
+			off := bit % 8
+			take := 8 - off
+			if take > width-got {
+				take = width - got
+			}
+
+			v |= (uint64(b[byt]>>uint(off)) & mask(take)) << uint(got)
+			got += take
+			bit += take
+		}
+		out[i] = v
+	}
+	return out, true
+}
+
+func mask(n int) uint64 {
+	if n >= 64 {
+		return ^uint64(0)
+	}
+	return 1<<uint(n) - 1
+}
+
+func shift(v uint64, n int) uint64 {
+	if n >= 64 {
+		return 0
+	}
+	return v >> uint(n)
+}
@@ -64,3 +64,34 @@ func getBytes(vals ...string) []byte {
 	}
 	return out
 }
+
+func TestPackUint64(t *testing.T) {
+	for width := 0; width <= 64; width++ {
+		t.Run(fmt.Sprintf("width %d", width), func(t *testing.T) {
+			vals := make([]uint64, 13)
+			for i := range vals {
+				if width > 0 {
+					vals[i] = (uint64(i)*0x9E3779B97F4A7C15 + 1) >> uint(64-width)
+				}
+			}
+
+			b := bitpack.PackUint64(nil, width, vals)
+			assert.Equal(t, (width*len(vals)+7)/8, len(b))
+
+			out, ok := bitpack.UnpackUint64(width, b, len(vals))
+			assert.True(t, ok)
+			assert.Equal(t, vals, out)
+		})
+	}
+
+	// the same layout as Pack
+	vals := []uint8{0, 1, 2, 3, 4, 5, 6, 7}
+	u := make([]uint64, len(vals))
+	for i, v := range vals {
+		u[i] = uint64(v)
+	}
+	assert.Equal(t, bitpack.Pack(nil, 3, vals), bitpack.PackUint64(nil, 3, u))
+
+	_, ok := bitpack.UnpackUint64(3, []byte{1, 2}, 8)
+	assert.False(t, ok)
+}
@@ -0,0 +1,54 @@
+// Package bytestreamsplit implements the BYTE_STREAM_SPLIT parquet
+// encoding, which splits fixed width values into one stream per
+// byte: the first bytes of every value, followed by the second
+// bytes, and so on.  The values' bytes aren't changed, but grouping
+// them together helps compression (the exponents of floats, for
+// example, don't vary much).
+package bytestreamsplit
+
+import "fmt"
+
+// Encode splits plain, a sequence of little endian values of
+// width bytes, into width streams.
+func Encode(plain []byte, width int) ([]byte, error) {
+	if err := check(plain, width); err != nil {
+		return nil, err
+	}
+
+	n := len(plain) / width
+	out := make([]byte, len(plain))
+	for i := 0; i < n; i++ {
+		for k := 0; k < width; k++ {
+			out[k*n+i] = plain[i*width+k]
+		}
+	}
+	return out, nil
+}
+
+// Decode joins the width streams of data back into a sequence
+// of little endian values.
+func Decode(data []byte, width int) ([]byte, error) {
+	if err := check(data, width); err != nil {
+		return nil, err
+	}
+
+	n := len(data) / width
+	out := make([]byte, len(data))
+	for i := 0; i < n; i++ {
+		for k := 0; k < width; k++ {
+			out[i*width+k] = data[k*n+i]
+		}
+	}
+	return out, nil
+}
+
+func check(b []byte, width int) error {
+	if width <= 0 {
+		return fmt.Errorf("bytestreamsplit: invalid width %d", width)
+	}
+
+	if len(b)%width != 0 {
+		return fmt.Errorf("bytestreamsplit: %d bytes isn't a multiple of the width (%d)", len(b), width)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package bytestreamsplit_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"testing"
+
+	"github.com/parsyl/parquet/internal/bytestreamsplit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestByteStreamSplit(t *testing.T) {
+	b, err := bytestreamsplit.Encode([]byte{0x00, 0x01, 0x02, 0x03, 0x10, 0x11, 0x12, 0x13}, 4)
+	if assert.NoError(t, err) {
+		assert.Equal(t, []byte{0x00, 0x10, 0x01, 0x11, 0x02, 0x12, 0x03, 0x13}, b)
+	}
+
+	var buf bytes.Buffer
+	assert.NoError(t, binary.Write(&buf, binary.LittleEndian, []float64{1.5, -2.25, 1e300, 0, 3}))
+
+	testCases := []struct {
+		width int
+		plain []byte
+	}{
+		{width: 4, plain: []byte{}},
+		{width: 4, plain: []byte{1, 2, 3, 4}},
+		{width: 8, plain: buf.Bytes()},
+		{width: 3, plain: []byte{1, 2, 3, 4, 5, 6}},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%02d", i), func(t *testing.T) {
+			b, err := bytestreamsplit.Encode(tc.plain, tc.width)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			out, err := bytestreamsplit.Decode(b, tc.width)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.plain, out)
+			}
+		})
+	}
+
+	_, err = bytestreamsplit.Decode([]byte{1, 2, 3}, 4)
+	assert.Error(t, err)
+}
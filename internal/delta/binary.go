@@ -0,0 +1,233 @@
+// Package delta implements the DELTA_BINARY_PACKED,
+// DELTA_LENGTH_BYTE_ARRAY and DELTA_BYTE_ARRAY parquet
+// encodings.
+package delta
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	mbits "math/bits"
+
+	"github.com/parsyl/parquet/internal/bitpack"
+)
+
+const (
+	blockSize      = 128
+	miniBlocks     = 4
+	miniBlockSize  = blockSize / miniBlocks
+	maxHeaderBytes = 4 * binary.MaxVarintLen64
+
+	// maxBlockSize is the largest block size that is decoded,
+	// anything bigger is assumed to be corrupt.
+	maxBlockSize = 1 << 20
+)
+
+// ErrShortBuffer is returned when encoded data ends before
+// all of its values have been decoded.
+var ErrShortBuffer = errors.New("delta: unexpected end of data")
+
+// EncodeInt32 encodes vals with DELTA_BINARY_PACKED.
+func EncodeInt32(vals []int32) []byte {
+	u := make([]uint64, len(vals))
+	for i, v := range vals {
+		u[i] = uint64(int64(v))
+	}
+	return encode(u, 32)
+}
+
+// EncodeInt64 encodes vals with DELTA_BINARY_PACKED.
+func EncodeInt64(vals []int64) []byte {
+	u := make([]uint64, len(vals))
+	for i, v := range vals {
+		u[i] = uint64(v)
+	}
+	return encode(u, 64)
+}
+
+// DecodeInt32 decodes DELTA_BINARY_PACKED data and returns the
+// values along with the number of bytes that were read.
+func DecodeInt32(b []byte) ([]int32, int, error) {
+	u, n, err := decode(b, 32)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	out := make([]int32, len(u))
+	for i, v := range u {
+		out[i] = int32(v)
+	}
+	return out, n, nil
+}
+
+// DecodeInt64 decodes DELTA_BINARY_PACKED data and returns the
+// values along with the number of bytes that were read.
+func DecodeInt64(b []byte) ([]int64, int, error) {
+	u, n, err := decode(b, 64)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	out := make([]int64, len(u))
+	for i, v := range u {
+		out[i] = int64(v)
+	}
+	return out, n, nil
+}
+
+// encode encodes vals, which are signed integers of the given
+// number of bits (32 or 64).  The deltas are computed with the
+// same wrap around arithmetic as the values' type so that
+// every delta fits in bits bits.
+func encode(vals []uint64, bits uint) []byte {
+	m := mask(bits)
+	out := make([]byte, 0, maxHeaderBytes)
+	out = appendUvarint(out, blockSize)
+	out = appendUvarint(out, miniBlocks)
+	out = appendUvarint(out, uint64(len(vals)))
+	if len(vals) == 0 {
+		return appendVarint(out, 0)
+	}
+
+	out = appendVarint(out, signed(vals[0], bits))
+
+	deltas := make([]uint64, 0, blockSize)
+	for i := 1; i < len(vals); i += blockSize {
+		deltas = deltas[:0]
+		for j := i; j < i+blockSize && j < len(vals); j++ {
+			deltas = append(deltas, (vals[j]-vals[j-1])&m)
+		}
+
+		min := signed(deltas[0], bits)
+		for _, d := range deltas[1:] {
+			if s := signed(d, bits); s < min {
+				min = s
+			}
+		}
+
+		// the deltas are stored relative to the smallest delta,
+		// which makes them all positive
+		var widths [miniBlocks]byte
+		for k := range deltas {
+			deltas[k] = (deltas[k] - uint64(min)) & m
+			w := byte(mbits.Len64(deltas[k]))
+			if w > widths[k/miniBlockSize] {
+				widths[k/miniBlockSize] = w
+			}
+		}
+
+		out = appendVarint(out, min)
+		out = append(out, widths[:]...)
+		for k := 0; k < len(deltas); k += miniBlockSize {
+			// the last miniblock is padded with zeros
+			mb := make([]uint64, miniBlockSize)
+			copy(mb, deltas[k:])
+			out = bitpack.PackUint64(out, int(widths[k/miniBlockSize]), mb)
+		}
+	}
+	return out
+}
+
+func decode(b []byte, bits uint) ([]uint64, int, error) {
+	var pos int
+	header := make([]uint64, 3)
+	for i := range header {
+		v, n := binary.Uvarint(b[pos:])
+		if n <= 0 {
+			return nil, 0, ErrShortBuffer
+		}
+		header[i] = v
+		pos += n
+	}
+
+	size, blocks, total := header[0], header[1], header[2]
+	if size == 0 || size > maxBlockSize || size%128 != 0 || blocks == 0 || size%blocks != 0 || (size/blocks)%32 != 0 {
+		return nil, 0, fmt.Errorf("delta: invalid block size %d with %d miniblocks", size, blocks)
+	}
+
+	first, n := binary.Varint(b[pos:])
+	if n <= 0 {
+		return nil, 0, ErrShortBuffer
+	}
+	pos += n
+
+	// each block holds up to size values and takes at
+	// least 1 + blocks bytes
+	if total > 1 && (total-2)/size+1 > uint64(len(b)-pos)/(1+blocks) {
+		return nil, 0, ErrShortBuffer
+	}
+
+	m := mask(bits)
+	out := make([]uint64, 0, total)
+	if total == 0 {
+		return out, pos, nil
+	}
+
+	out = append(out, uint64(first)&m)
+	per := int(size / blocks)
+	for uint64(len(out)) < total {
+		min, n := binary.Varint(b[pos:])
+		if n <= 0 {
+			return nil, 0, ErrShortBuffer
+		}
+		pos += n
+
+		if pos+int(blocks) > len(b) {
+			return nil, 0, ErrShortBuffer
+		}
+		widths := b[pos : pos+int(blocks)]
+		pos += int(blocks)
+
+		for _, w := range widths {
+			if uint64(len(out)) >= total {
+				break
+			}
+
+			if uint(w) > bits {
+				return nil, 0, fmt.Errorf("delta: invalid bit width %d", w)
+			}
+
+			l := per * int(w) / 8
+			if pos+l > len(b) {
+				return nil, 0, ErrShortBuffer
+			}
+
+			deltas, _ := bitpack.UnpackUint64(int(w), b[pos:pos+l], per)
+			pos += l
+			for _, d := range deltas {
+				if uint64(len(out)) >= total {
+					break
+				}
+				out = append(out, (out[len(out)-1]+uint64(min)+d)&m)
+			}
+		}
+	}
+
+	return out, pos, nil
+}
+
+// signed sign extends v, an integer of the given number of
+// bits.
+func signed(v uint64, bits uint) int64 {
+	if bits == 32 {
+		return int64(int32(v))
+	}
+	return int64(v)
+}
+
+func mask(bits uint) uint64 {
+	if bits == 64 {
+		return ^uint64(0)
+	}
+	return 1<<bits - 1
+}
+
+func appendUvarint(b []byte, v uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(b, buf[:binary.PutUvarint(buf[:], v)]...)
+}
+
+func appendVarint(b []byte, v int64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return append(b, buf[:binary.PutVarint(buf[:], v)]...)
+}
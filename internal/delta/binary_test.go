@@ -0,0 +1,75 @@
+package delta_test
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/parsyl/parquet/internal/delta"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBinaryPacked(t *testing.T) {
+	// the example from the parquet documentation
+	b := delta.EncodeInt32([]int32{1, 2, 3, 4, 5})
+	assert.Equal(t, []byte{0x80, 0x01, 0x04, 0x05, 0x02, 0x02, 0, 0, 0, 0}, b)
+
+	rnd := rand.New(rand.NewSource(1))
+	random32 := make([]int32, 1000)
+	random64 := make([]int64, 1000)
+	for i := range random32 {
+		random32[i] = rnd.Int31() - math.MaxInt32/2
+		random64[i] = rnd.Int63() - math.MaxInt64/2
+	}
+
+	increasing := make([]int64, 300)
+	for i := range increasing {
+		increasing[i] = 1600000000000 + int64(i)*1000 + int64(rnd.Intn(10))
+	}
+
+	testCases32 := [][]int32{
+		{},
+		{7},
+		{1, 2, 3, 4, 5},
+		{7, 5, 3, 1, 2, 3, 4, 5},
+		{math.MinInt32, math.MaxInt32, math.MinInt32, 0, math.MaxInt32},
+		random32,
+	}
+
+	for i, tc := range testCases32 {
+		t.Run(fmt.Sprintf("int32 %d", i), func(t *testing.T) {
+			b := delta.EncodeInt32(tc)
+			out, n, err := delta.DecodeInt32(append(b, 0xff))
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc, out)
+				assert.Equal(t, len(b), n)
+			}
+		})
+	}
+
+	testCases64 := [][]int64{
+		{},
+		{-7},
+		{math.MinInt64, math.MaxInt64, math.MinInt64, 0, math.MaxInt64},
+		random64,
+		increasing,
+	}
+
+	for i, tc := range testCases64 {
+		t.Run(fmt.Sprintf("int64 %d", i), func(t *testing.T) {
+			b := delta.EncodeInt64(tc)
+			out, n, err := delta.DecodeInt64(b)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc, out)
+				assert.Equal(t, len(b), n)
+			}
+		})
+	}
+
+	b = delta.EncodeInt64(increasing)
+	assert.True(t, len(b) < len(increasing)*2, len(b))
+
+	_, _, err := delta.DecodeInt64(b[:len(b)-1])
+	assert.Equal(t, delta.ErrShortBuffer, err)
+}
@@ -0,0 +1,102 @@
+package delta
+
+import "fmt"
+
+// EncodeLengthByteArray encodes vals with DELTA_LENGTH_BYTE_ARRAY:
+// the lengths of the values, DELTA_BINARY_PACKED, followed by the
+// values themselves.
+func EncodeLengthByteArray(vals [][]byte) []byte {
+	lengths := make([]int32, len(vals))
+	var size int
+	for i, v := range vals {
+		lengths[i] = int32(len(v))
+		size += len(v)
+	}
+
+	out := EncodeInt32(lengths)
+	buf := make([]byte, len(out), len(out)+size)
+	copy(buf, out)
+	for _, v := range vals {
+		buf = append(buf, v...)
+	}
+	return buf
+}
+
+// DecodeLengthByteArray decodes DELTA_LENGTH_BYTE_ARRAY data and
+// returns the values along with the number of bytes that were read.
+// The values are slices of b.
+func DecodeLengthByteArray(b []byte) ([][]byte, int, error) {
+	lengths, pos, err := DecodeInt32(b)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	out := make([][]byte, len(lengths))
+	for i, l := range lengths {
+		if l < 0 {
+			return nil, 0, fmt.Errorf("delta: invalid length %d", l)
+		}
+
+		if int(l) > len(b)-pos {
+			return nil, 0, ErrShortBuffer
+		}
+
+		out[i] = b[pos : pos+int(l) : pos+int(l)]
+		pos += int(l)
+	}
+	return out, pos, nil
+}
+
+// EncodeByteArray encodes vals with DELTA_BYTE_ARRAY: the length of
+// the prefix that each value shares with the previous one,
+// DELTA_BINARY_PACKED, followed by the rest of each value,
+// DELTA_LENGTH_BYTE_ARRAY.
+func EncodeByteArray(vals [][]byte) []byte {
+	prefixes := make([]int32, len(vals))
+	suffixes := make([][]byte, len(vals))
+	var prev []byte
+	for i, v := range vals {
+		var p int
+		for p < len(v) && p < len(prev) && v[p] == prev[p] {
+			p++
+		}
+		prefixes[i] = int32(p)
+		suffixes[i] = v[p:]
+		prev = v
+	}
+
+	return append(EncodeInt32(prefixes), EncodeLengthByteArray(suffixes)...)
+}
+
+// DecodeByteArray decodes DELTA_BYTE_ARRAY data and returns the
+// values along with the number of bytes that were read.
+func DecodeByteArray(b []byte) ([][]byte, int, error) {
+	prefixes, pos, err := DecodeInt32(b)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	suffixes, n, err := DecodeLengthByteArray(b[pos:])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if len(suffixes) != len(prefixes) {
+		return nil, 0, fmt.Errorf("delta: %d prefixes but %d suffixes", len(prefixes), len(suffixes))
+	}
+
+	out := make([][]byte, len(prefixes))
+	var prev []byte
+	for i, p := range prefixes {
+		if p < 0 || int(p) > len(prev) {
+			return nil, 0, fmt.Errorf("delta: invalid prefix length %d", p)
+		}
+
+		v := make([]byte, int(p)+len(suffixes[i]))
+		copy(v, prev[:p])
+		copy(v[p:], suffixes[i])
+		out[i] = v
+		prev = v
+	}
+	return out, pos + n, nil
+}
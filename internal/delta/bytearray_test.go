@@ -0,0 +1,76 @@
+package delta_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/parsyl/parquet/internal/delta"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestByteArray(t *testing.T) {
+	// the example from the parquet documentation
+	words := bytes("axis", "axle", "babble", "babyhood")
+	b := delta.EncodeByteArray(words)
+	expected := append(delta.EncodeInt32([]int32{0, 2, 0, 3}), delta.EncodeLengthByteArray(bytes("axis", "le", "babble", "yhood"))...)
+	assert.Equal(t, expected, b)
+
+	urls := make([][]byte, 500)
+	for i := range urls {
+		urls[i] = []byte(fmt.Sprintf("https://example.com/api/v1/things/%06d", i))
+	}
+
+	testCases := [][][]byte{
+		{},
+		bytes(""),
+		bytes("", "", ""),
+		bytes("a"),
+		words,
+		bytes("abc", "abc", "ab", "abcd", "", "x"),
+		urls,
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("length %d", i), func(t *testing.T) {
+			b := delta.EncodeLengthByteArray(tc)
+			out, n, err := delta.DecodeLengthByteArray(append(b, 0xff))
+			if assert.NoError(t, err) {
+				assert.Equal(t, len(tc), len(out))
+				for j := range tc {
+					assert.Equal(t, string(tc[j]), string(out[j]))
+				}
+				assert.Equal(t, len(b), n)
+			}
+		})
+
+		t.Run(fmt.Sprintf("prefix %d", i), func(t *testing.T) {
+			b := delta.EncodeByteArray(tc)
+			out, n, err := delta.DecodeByteArray(append(b, 0xff))
+			if assert.NoError(t, err) {
+				assert.Equal(t, len(tc), len(out))
+				for j := range tc {
+					assert.Equal(t, string(tc[j]), string(out[j]))
+				}
+				assert.Equal(t, len(b), n)
+			}
+		})
+	}
+
+	l := delta.EncodeLengthByteArray(urls)
+	p := delta.EncodeByteArray(urls)
+	assert.True(t, len(p) < len(l)/4, "%d %d", len(p), len(l))
+
+	_, _, err := delta.DecodeLengthByteArray(l[:len(l)-1])
+	assert.Equal(t, delta.ErrShortBuffer, err)
+
+	_, _, err = delta.DecodeByteArray(p[:len(p)-1])
+	assert.Equal(t, delta.ErrShortBuffer, err)
+}
+
+func bytes(vals ...string) [][]byte {
+	out := make([][]byte, len(vals))
+	for i, v := range vals {
+		out[i] = []byte(v)
+	}
+	return out
+}
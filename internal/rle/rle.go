@@ -12,6 +12,10 @@ import (
 const (
 	mask1 = uint64(0x7F)
 	mask2 = uint64(0x80)
+
+	// MaxWidth is the largest bit width that can be
+	// encoded/decoded.
+	MaxWidth = 32
 )
 
 // RLE holds metadata that is used while reading
@@ -20,9 +24,8 @@ type RLE struct {
 	// TODO: make out a buffer?
 	out           *writeBuffer
 	bitWidth      int32
-	packBuf       []byte
-	prev          uint8
-	valBuf        []uint8
+	prev          uint64
+	valBuf        []uint64
 	bufCount      int
 	repeatCount   int
 	groupCount    int
@@ -32,20 +35,30 @@ type RLE struct {
 // New creates an RLE struct based on the maximum bitwidth (width) of
 // the data that is to be encoded/decoded.
 func New(width int32, size int) (*RLE, error) {
-	if width > 4 {
-		return nil, fmt.Errorf("bitwidth %d is greater than 4 (highest supported)", width)
+	if width < 0 || width > MaxWidth {
+		return nil, fmt.Errorf("bitwidth %d is greater than %d (highest supported)", width, MaxWidth)
 	}
 	return &RLE{
 		out:           newWriteBuffer(size),
 		bitWidth:      width,
-		packBuf:       make([]byte, int(width)),
-		valBuf:        make([]uint8, 8),
+		valBuf:        make([]uint64, 8),
 		headerPointer: -1,
 	}, nil
 }
 
 // Write encodes 'value' to run length encoded data.
 func (r *RLE) Write(value uint8) {
+	r.WriteUint64(uint64(value))
+}
+
+// WriteUint32 encodes 'value' to run length encoded data.
+func (r *RLE) WriteUint32(value uint32) {
+	r.WriteUint64(uint64(value))
+}
+
+// WriteUint64 encodes 'value' to run length encoded data.  Only
+// the lowest bitwidth bits of value are written.
+func (r *RLE) WriteUint64(value uint64) {
 	if value == r.prev {
 		r.repeatCount++
 		if r.repeatCount >= 8 {
@@ -76,8 +89,16 @@ func (r *RLE) writeOrAppendBitPackedRun() {
 		r.headerPointer = r.out.size() - 1
 	}
 
-	tmp := make([]byte, 0, bitpack.MaxSize)
-	tmp = bitpack.Pack(tmp, int(r.bitWidth), r.valBuf)
+	tmp := make([]byte, 0, r.bitWidth)
+	if r.bitWidth <= bitpack.MaxSize {
+		var vals [8]uint8
+		for i, v := range r.valBuf {
+			vals[i] = uint8(v)
+		}
+		tmp = bitpack.Pack(tmp, int(r.bitWidth), vals[:])
+	} else {
+		tmp = bitpack.PackUint64(tmp, int(r.bitWidth), r.valBuf)
+	}
 	r.out.write(tmp)
 	r.bufCount = 0
 	r.repeatCount = 0
@@ -95,36 +116,22 @@ func (r *RLE) endPreviousBitPackedRun() {
 	r.groupCount = 0
 }
 
-func (r *RLE) writeRLERun() error {
+func (r *RLE) writeRLERun() {
 	r.endPreviousBitPackedRun()
 	r.out.write(r.leb128(r.repeatCount << 1))
-	x, err := r.writeIntLittleEndianPaddedOnBitWidth(r.prev, r.bitWidth)
-	if err != nil {
-		return err
-	}
-	r.out.write(x)
+	r.out.write(writeIntLittleEndianPaddedOnBitWidth(r.prev, r.bitWidth))
 	r.repeatCount = 0
 	r.bufCount = 0
-	return nil
 }
 
-func (r *RLE) writeIntLittleEndianPaddedOnBitWidth(v uint8, bitWidth int32) ([]byte, error) {
-	bytesWidth := (bitWidth + 7) / 8
-	switch bytesWidth {
-	case 0:
-		return nil, nil
-	case 1:
-		return []byte{
-			byte(uint(v>>0) & 0xFF),
-		}, nil
-	case 2:
-		return []byte{
-			byte(uint(v>>0) & 0xFF),
-			byte(uint(v>>8) & 0xFF),
-		}, nil
-	default:
-		return nil, fmt.Errorf("Encountered value (%d) that requires more than 2 bytes", v)
+// writeIntLittleEndianPaddedOnBitWidth returns the lowest
+// (bitWidth+7)/8 bytes of v, least significant byte first.
+func writeIntLittleEndianPaddedOnBitWidth(v uint64, bitWidth int32) []byte {
+	out := make([]byte, (bitWidth+7)/8)
+	for i := range out {
+		out[i] = byte(v >> (8 * uint(i)))
 	}
+	return out
 }
 
 func (r *RLE) leb128(value int) []byte {
@@ -136,8 +143,31 @@ func (r *RLE) leb128(value int) []byte {
 	return append(out, byte(value&0x7F))
 }
 
-// Bytes the raw run length encoded data.
+// Bytes returns the run length encoded data, prefixed with its
+// 4 byte little endian length (the layout used for levels in
+// DATA_PAGE pages and for RLE encoded booleans).
 func (r *RLE) Bytes() []byte {
+	runs := r.Runs()
+	var b bytes.Buffer
+	binary.Write(&b, binary.LittleEndian, int32(len(runs)))
+	return append(b.Bytes(), runs...)
+}
+
+// Runs returns the run length encoded data without a length
+// prefix (the layout used for levels in DATA_PAGE_V2 pages).
+func (r *RLE) Runs() []byte {
+	r.flush()
+	return r.out.bytes()
+}
+
+// Indices returns the run length encoded data prefixed with a
+// single byte that holds the bit width (the layout used for
+// dictionary indices).
+func (r *RLE) Indices() []byte {
+	return append([]byte{byte(r.bitWidth)}, r.Runs()...)
+}
+
+func (r *RLE) flush() {
 	if r.repeatCount >= 8 {
 		r.writeRLERun()
 	} else if r.bufCount > 0 {
@@ -149,126 +179,169 @@ func (r *RLE) Bytes() []byte {
 	} else {
 		r.endPreviousBitPackedRun()
 	}
-
-	var b bytes.Buffer
-	binary.Write(&b, binary.LittleEndian, int32(r.out.size()))
-	return append(b.Bytes(), r.out.bytes()...)
 }
 
-// Read reads the RLE encoded definition levels
+// Read reads the RLE encoded definition levels.  It returns an
+// error if the bitwidth is greater than 8 (see ReadUint64).
 func (r *RLE) Read(in io.Reader) ([]uint8, int, error) {
-	var out []uint8
+	if r.bitWidth > 8 {
+		return nil, 0, fmt.Errorf("bitwidth %d is too wide for uint8 values", r.bitWidth)
+	}
+
+	vals, n, err := r.ReadUint64(in)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	out := make([]uint8, len(vals))
+	for i, v := range vals {
+		out[i] = uint8(v)
+	}
+	return out, n, nil
+}
+
+// ReadUint32 reads RLE encoded values of up to 32 bits.
+func (r *RLE) ReadUint32(in io.Reader) ([]uint32, int, error) {
+	vals, n, err := r.ReadUint64(in)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	out := make([]uint32, len(vals))
+	for i, v := range vals {
+		out[i] = uint32(v)
+	}
+	return out, n, nil
+}
+
+// ReadUint64 reads RLE encoded values.  Like Read, it returns
+// the values along with the number of bytes that were read
+// (including the 4 byte length of the encoded data).  The last
+// bit packed run is padded, so there might be more values than
+// were written.
+func (r *RLE) ReadUint64(in io.Reader) ([]uint64, int, error) {
 	var length int32
 	if err := binary.Read(in, binary.LittleEndian, &length); err != nil {
-		return out, 0, err
+		return nil, 0, err
+	}
+
+	if length < 0 {
+		return nil, 0, fmt.Errorf("invalid length %d", length)
 	}
 
 	buf := make([]byte, length)
-	if _, err := in.Read(buf); err != nil {
+	if _, err := io.ReadFull(in, buf); err != nil {
 		return nil, 0, err
 	}
 
-	rr := bytes.NewReader(buf)
-	var header uint64
-	var vals []uint8
-	var err error
+	out, err := readRuns(buf, int(r.bitWidth))
+	if err != nil {
+		return nil, 0, err
+	}
+	return out, int(length) + 4, nil
+}
+
+// ReadRuns reads run length encoded data that has no length
+// prefix (see Runs).  Every byte of b is expected to belong to
+// the encoded data.
+func (r *RLE) ReadRuns(b []byte) ([]uint64, error) {
+	return readRuns(b, int(r.bitWidth))
+}
+
+// ReadIndices reads dictionary indices that were encoded with
+// Indices.  The bit width is read from the first byte of b.
+func ReadIndices(b []byte) ([]uint64, error) {
+	if len(b) == 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	width := int(b[0])
+	if width > MaxWidth {
+		return nil, fmt.Errorf("bitwidth %d is greater than %d (highest supported)", width, MaxWidth)
+	}
+	return readRuns(b[1:], width)
+}
+
+func readRuns(b []byte, width int) ([]uint64, error) {
+	var out []uint64
+	rr := bytes.NewReader(b)
 	for rr.Len() > 0 {
-		header, err = readLEB128(rr)
+		header, err := readLEB128(rr)
 		if err != nil {
-			return nil, 0, err
+			return nil, err
 		}
+
+		var vals []uint64
 		if header&1 == 0 {
-			vals, err = readRLE(rr, header, uint64(r.bitWidth))
-			if err != nil {
-				return nil, 0, err
-			}
-			out = append(out, vals...)
+			vals, err = readRLE(rr, header, width)
 		} else {
-			vals, err = readRLEBitPacked(rr, header, uint8(r.bitWidth))
-			if err != nil {
-				return nil, 0, err
-			}
-			out = append(out, vals...)
+			vals, err = readRLEBitPacked(rr, header, width)
 		}
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, vals...)
 	}
-	return out, int(length) + 4, nil
+	return out, nil
 }
 
-func readRLEBitPacked(r io.Reader, header uint64, width uint8) ([]uint8, error) {
-	count := (int(header) >> 1) * 8
+func readRLEBitPacked(r *bytes.Reader, header uint64, width int) ([]uint64, error) {
+	count := (header >> 1) * 8
 	if width == 0 {
-		return make([]uint8, count), nil
+		return make([]uint64, count), nil
+	}
+
+	byteCount := uint64(width) * count / 8
+	if byteCount > uint64(r.Len()) {
+		return nil, io.ErrUnexpectedEOF
 	}
 
-	byteCount := (int(width) * count) / 8
 	rawBytes := make([]byte, byteCount)
-	if _, err := r.Read(rawBytes); err != nil {
+	if _, err := io.ReadFull(r, rawBytes); err != nil {
 		return nil, err
 	}
 
-	var out []uint8
+	if width > bitpack.MaxSize {
+		out, _ := bitpack.UnpackUint64(width, rawBytes, int(count))
+		return out, nil
+	}
+
+	out := make([]uint64, 0, count)
 	for len(rawBytes) > 0 {
-		out = append(out, bitpack.Unpack(int(width), rawBytes[:width])...)
-		rawBytes = rawBytes[int(width):]
+		for _, v := range bitpack.Unpack(width, rawBytes[:width]) {
+			out = append(out, uint64(v))
+		}
+		rawBytes = rawBytes[width:]
 	}
 
 	return out, nil
 }
 
-func readRLE(r io.Reader, header uint64, bitWidth uint64) ([]uint8, error) {
+func readRLE(r io.Reader, header uint64, bitWidth int) ([]uint64, error) {
 	count := header >> 1
-	value, err := readIntLittleEndianPaddedOnBitWidth(r, int(bitWidth))
+	value, err := readIntLittleEndianPaddedOnBitWidth(r, bitWidth)
 	if err != nil {
 		return nil, err
 	}
 
-	out := make([]uint8, count)
-	for i := 0; i < int(count); i++ {
-		out[i] = uint8(value)
+	out := make([]uint64, count)
+	for i := range out {
+		out[i] = value
 	}
 	return out, nil
 }
 
-func readIntLittleEndianPaddedOnBitWidth(in io.Reader, bitWidth int) (uint8, error) {
-	bytesWidth := (bitWidth + 7) / 8
-	switch bytesWidth {
-	case 0:
-		return 0, nil
-	case 1:
-		return readIntLittleEndianOnOneByte(in)
-	case 2:
-		return readIntLittleEndianOnTwoBytes(in)
-	default:
-		return 0, fmt.Errorf("Encountered bitWidth (%d) that requires more than 4 bytes", bitWidth)
-	}
-}
-
-func readIntLittleEndianOnOneByte(in io.Reader) (uint8, error) {
-	b := make([]byte, 1)
-	_, err := in.Read(b)
-	if err != nil {
-		return 0, err
-	}
-	if b[0] < 0 {
-		return 0, io.EOF
-	}
-	return uint8(b[0]), nil
-}
-
-func readIntLittleEndianOnTwoBytes(in io.Reader) (uint8, error) {
-	b := make([]byte, 2)
-	_, err := in.Read(b)
-	if err != nil {
+func readIntLittleEndianPaddedOnBitWidth(in io.Reader, bitWidth int) (uint64, error) {
+	b := make([]byte, (bitWidth+7)/8)
+	if _, err := io.ReadFull(in, b); err != nil {
 		return 0, err
 	}
-	if b[0] < 0 {
-		return 0, io.EOF
-	}
 
-	if (b[0] | b[1]) < 0 {
-		return 0, io.EOF
+	var out uint64
+	for i, x := range b {
+		out |= uint64(x) << (8 * uint(i))
 	}
-	return (uint8(b[1]) << 8) + (uint8(b[0]) << 0), nil
+	return out, nil
 }
 
 func readLEB128(r io.Reader) (uint64, error) {
@@ -276,7 +349,7 @@ func readLEB128(r io.Reader) (uint64, error) {
 	var out, shift, x uint64
 	b := make([]byte, 1)
 	for {
-		_, err = r.Read(b)
+		_, err = io.ReadFull(r, b)
 		if err != nil {
 			return out, err
 		}
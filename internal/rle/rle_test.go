@@ -72,7 +72,17 @@ func TestRLE(t *testing.T) {
 		{
 			name:  "width 4",
 			width: 4,
-			err:   fmt.Errorf("bitwidth 4 is greater than 3 (highest supported)"),
+			in:    mod(16, 100),
+		},
+		{
+			name:  "width 8",
+			width: 8,
+			in:    append(mod(256, 300), repeat(255, 20)...),
+		},
+		{
+			name:  "width 33",
+			width: 33,
+			err:   fmt.Errorf("bitwidth 33 is greater than 32 (highest supported)"),
 		},
 	}
 
@@ -80,7 +90,7 @@ func TestRLE(t *testing.T) {
 		t.Run(fmt.Sprintf("%02d-%s", i, tc.name), func(t *testing.T) {
 			r, err := rle.New(tc.width, len(tc.in))
 			if tc.err != nil {
-				assert.Error(t, tc.err, err)
+				assert.EqualError(t, err, tc.err.Error())
 				return
 			}
 
@@ -100,6 +110,55 @@ func TestRLE(t *testing.T) {
 	}
 }
 
+func TestRLEUint64(t *testing.T) {
+	testCases := []struct {
+		width int32
+		in    []uint64
+	}{
+		{width: 9, in: []uint64{1, 511, 256, 3}},
+		{width: 12, in: append(repeatUint64(4095, 20), 1, 2, 3)},
+		{width: 17, in: []uint64{1 << 16, 7, 7, 7, 7, 7, 7, 7, 7, 7, 0}},
+		{width: 32, in: append([]uint64{1<<32 - 1, 0, 1 << 31}, repeatUint64(1<<32-1, 100)...)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("width %d", tc.width), func(t *testing.T) {
+			r, err := rle.New(tc.width, 0)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			for _, x := range tc.in {
+				r.WriteUint64(x)
+			}
+			b := r.Bytes()
+			vals, n, err := r.ReadUint64(bytes.NewReader(b))
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.in, vals[:len(tc.in)])
+				assert.Equal(t, len(b), n)
+			}
+
+			vals32, _, err := r.ReadUint32(bytes.NewReader(b))
+			if assert.NoError(t, err) {
+				for i, x := range tc.in {
+					assert.Equal(t, uint32(x), vals32[i])
+				}
+			}
+
+			_, _, err = r.Read(bytes.NewReader(b))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func repeatUint64(v uint64, c int) []uint64 {
+	out := make([]uint64, c)
+	for i := range out {
+		out[i] = v
+	}
+	return out
+}
+
 func mod(m, c int) []uint8 {
 	out := make([]uint8, c)
 	for i := range out {
@@ -123,3 +182,55 @@ func repeat(v uint8, c int) []uint8 {
 	}
 	return out
 }
+
+func TestRLEWithoutLength(t *testing.T) {
+	testCases := []struct {
+		width int32
+		in    []uint64
+	}{
+		{width: 0, in: repeatUint64(0, 10)},
+		{width: 1, in: []uint64{1, 0, 1, 1, 0}},
+		{width: 3, in: append(repeatUint64(4, 100), 1, 2, 7)},
+		{width: 12, in: append(repeatUint64(4095, 20), 1, 2, 3)},
+		{width: 32, in: append([]uint64{1<<32 - 1, 0, 1 << 31}, repeatUint64(1<<32-1, 100)...)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("width %d", tc.width), func(t *testing.T) {
+			r, err := rle.New(tc.width, 0)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			for _, x := range tc.in {
+				r.WriteUint64(x)
+			}
+
+			runs := r.Runs()
+			prefixed := r.Bytes()
+			assert.Equal(t, prefixed[4:], runs)
+
+			vals, err := r.ReadRuns(runs)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.in, vals[:len(tc.in)])
+			}
+
+			indices := r.Indices()
+			assert.Equal(t, byte(tc.width), indices[0])
+			assert.Equal(t, runs, indices[1:])
+
+			vals, err = rle.ReadIndices(indices)
+			if assert.NoError(t, err) {
+				assert.Equal(t, tc.in, vals[:len(tc.in)])
+			}
+		})
+	}
+}
+
+func TestReadIndicesErrors(t *testing.T) {
+	_, err := rle.ReadIndices(nil)
+	assert.EqualError(t, err, "unexpected EOF")
+
+	_, err = rle.ReadIndices([]byte{33, 2, 1})
+	assert.EqualError(t, err, "bitwidth 33 is greater than 32 (highest supported)")
+}
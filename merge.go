@@ -0,0 +1,386 @@
+package parquet
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/apache/thrift/lib/go/thrift"
+	sch "github.com/parsyl/parquet/schema"
+)
+
+// Merger holds the options of Merge.
+type Merger struct {
+	minRows int64
+}
+
+// MergeCoalesce makes Merge combine consecutive row groups that
+// have fewer than rows rows into row groups of (at least) rows
+// rows.  Coalesced row groups are decoded and written again (with
+// snappy compression and PLAIN encoding), so only the columns that
+// a FileWriter can write are supported.  A coalesced row group keeps
+// the sorting columns of its row groups if they agree and it is
+// still sorted by them.
+func MergeCoalesce(rows int64) func(*Merger) error {
+	return func(m *Merger) error {
+		if rows < 1 {
+			return fmt.Errorf("invalid number of rows to coalesce: %d", rows)
+		}
+		m.minRows = rows
+		return nil
+	}
+}
+
+// Merge writes a parquet file to w that has all of the row groups
+// of files, in order.  The files must have the same schema,
+// otherwise an *ErrSchemaMismatch is returned.  The column chunks
+// are copied without being decoded (only their offsets change)
+// unless MergeCoalesce is used to combine small row groups.  The
+// schema, key/value metadata and created_by of the first file are
+// kept, and so are its column orders if all of the files agree on
+// them.
+func Merge(w io.Writer, files []io.ReadSeeker, opts ...func(*Merger) error) error {
+	var m Merger
+	for _, opt := range opts {
+		if err := opt(&m); err != nil {
+			return err
+		}
+	}
+
+	if len(files) == 0 {
+		return fmt.Errorf("no files to merge")
+	}
+
+	inputs := make([]*File, len(files))
+	for i, r := range files {
+		f, err := OpenFile(r)
+		if err != nil {
+			return fmt.Errorf("file %d: %w", i, err)
+		}
+
+		if i > 0 {
+			if err := checkSchema(inputs[0].Fields(), f.meta); err != nil {
+				return fmt.Errorf("file %d: %w", i, err)
+			}
+		}
+		inputs[i] = f
+	}
+
+	mw := &mergeWriter{w: w}
+	if err := mw.write(par1); err != nil {
+		return err
+	}
+
+	var pending []*RowGroupReader
+	var pendingRows int64
+	flush := func() error {
+		var err error
+		switch len(pending) {
+		case 0:
+		case 1:
+			err = mw.copy(pending[0])
+		default:
+			err = mw.coalesce(inputs[0].Fields(), pending, pendingRows)
+		}
+		pending, pendingRows = nil, 0
+		return err
+	}
+
+	for _, f := range inputs {
+		for i := 0; i < f.NumRowGroups(); i++ {
+			rg := f.RowGroup(i)
+			if rg.Rows() == 0 {
+				continue
+			}
+
+			if rg.Rows() >= m.minRows {
+				if err := flush(); err != nil {
+					return err
+				}
+				if err := mw.copy(rg); err != nil {
+					return err
+				}
+				continue
+			}
+
+			pending = append(pending, rg)
+			pendingRows += rg.Rows()
+			if pendingRows >= m.minRows {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return err
+	}
+
+	first := inputs[0].meta
+	return mw.footer(&sch.FileMetaData{
+		Version:          1,
+		Schema:           first.Schema,
+		NumRows:          mw.rows,
+		RowGroups:        mw.rowGroups,
+		KeyValueMetadata: first.KeyValueMetadata,
+		CreatedBy:        first.CreatedBy,
+		ColumnOrders:     columnOrders(inputs),
+	})
+}
+
+// columnOrders returns the column orders of the first of inputs,
+// or nil if any of the others has different ones.
+func columnOrders(inputs []*File) []*sch.ColumnOrder {
+	orders := inputs[0].meta.ColumnOrders
+	for _, f := range inputs[1:] {
+		if !reflect.DeepEqual(orders, f.meta.ColumnOrders) {
+			return nil
+		}
+	}
+	return orders
+}
+
+// mergeWriter keeps track of the position in the merged file
+// and the row groups that have been written to it.
+type mergeWriter struct {
+	w         io.Writer
+	pos       int64
+	rows      int64
+	rowGroups []*sch.RowGroup
+}
+
+func (mw *mergeWriter) write(b []byte) error {
+	n, err := mw.w.Write(b)
+	mw.pos += int64(n)
+	return err
+}
+
+// copy copies the column chunks of rg as they are and adds rg
+// to the merged file's row groups with offsets that point to the
+// copies.
+func (mw *mergeWriter) copy(rg *RowGroupReader) error {
+	out := &sch.RowGroup{
+		NumRows:        rg.rowGroup.NumRows,
+		TotalByteSize:  rg.rowGroup.TotalByteSize,
+		SortingColumns: rg.rowGroup.SortingColumns,
+		Columns:        make([]*sch.ColumnChunk, len(rg.rowGroup.Columns)),
+	}
+
+	for i, ch := range rg.rowGroup.Columns {
+		if ch.MetaData == nil {
+			return fmt.Errorf("column chunk %d has no metadata", i)
+		}
+
+		if ch.FilePath != nil {
+			return fmt.Errorf("column %v is in another file (%s)", ch.MetaData.PathInSchema, *ch.FilePath)
+		}
+
+		md := *ch.MetaData
+		start := chunkPage(ch).Offset
+		if _, err := rg.file.r.Seek(start, io.SeekStart); err != nil {
+			return err
+		}
+
+		delta := mw.pos - start
+		n, err := io.CopyN(mw.w, rg.file.r, md.TotalCompressedSize)
+		mw.pos += n
+		if err != nil {
+			return fmt.Errorf("unable to copy column %v: %w", md.PathInSchema, err)
+		}
+
+		md.DataPageOffset += delta
+		md.DictionaryPageOffset = shiftOffset(md.DictionaryPageOffset, delta)
+		md.IndexPageOffset = shiftOffset(md.IndexPageOffset, delta)
+		md.BloomFilterOffset = nil
+		// the file offset is where the copied chunk starts: the
+		// source's could be anywhere (or unset) and the column
+		// metadata isn't copied along with the pages
+		out.Columns[i] = &sch.ColumnChunk{
+			FileOffset: start + delta,
+			MetaData:   &md,
+		}
+	}
+
+	mw.rows += out.NumRows
+	mw.rowGroups = append(mw.rowGroups, out)
+	return nil
+}
+
+// coalesce writes the columns of rgs as a single row group of
+// rows rows.  The row group is written to a buffer with a
+// FileWriter and then copied.  It keeps the sorting columns of
+// rgs if the coalesced row group is still sorted by them.
+func (mw *mergeWriter) coalesce(fields []Field, rgs []*RowGroupReader, rows int64) error {
+	var buf bytes.Buffer
+	fw, err := NewFileWriter(&buf, fields)
+	if err != nil {
+		return err
+	}
+
+	if err := fw.StartRowGroup(rows); err != nil {
+		return err
+	}
+
+	cols := make([][]*ColumnReader, len(fields))
+	for i, f := range fields {
+		for _, rg := range rgs {
+			c, err := rg.Column(f.Name)
+			if err != nil {
+				return err
+			}
+
+			vals := unsignedValues(c.Values(), c.se.ConvertedType)
+			if err := fw.WriteColumn(f.Name, vals, c.DefinitionLevels(), c.RepetitionLevels()); err != nil {
+				return err
+			}
+			cols[i] = append(cols[i], c)
+		}
+	}
+
+	if err := fw.Close(); err != nil {
+		return err
+	}
+
+	f, err := OpenFile(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		return err
+	}
+
+	if err := mw.copy(f.RowGroup(0)); err != nil {
+		return err
+	}
+	mw.rowGroups[len(mw.rowGroups)-1].SortingColumns = sortingColumns(rgs, cols)
+	return nil
+}
+
+// sortingColumns returns the sorting columns of rgs if they all
+// have the same ones and each row group's first row doesn't sort
+// before the previous row group's last row.  cols holds the
+// column readers of each field for each of rgs.
+func sortingColumns(rgs []*RowGroupReader, cols [][]*ColumnReader) []*sch.SortingColumn {
+	sc := rgs[0].rowGroup.SortingColumns
+	for _, rg := range rgs[1:] {
+		if !reflect.DeepEqual(sc, rg.rowGroup.SortingColumns) {
+			return nil
+		}
+	}
+
+	for _, s := range sc {
+		if int(s.ColumnIdx) >= len(cols) || cols[s.ColumnIdx][0].MaxRep() > 0 {
+			return nil
+		}
+	}
+
+	for i := 1; i < len(rgs); i++ {
+		for _, s := range sc {
+			prev, next := cols[s.ColumnIdx][i-1], cols[s.ColumnIdx][i]
+			a, okA := rowValue(prev, prev.Len()-1)
+			b, okB := rowValue(next, 0)
+
+			var c int
+			switch {
+			case !okA && !okB:
+			case !okA || !okB:
+				c = -1
+				if okA == s.NullsFirst {
+					c = 1
+				}
+			default:
+				c = compareSorted(a, b)
+				if s.Descending {
+					c = -c
+				}
+			}
+
+			if c < 0 {
+				break
+			}
+			if c > 0 {
+				return nil
+			}
+		}
+	}
+	return sc
+}
+
+// rowValue returns the value of the i'th row of c, which must
+// not be repeated, or false if the row's value is missing.
+func rowValue(c *ColumnReader, i int) (reflect.Value, bool) {
+	vi := i
+	if defs := c.DefinitionLevels(); defs != nil {
+		if defs[i] < c.MaxDef() {
+			return reflect.Value{}, false
+		}
+
+		vi = 0
+		for _, def := range defs[:i] {
+			if def == c.MaxDef() {
+				vi++
+			}
+		}
+	}
+	return reflect.ValueOf(unsignedValues(c.Values(), c.se.ConvertedType)).Index(vi), true
+}
+
+// footer writes the merged file's FileMetaData, its length and
+// the closing magic bytes.
+func (mw *mergeWriter) footer(fmd *sch.FileMetaData) error {
+	ts := thrift.NewTSerializer()
+	ts.Protocol = thrift.NewTCompactProtocolFactory().GetProtocol(ts.Transport)
+	buf, err := ts.Write(context.Background(), fmd)
+	if err != nil {
+		return err
+	}
+
+	if err := mw.write(buf); err != nil {
+		return err
+	}
+
+	if err := binary.Write(mw.w, binary.LittleEndian, uint32(len(buf))); err != nil {
+		return err
+	}
+	return mw.write(par1)
+}
+
+// shiftOffset moves o by delta.  Offsets that aren't set (some
+// writers write 0 instead of leaving them out) are kept as they are.
+func shiftOffset(o *int64, delta int64) *int64 {
+	if o == nil || *o <= 0 {
+		return o
+	}
+	v := *o + delta
+	return &v
+}
+
+// unsignedValues converts the values of UINT_32 and UINT_64
+// columns (which are read as int32s and int64s) back to the
+// types they are written with.
+func unsignedValues(vals interface{}, ct *sch.ConvertedType) interface{} {
+	if ct == nil {
+		return vals
+	}
+
+	switch v := vals.(type) {
+	case []int32:
+		if *ct == sch.ConvertedType_UINT_32 {
+			out := make([]uint32, len(v))
+			for i, x := range v {
+				out[i] = uint32(x)
+			}
+			return out
+		}
+	case []int64:
+		if *ct == sch.ConvertedType_UINT_64 {
+			out := make([]uint64, len(v))
+			for i, x := range v {
+				out[i] = uint64(x)
+			}
+			return out
+		}
+	}
+	return vals
+}
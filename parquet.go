@@ -7,9 +7,12 @@ import (
 	"context"
 	"encoding/binary"
 	"fmt"
+	"hash/crc32"
 	"io"
 	"io/ioutil"
+	"math"
 	"strings"
+	"sync"
 
 	"github.com/apache/thrift/lib/go/thrift"
 	sch "github.com/parsyl/parquet/schema"
@@ -22,6 +25,21 @@ type Field struct {
 	Types          []int
 	Type           FieldFunc
 	RepetitionType FieldFunc
+
+	// IDs holds the field ID of each element of Path (0 if the
+	// element doesn't have one).  It is nil if the column doesn't
+	// have any field IDs.
+	IDs []int32
+}
+
+// id returns the field ID of the i'th element of f's path, or
+// nil if it doesn't have one.
+func (f Field) id(i int) *int32 {
+	if i >= len(f.IDs) || f.IDs[i] == 0 {
+		return nil
+	}
+	id := f.IDs[i]
+	return &id
 }
 
 // Page keeps track of metadata for each ColumnChunk
@@ -31,6 +49,10 @@ type Page struct {
 	Size   int
 	Offset int64
 	Codec  sch.CompressionCodec
+
+	// Type is the physical type of the column, which is needed
+	// to decode pages that aren't PLAIN encoded.
+	Type sch.Type
 }
 
 type schema struct {
@@ -40,35 +62,33 @@ type schema struct {
 
 func (s schema) schema() (int64, []*sch.SchemaElement) {
 	out := make([]*sch.SchemaElement, 0, len(s.fields)+1)
-	out = append(out, &sch.SchemaElement{
-		Name: "root",
-	})
+	root := &sch.SchemaElement{
+		Name:        "root",
+		NumChildren: new(int32),
+	}
+	out = append(out, root)
 
-	var children int32
 	var z int32
 	m := map[string]*sch.SchemaElement{}
 	for _, f := range s.fields {
-		if len(f.Path) > 1 {
-			for i, name := range f.Path[:len(f.Path)-1] {
-				par, ok := m[name]
-				if !ok {
-					children++
-					parts := strings.Split(name, ".")
-					rt := sch.FieldRepetitionType(f.Types[i])
-					par = &sch.SchemaElement{
-						Name:           parts[len(parts)-1],
-						RepetitionType: &rt,
-						NumChildren:    &z,
-					}
-					out = append(out, par)
+		par := root
+		for i, name := range f.Path[:len(f.Path)-1] {
+			key := strings.Join(f.Path[:i+1], ".")
+			se, ok := m[key]
+			if !ok {
+				parts := strings.Split(name, ".")
+				rt := sch.FieldRepetitionType(f.Types[i])
+				se = &sch.SchemaElement{
+					Name:           parts[len(parts)-1],
+					RepetitionType: &rt,
+					NumChildren:    new(int32),
+					FieldID:        f.id(i),
 				}
-				n := *par.NumChildren
-				n++
-				par.NumChildren = &n
-				m[name] = par
+				out = append(out, se)
+				m[key] = se
+				*par.NumChildren++
 			}
-		} else if len(f.Path) == 1 {
-			children++
+			par = se
 		}
 
 		se := &sch.SchemaElement{
@@ -76,15 +96,15 @@ func (s schema) schema() (int64, []*sch.SchemaElement) {
 			TypeLength: &z,
 			Scale:      &z,
 			Precision:  &z,
-			FieldID:    &z,
+			FieldID:    f.id(len(f.Path) - 1),
 		}
 
 		f.Type(se)
 		f.RepetitionType(se)
 		out = append(out, se)
+		*par.NumChildren++
 	}
 
-	out[0].NumChildren = &children
 	return int64(len(s.fields)), out
 }
 
@@ -92,6 +112,9 @@ func (s schema) schema() (int64, []*sch.SchemaElement) {
 // be kept track of in order to write the FileMetaData
 // at the end of the parquet file.
 type Metadata struct {
+	// mu guards ts and the row groups so that column chunks can
+	// be written concurrently.
+	mu           sync.Mutex
 	ts           *thrift.TSerializer
 	schema       schema
 	docs         int64
@@ -99,6 +122,32 @@ type Metadata struct {
 	rowGroupDocs int64
 	rowGroups    []RowGroup
 
+	// checksums is true if a CRC32 of each page is
+	// written to its page header.
+	checksums bool
+
+	// encodings holds the encoding of each column that
+	// isn't PLAIN encoded.
+	encodings map[string]sch.Encoding
+
+	// keyValues and createdBy are written to the footer.
+	keyValues []*sch.KeyValue
+	createdBy *string
+
+	// sortingColumns is written to each row group (see
+	// SetSortingColumns).
+	sortingColumns []*sch.SortingColumn
+
+	// offset is the position of the first row group that is
+	// written with m and existing holds the row groups that
+	// were already in the file (see AppendTo).
+	offset   int64
+	existing []*sch.RowGroup
+
+	// columnOrders are the column orders of the file that m
+	// continues (see AppendTo).
+	columnOrders []*sch.ColumnOrder
+
 	metadata *sch.FileMetaData
 }
 
@@ -118,12 +167,135 @@ func New(fields ...Field) *Metadata {
 	m := &Metadata{
 		ts:     ts,
 		schema: schemaElements(fields),
+		offset: 4,
 	}
 
 	m.StartRowGroup(fields...)
 	return m
 }
 
+// EnableChecksums makes the fields that are written with m
+// add a CRC32 of each page's (compressed) data to its page
+// header.
+func (m *Metadata) EnableChecksums() {
+	m.checksums = true
+}
+
+// SetEncoding sets the encoding of the values of column (the
+// names of the fields separated by dots).  It returns an error
+// if the column doesn't exist or its type can't be encoded with
+// enc.
+func (m *Metadata) SetEncoding(column string, enc sch.Encoding) error {
+	t, err := columnType(column, m.schema)
+	if err != nil {
+		return err
+	}
+
+	if err := checkEncoding(column, t, enc); err != nil {
+		return err
+	}
+
+	if m.encodings == nil {
+		m.encodings = map[string]sch.Encoding{}
+	}
+	m.encodings[column] = enc
+	return nil
+}
+
+// SetSortingColumns records that the rows of each row group that
+// is written with m are sorted by columns (in ascending order, with
+// missing values first).  The columns are written to each row
+// group's sorting_columns and the footer's column_orders is set.
+// It returns an error if a column doesn't exist or is repeated.
+func (m *Metadata) SetSortingColumns(columns ...string) error {
+	out := make([]*sch.SortingColumn, len(columns))
+	for i, col := range columns {
+		idx := -1
+		for j, f := range m.schema.fields {
+			if strings.Join(f.Path, ".") == col {
+				idx = j
+				break
+			}
+		}
+
+		if idx == -1 {
+			return &ErrSchemaMismatch{Column: col, Reason: "not in the schema"}
+		}
+
+		if getRepetitionTypes(m.schema.fields[idx].Types).MaxRep() > 0 {
+			return fmt.Errorf("can't sort by column %s, it is repeated", col)
+		}
+
+		out[i] = &sch.SortingColumn{ColumnIdx: int32(idx), NullsFirst: true}
+	}
+
+	m.sortingColumns = out
+	return nil
+}
+
+// SetKeyValue adds key and value to the key/value metadata that
+// is written to the footer.  If key has already been set its
+// value is replaced.
+func (m *Metadata) SetKeyValue(key, value string) {
+	for _, kv := range m.keyValues {
+		if kv.Key == key {
+			kv.Value = &value
+			return
+		}
+	}
+	m.keyValues = append(m.keyValues, &sch.KeyValue{Key: key, Value: &value})
+}
+
+// SetCreatedBy sets the application that wrote the file, which
+// is written to the footer's created_by.
+func (m *Metadata) SetCreatedBy(createdBy string) {
+	m.createdBy = &createdBy
+}
+
+// KeyValueMetadata returns the key/value metadata of the footer
+// that was read by ReadFooter (or ReadFooterAt).
+func (m *Metadata) KeyValueMetadata() map[string]string {
+	return keyValueMetadata(m.metadata)
+}
+
+// CreatedBy returns the created_by of the footer that was read
+// by ReadFooter (or ReadFooterAt).
+func (m *Metadata) CreatedBy() string {
+	if m.metadata == nil || m.metadata.CreatedBy == nil {
+		return ""
+	}
+	return *m.metadata.CreatedBy
+}
+
+func keyValueMetadata(footer *sch.FileMetaData) map[string]string {
+	out := map[string]string{}
+	if footer == nil {
+		return out
+	}
+
+	for _, kv := range footer.KeyValueMetadata {
+		var v string
+		if kv.Value != nil {
+			v = *kv.Value
+		}
+		out[kv.Key] = v
+	}
+	return out
+}
+
+// encoding returns the encoding and the type of the column
+// at pth.
+func (m *Metadata) encoding(pth []string) (sch.Encoding, sch.Type) {
+	col := strings.Join(pth, ".")
+	enc, ok := m.encodings[col]
+	if !ok {
+		return sch.Encoding_PLAIN, 0
+	}
+
+	t, _ := columnType(col, m.schema)
+	return enc, t
+}
+
 // StartRowGroup is called when starting a new row group
 func (m *Metadata) StartRowGroup(fields ...Field) {
 	m.rowGroupDocs = 0
@@ -142,6 +314,13 @@ func (m *Metadata) NextDoc() {
 	m.pageDocs++
 }
 
+// NextDocs is NextDoc for n documents.
+func (m *Metadata) NextDocs(n int64) {
+	m.docs += n
+	m.rowGroupDocs += n
+	m.pageDocs += n
+}
+
 // RowGroups returns a summary of each schema.RowGroup
 func (m *Metadata) RowGroups() []RowGroup {
 	rgs := make([]RowGroup, len(m.metadata.RowGroups))
@@ -156,13 +335,36 @@ func (m *Metadata) RowGroups() []RowGroup {
 
 // WritePageHeader is called in order to finish writing to a column chunk.
 func (m *Metadata) WritePageHeader(w io.Writer, pth []string, dataLen, compressedLen, defCount, count int, defLen, repLen int64, comp sch.CompressionCodec, stats Stats) error {
+	return m.writePageHeader(w, pth, dataLen, compressedLen, count, comp, sch.Encoding_PLAIN, stats, nil)
+}
+
+// writePage writes the header of a page of (compressed)
+// data, including its checksum if checksums are enabled,
+// followed by the data.
+func (m *Metadata) writePage(w io.Writer, pth []string, dataLen int, data []byte, count int, comp sch.CompressionCodec, enc sch.Encoding, stats Stats) error {
+	var crc *int32
+	if m.checksums {
+		c := int32(crc32.ChecksumIEEE(data))
+		crc = &c
+	}
+
+	if err := m.writePageHeader(w, pth, dataLen, len(data), count, comp, enc, stats, crc); err != nil {
+		return err
+	}
+
+	_, err := w.Write(data)
+	return err
+}
+
+func (m *Metadata) writePageHeader(w io.Writer, pth []string, dataLen, compressedLen, count int, comp sch.CompressionCodec, enc sch.Encoding, stats Stats, crc *int32) error {
 	ph := &sch.PageHeader{
 		Type:                 sch.PageType_DATA_PAGE,
 		UncompressedPageSize: int32(dataLen),
 		CompressedPageSize:   int32(compressedLen),
+		Crc:                  crc,
 		DataPageHeader: &sch.DataPageHeader{
 			NumValues:               int32(count),
-			Encoding:                sch.Encoding_PLAIN,
+			Encoding:                enc,
 			DefinitionLevelEncoding: sch.Encoding_RLE,
 			RepetitionLevelEncoding: sch.Encoding_RLE,
 			Statistics: &sch.Statistics{
@@ -174,14 +376,18 @@ func (m *Metadata) WritePageHeader(w io.Writer, pth []string, dataLen, compresse
 		},
 	}
 
+	m.mu.Lock()
 	m.pageDocs = 0
 
 	buf, err := m.ts.Write(context.TODO(), ph)
 	if err != nil {
+		m.mu.Unlock()
 		return err
 	}
 
-	if err := m.updateRowGroup(pth, dataLen, compressedLen, len(buf), count, comp); err != nil {
+	err = m.updateRowGroup(pth, dataLen, compressedLen, len(buf), count, comp, enc)
+	m.mu.Unlock()
+	if err != nil {
 		return err
 	}
 
@@ -189,7 +395,7 @@ func (m *Metadata) WritePageHeader(w io.Writer, pth []string, dataLen, compresse
 	return err
 }
 
-func (m *Metadata) updateRowGroup(pth []string, dataLen, compressedLen, headerLen, count int, comp sch.CompressionCodec) error {
+func (m *Metadata) updateRowGroup(pth []string, dataLen, compressedLen, headerLen, count int, comp sch.CompressionCodec, enc sch.Encoding) error {
 	i := len(m.rowGroups)
 	if i == 0 {
 		return fmt.Errorf("no row groups, you must call StartRowGroup at least once")
@@ -198,7 +404,7 @@ func (m *Metadata) updateRowGroup(pth []string, dataLen, compressedLen, headerLe
 	rg := m.rowGroups[i-1]
 
 	rg.rowGroup.NumRows = m.rowGroupDocs
-	err := rg.updateColumnChunk(pth, dataLen+headerLen, compressedLen+headerLen, count, m.schema, comp)
+	err := rg.updateColumnChunk(pth, dataLen+headerLen, compressedLen+headerLen, count, m.schema, comp, enc)
 	m.rowGroups[i-1] = rg
 	return err
 }
@@ -206,7 +412,7 @@ func (m *Metadata) updateRowGroup(pth []string, dataLen, compressedLen, headerLe
 func columnType(col string, fields schema) (sch.Type, error) {
 	f, ok := fields.lookup[col]
 	if !ok {
-		return 0, fmt.Errorf("could not find type for column %s", col)
+		return 0, &ErrSchemaMismatch{Column: col, Reason: "not in the schema"}
 	}
 	return *f.Type, nil
 }
@@ -219,15 +425,37 @@ func (m *Metadata) Rows() int64 {
 
 // Footer writes the FileMetaData at the end of the file.
 func (m *Metadata) Footer(w io.Writer) error {
+	return m.FooterContext(context.Background(), w)
+}
+
+// FooterContext is Footer with a context.  It returns ctx.Err()
+// if ctx is done before the FileMetaData is written.
+func (m *Metadata) FooterContext(ctx context.Context, w io.Writer) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	_, s := m.schema.schema()
 	fmd := &sch.FileMetaData{
-		Version:   1,
-		Schema:    s,
-		NumRows:   m.docs,
-		RowGroups: make([]*sch.RowGroup, 0, len(m.rowGroups)),
+		Version:          1,
+		Schema:           s,
+		NumRows:          m.docs,
+		RowGroups:        make([]*sch.RowGroup, 0, len(m.existing)+len(m.rowGroups)),
+		KeyValueMetadata: m.keyValues,
+		CreatedBy:        m.createdBy,
 	}
 
-	pos := int64(4)
+	if m.columnOrders != nil {
+		fmd.ColumnOrders = m.columnOrders
+	} else if len(m.sortingColumns) > 0 {
+		fmd.ColumnOrders = make([]*sch.ColumnOrder, len(m.schema.fields))
+		for i := range fmd.ColumnOrders {
+			fmd.ColumnOrders[i] = &sch.ColumnOrder{TYPE_ORDER: &sch.TypeDefinedOrder{}}
+		}
+	}
+
+	fmd.RowGroups = append(fmd.RowGroups, m.existing...)
+	pos := m.offset
 	for _, mrg := range m.rowGroups {
 		rg := mrg.rowGroup
 		if rg.NumRows == 0 {
@@ -247,10 +475,11 @@ func (m *Metadata) Footer(w io.Writer) error {
 			pos += ch.MetaData.TotalCompressedSize
 		}
 
+		rg.SortingColumns = m.sortingColumns
 		fmd.RowGroups = append(fmd.RowGroups, &rg)
 	}
 
-	buf, err := m.ts.Write(context.TODO(), fmd)
+	buf, err := m.ts.Write(ctx, fmd)
 	if err != nil {
 		return err
 	}
@@ -280,7 +509,7 @@ func (r *RowGroup) Columns() []*sch.ColumnChunk {
 	return r.rowGroup.Columns
 }
 
-func (r *RowGroup) updateColumnChunk(pth []string, dataLen, compressedLen, count int, fields schema, comp sch.CompressionCodec) error {
+func (r *RowGroup) updateColumnChunk(pth []string, dataLen, compressedLen, count int, fields schema, comp sch.CompressionCodec, enc sch.Encoding) error {
 	col := strings.Join(pth, ".")
 
 	ch, ok := r.columns[col]
@@ -300,6 +529,10 @@ func (r *RowGroup) updateColumnChunk(pth []string, dataLen, compressedLen, count
 		}
 	}
 
+	if !hasEncoding(ch.MetaData.Encodings, enc) {
+		ch.MetaData.Encodings = append(ch.MetaData.Encodings, enc)
+	}
+
 	ch.MetaData.NumValues += int64(count)
 	ch.MetaData.TotalUncompressedSize += int64(dataLen)
 	ch.MetaData.TotalCompressedSize += int64(compressedLen)
@@ -316,7 +549,7 @@ func schemaElements(fields []Field) schema {
 			TypeLength: &z,
 			Scale:      &z,
 			Precision:  &z,
-			FieldID:    &z,
+			FieldID:    f.id(len(f.Path) - 1),
 		}
 
 		f.Type(&se)
@@ -336,24 +569,36 @@ func (m *Metadata) Pages() (map[string][]Page, error) {
 	for _, rg := range m.metadata.RowGroups {
 		for _, ch := range rg.Columns {
 			pth := ch.MetaData.PathInSchema
-			_, ok := m.schema.lookup[strings.Join(pth, ".")]
-			if !ok {
-				return nil, fmt.Errorf("could not find schema for %v", pth)
+			k := strings.Join(pth, ".")
+			if _, ok := m.schema.lookup[k]; !ok {
+				return nil, &ErrSchemaMismatch{Column: k, Reason: "not in the schema"}
 			}
 
-			pg := Page{
-				N:      int(ch.MetaData.NumValues),
-				Offset: ch.FileOffset,
-				Size:   int(ch.MetaData.TotalCompressedSize),
-				Codec:  ch.MetaData.Codec,
-			}
-			k := strings.Join(pth, ".")
-			out[k] = append(out[k], pg)
+			out[k] = append(out[k], chunkPage(ch))
 		}
 	}
 	return out, nil
 }
 
+// chunkPage returns the Page that describes a ColumnChunk.  The
+// column chunk starts at its dictionary page, if it has one, and
+// otherwise at its first data page (FileOffset is the offset of
+// the ColumnMetaData, which some writers put after the pages).
+func chunkPage(ch *sch.ColumnChunk) Page {
+	offset := ch.MetaData.DataPageOffset
+	if o := ch.MetaData.DictionaryPageOffset; o != nil && *o > 0 && *o < offset {
+		offset = *o
+	}
+
+	return Page{
+		N:      int(ch.MetaData.NumValues),
+		Offset: offset,
+		Size:   int(ch.MetaData.TotalCompressedSize),
+		Codec:  ch.MetaData.Codec,
+		Type:   ch.MetaData.Type,
+	}
+}
+
 // ReadMetaData reads the FileMetaData from the end of a parquet file
 func ReadMetaData(r io.ReadSeeker) (*sch.FileMetaData, error) {
 	p := thrift.NewTCompactProtocol(&thrift.StreamTransport{Reader: r})
@@ -371,11 +616,113 @@ func ReadMetaData(r io.ReadSeeker) (*sch.FileMetaData, error) {
 	return m, m.Read(p)
 }
 
+// ReadMetaDataAt reads the FileMetaData from the end of a parquet
+// file of the given size without depending on a seek position.
+func ReadMetaDataAt(r io.ReaderAt, size int64) (*sch.FileMetaData, error) {
+	if size < 12 {
+		return nil, fmt.Errorf("a parquet file is at least 12 bytes, got %d", size)
+	}
+
+	var b [4]byte
+	if n, err := r.ReadAt(b[:], size-8); err != nil && !(err == io.EOF && n == len(b)) {
+		return nil, fmt.Errorf("unable to read metadata size: %w", err)
+	}
+
+	n := int64(binary.LittleEndian.Uint32(b[:]))
+	if n+8 > size {
+		return nil, fmt.Errorf("invalid metadata size %d for a file of %d bytes", n, size)
+	}
+
+	p := thrift.NewTCompactProtocol(&thrift.StreamTransport{Reader: io.NewSectionReader(r, size-8-n, n)})
+	m := sch.NewFileMetaData()
+	return m, m.Read(p)
+}
+
 // ReadFooter reads the parquet metadata
 func (m *Metadata) ReadFooter(r io.ReadSeeker) error {
 	meta, err := ReadMetaData(r)
 	m.metadata = meta
-	return err
+	if err != nil {
+		return err
+	}
+	return m.resolveFieldIDs()
+}
+
+// ReadFooterAt reads the parquet metadata of a file of the
+// given size.
+func (m *Metadata) ReadFooterAt(r io.ReaderAt, size int64) error {
+	meta, err := ReadMetaDataAt(r, size)
+	m.metadata = meta
+	if err != nil {
+		return err
+	}
+	return m.resolveFieldIDs()
+}
+
+// resolveFieldIDs matches the columns of the footer that was read
+// with the fields of m by their (leaf) field IDs, so columns that
+// have been renamed since the file was written are still read.
+// The PathInSchema of a matched column's chunks is changed to the
+// path of m's field.  Columns without field IDs are matched by
+// name.  If a matched column is renamed to the name of one of the
+// file's other, unmatched, columns an *ErrSchemaMismatch is
+// returned.
+func (m *Metadata) resolveFieldIDs() error {
+	ids := map[int32][]string{}
+	for _, f := range m.schema.fields {
+		if id := f.id(len(f.Path) - 1); id != nil {
+			ids[*id] = f.Path
+		}
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	columns, err := schemaColumns(m.metadata)
+	if err != nil {
+		return err
+	}
+
+	paths := map[string][]string{}
+	for _, c := range columns {
+		id := c.field.id(len(c.field.Path) - 1)
+		if id == nil {
+			continue
+		}
+
+		if pth, ok := ids[*id]; ok {
+			paths[c.field.Name] = pth
+		}
+	}
+
+	renamed := map[string]string{}
+	for name, pth := range paths {
+		renamed[strings.Join(pth, ".")] = name
+	}
+
+	for _, c := range columns {
+		if _, ok := paths[c.field.Name]; ok {
+			continue
+		}
+
+		if name, ok := renamed[c.field.Name]; ok {
+			return &ErrSchemaMismatch{Column: c.field.Name, Reason: fmt.Sprintf("the file's column %s has the field's ID, but the file's column %s has its name", name, c.field.Name)}
+		}
+	}
+
+	for _, rg := range m.metadata.RowGroups {
+		for _, ch := range rg.Columns {
+			if ch.MetaData == nil {
+				continue
+			}
+
+			if pth, ok := paths[strings.Join(ch.MetaData.PathInSchema, ".")]; ok {
+				ch.MetaData.PathInSchema = pth
+			}
+		}
+	}
+	return nil
 }
 
 // PageHeader reads the page header from a column page
@@ -405,13 +752,24 @@ func PageHeaders(footer *sch.FileMetaData, r io.ReadSeeker) ([]sch.PageHeader, e
 // PageHeadersAtOffset seeks to the given offset, then reads the PageHeader
 // without reading the data.
 func PageHeadersAtOffset(r io.ReadSeeker, o, n int64) ([]sch.PageHeader, error) {
-	var out []sch.PageHeader
-	var nRead int64
 	_, err := r.Seek(o, io.SeekStart)
 	if err != nil {
-		return nil, fmt.Errorf("unable to seek to offset %d, err: %s", o, err)
+		return nil, fmt.Errorf("unable to seek to offset %d, err: %w", o, err)
 	}
 
+	return pageHeaders(r, n)
+}
+
+// PageHeadersAt is PageHeadersAtOffset for an io.ReaderAt.
+func PageHeadersAt(r io.ReaderAt, o, n int64) ([]sch.PageHeader, error) {
+	return pageHeaders(io.NewSectionReader(r, o, math.MaxInt64-o), n)
+}
+
+// pageHeaders reads the page headers of a column chunk, starting
+// at the current position of r.
+func pageHeaders(r io.ReadSeeker, n int64) ([]sch.PageHeader, error) {
+	var out []sch.PageHeader
+	var nRead int64
 	var readOne bool
 	if n > 0 {
 		readOne = true
@@ -424,12 +782,12 @@ func PageHeadersAtOffset(r io.ReadSeeker, o, n int64) ([]sch.PageHeader, error)
 		rc := &readCounter{r: r}
 		ph, err := PageHeader(rc)
 		if err != nil {
-			return nil, fmt.Errorf("unable to read page header: %s", err)
+			return nil, fmt.Errorf("unable to read page header: %w", err)
 		}
 		out = append(out, *ph)
 		_, err = r.Seek(int64(ph.CompressedPageSize), io.SeekCurrent)
 		if err != nil {
-			return nil, fmt.Errorf("unable to seek to next page: %s", err)
+			return nil, fmt.Errorf("unable to seek to next page: %w", err)
 		}
 
 		nRead += int64(ph.DataPageHeader.NumValues)
@@ -460,10 +818,92 @@ func RepetitionRepeated(se *sch.SchemaElement) {
 
 var fieldFuncs = []FieldFunc{RepetitionRequired, RepetitionOptional, RepetitionRepeated}
 
+// Int32Type sets the type of a column to INT32
+func Int32Type(se *sch.SchemaElement) {
+	t := sch.Type_INT32
+	se.Type = &t
+}
+
+// Uint32Type sets the type of a column to INT32 with
+// a converted type of UINT_32
+func Uint32Type(se *sch.SchemaElement) {
+	t := sch.Type_INT32
+	se.Type = &t
+	ct := sch.ConvertedType_UINT_32
+	se.ConvertedType = &ct
+}
+
+// Int64Type sets the type of a column to INT64
+func Int64Type(se *sch.SchemaElement) {
+	t := sch.Type_INT64
+	se.Type = &t
+}
+
+// Uint64Type sets the type of a column to INT64 with
+// a converted type of UINT_64
+func Uint64Type(se *sch.SchemaElement) {
+	t := sch.Type_INT64
+	se.Type = &t
+	ct := sch.ConvertedType_UINT_64
+	se.ConvertedType = &ct
+}
+
+// Float32Type sets the type of a column to FLOAT
+func Float32Type(se *sch.SchemaElement) {
+	t := sch.Type_FLOAT
+	se.Type = &t
+}
+
+// Float64Type sets the type of a column to DOUBLE
+func Float64Type(se *sch.SchemaElement) {
+	t := sch.Type_DOUBLE
+	se.Type = &t
+}
+
+// BoolType sets the type of a column to BOOLEAN
+func BoolType(se *sch.SchemaElement) {
+	t := sch.Type_BOOLEAN
+	se.Type = &t
+}
+
+// StringType sets the type of a column to BYTE_ARRAY
+func StringType(se *sch.SchemaElement) {
+	t := sch.Type_BYTE_ARRAY
+	se.Type = &t
+}
+
+// Int32Ptr returns a pointer to i
+func Int32Ptr(i int32) *int32 { return &i }
+
+// Uint32Ptr returns a pointer to i
+func Uint32Ptr(i uint32) *uint32 { return &i }
+
+// Int64Ptr returns a pointer to i
+func Int64Ptr(i int64) *int64 { return &i }
+
+// Uint64Ptr returns a pointer to i
+func Uint64Ptr(i uint64) *uint64 { return &i }
+
+// BoolPtr returns a pointer to b
+func BoolPtr(b bool) *bool { return &b }
+
+// StringPtr returns a pointer to s
+func StringPtr(s string) *string { return &s }
+
+// Float32Ptr returns a pointer to f
+func Float32Ptr(f float32) *float32 { return &f }
+
+// Float64Ptr returns a pointer to f
+func Float64Ptr(f float64) *float64 { return &f }
+
 // GetBools reads a byte array and turns each bit into a bool
 func GetBools(r io.Reader, n int, pageSizes []int) ([]bool, error) {
 	var vals [8]bool
-	data, _ := ioutil.ReadAll(r)
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
 	out := make([]bool, 0, n)
 	for _, nVals := range pageSizes {
 
@@ -476,6 +916,10 @@ func GetBools(r io.Reader, n int, pageSizes []int) ([]bool, error) {
 			l++
 		}
 
+		if l > len(data) {
+			return nil, io.ErrUnexpectedEOF
+		}
+
 		var i int
 		chunk := data[:l]
 		data = data[l:]
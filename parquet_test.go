@@ -2,15 +2,20 @@ package parquet_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 	"math"
 	"math/rand"
 	"os"
+	"reflect"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/apache/thrift/lib/go/thrift"
 	"github.com/parsyl/parquet"
 	sch "github.com/parsyl/parquet/schema"
 	"github.com/stretchr/testify/assert"
@@ -501,6 +506,1070 @@ func TestParquet(t *testing.T) {
 	}
 }
 
+func TestReadBatch(t *testing.T) {
+	var expected []Person
+	for _, rg := range people {
+		expected = append(expected, rg...)
+	}
+
+	for _, size := range []int{1, 3, 5, 100} {
+		for _, reuse := range []bool{false, true} {
+			t.Run(fmt.Sprintf("batch size %d reuse %t", size, reuse), func(t *testing.T) {
+				b, err := generatedWrite(people, 2)
+				if !assert.NoError(t, err) {
+					return
+				}
+
+				r, err := NewParquetReader(bytes.NewReader(b))
+				if !assert.NoError(t, err) {
+					return
+				}
+
+				// a reused dst still holds the previous batch's
+				// optional and repeated fields
+				dst := make([]Person, size)
+				var out []Person
+				for {
+					if !reuse {
+						dst = make([]Person, size)
+					}
+
+					n, err := r.ReadBatch(dst)
+					out = append(out, dst[:n]...)
+
+					if err == io.EOF {
+						break
+					}
+					if !assert.NoError(t, err) {
+						return
+					}
+				}
+
+				assert.Equal(t, expected, out)
+			})
+		}
+	}
+}
+
+func TestAddBatch(t *testing.T) {
+	for _, size := range []int{1, 2, 3, 100} {
+		t.Run(fmt.Sprintf("batch size %d", size), func(t *testing.T) {
+			expected, err := generatedWrite(people, 2)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			var buf bytes.Buffer
+			w, err := NewParquetWriter(&buf, MaxPageSize(2))
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			for _, rg := range people {
+				for i := 0; i < len(rg); i += size {
+					j := i + size
+					if j > len(rg) {
+						j = len(rg)
+					}
+					assert.NoError(t, w.AddBatch(rg[i:j]))
+				}
+				assert.NoError(t, w.Write())
+			}
+
+			assert.NoError(t, w.Close())
+			assert.Equal(t, expected, buf.Bytes())
+		})
+	}
+
+	w, err := NewParquetWriter(&bytes.Buffer{}, MaxPageSize(0))
+	if assert.NoError(t, err) {
+		assert.EqualError(t, w.AddBatch([]Person{{}}), "invalid max page size: 0")
+	}
+}
+
+func TestConcurrency(t *testing.T) {
+	expected, err := generatedWrite(people, 2)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, n := range []int{1, 2, 32} {
+		t.Run(fmt.Sprintf("concurrency %d", n), func(t *testing.T) {
+			var buf bytes.Buffer
+			w, err := NewParquetWriter(&buf, MaxPageSize(2), Concurrency(n))
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			for _, rg := range people {
+				for _, p := range rg {
+					w.Add(p)
+				}
+				assert.NoError(t, w.Write())
+			}
+
+			assert.NoError(t, w.Close())
+			assert.Equal(t, expected, buf.Bytes())
+		})
+	}
+
+	_, err = NewParquetWriter(&bytes.Buffer{}, Concurrency(0))
+	assert.EqualError(t, err, "invalid concurrency: 0")
+}
+
+func TestReadConcurrency(t *testing.T) {
+	type testCase struct {
+		name string
+		opts []func(*ParquetReader)
+	}
+
+	testCases := []testCase{
+		{name: "concurrency", opts: []func(*ParquetReader){ReadConcurrency(4)}},
+		{name: "prefetch", opts: []func(*ParquetReader){Prefetch}},
+		{name: "concurrency and prefetch", opts: []func(*ParquetReader){ReadConcurrency(4), Prefetch}},
+	}
+
+	var expected []Person
+	for _, rg := range people {
+		expected = append(expected, rg...)
+	}
+
+	b, err := generatedWrite(people, 2)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%02d %s", i, tc.name), func(t *testing.T) {
+			r, err := NewParquetReader(bytes.NewReader(b), tc.opts...)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			var out []Person
+			for r.Next() {
+				var p Person
+				r.Scan(&p)
+				out = append(out, p)
+			}
+
+			assert.NoError(t, r.Error())
+			assert.Equal(t, expected, out)
+		})
+	}
+
+	_, err = NewParquetReader(struct{ io.ReadSeeker }{bytes.NewReader(b)}, Prefetch)
+	assert.EqualError(t, err, "concurrent reads require an io.ReaderAt, got struct { io.ReadSeeker }")
+}
+
+func TestParquetReaderAt(t *testing.T) {
+	var expected []Person
+	for _, rg := range people {
+		expected = append(expected, rg...)
+	}
+
+	b, err := generatedWrite(people, 2)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// both readers share ra, so they must not depend on its seek position
+	ra := bytes.NewReader(b)
+	r1, err := NewParquetReaderAt(ra, int64(len(b)))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	r2, err := NewParquetReaderAt(ra, int64(len(b)), ReadConcurrency(2), Prefetch)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var out1, out2 []Person
+	for r1.Next() && r2.Next() {
+		var p1, p2 Person
+		r1.Scan(&p1)
+		r2.Scan(&p2)
+		out1 = append(out1, p1)
+		out2 = append(out2, p2)
+	}
+
+	assert.NoError(t, r1.Error())
+	assert.NoError(t, r2.Error())
+	assert.Equal(t, expected, out1)
+	assert.Equal(t, expected, out2)
+
+	_, err = NewParquetReaderAt(ra, 4)
+	assert.EqualError(t, err, "a parquet file is at least 12 bytes, got 4")
+}
+
+func TestColumnChunkOffsets(t *testing.T) {
+	var expected []Person
+	for _, rg := range people {
+		expected = append(expected, rg...)
+	}
+
+	b, err := generatedWrite(people, 2)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	b, err = relayout(b)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	r, err := NewParquetReader(bytes.NewReader(b))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var out []Person
+	for r.Next() {
+		var p Person
+		r.Scan(&p)
+		out = append(out, p)
+	}
+
+	assert.NoError(t, r.Error())
+	assert.Equal(t, expected, out)
+}
+
+// relayout rewrites a parquet file the way other writers might lay it
+// out: the column chunks are written in reverse order with padding in
+// between them and FileOffset points to the end of each column chunk.
+func relayout(b []byte) ([]byte, error) {
+	footer, err := parquet.ReadMetaData(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.Write([]byte("PAR1"))
+	for _, rg := range footer.RowGroups {
+		var cols []*sch.ColumnChunk
+		for i := len(rg.Columns) - 1; i >= 0; i-- {
+			ch := rg.Columns[i]
+			buf.Write([]byte("padding"))
+			start := ch.MetaData.DataPageOffset
+			ch.MetaData.DataPageOffset = int64(buf.Len())
+			buf.Write(b[start : start+ch.MetaData.TotalCompressedSize])
+			ch.FileOffset = int64(buf.Len())
+			cols = append(cols, ch)
+		}
+		rg.Columns = cols
+	}
+
+	ts := thrift.NewTSerializer()
+	ts.Protocol = thrift.NewTCompactProtocolFactory().GetProtocol(ts.Transport)
+	meta, err := ts.Write(context.Background(), footer)
+	if err != nil {
+		return nil, err
+	}
+
+	buf.Write(meta)
+	binary.Write(&buf, binary.LittleEndian, uint32(len(meta)))
+	buf.Write([]byte("PAR1"))
+	return buf.Bytes(), nil
+}
+
+// rewriteFooter replaces the metadata of the parquet file b
+// with the metadata as modified by fn.
+func rewriteFooter(b []byte, fn func(*sch.FileMetaData)) ([]byte, error) {
+	footer, err := parquet.ReadMetaData(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	fn(footer)
+	ts := thrift.NewTSerializer()
+	ts.Protocol = thrift.NewTCompactProtocolFactory().GetProtocol(ts.Transport)
+	meta, err := ts.Write(context.Background(), footer)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.Write(b[:len(b)-8-int(binary.LittleEndian.Uint32(b[len(b)-8:]))])
+	buf.Write(meta)
+	binary.Write(&buf, binary.LittleEndian, uint32(len(meta)))
+	buf.Write([]byte("PAR1"))
+	return buf.Bytes(), nil
+}
+
+func TestContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, n := range []int{1, 4} {
+		var buf bytes.Buffer
+		w, err := NewParquetWriter(&buf, Concurrency(n))
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		w.Add(Person{})
+		assert.Equal(t, context.Canceled, w.WriteContext(ctx))
+		assert.Equal(t, context.Canceled, w.CloseContext(ctx))
+	}
+
+	b, err := generatedWrite(people, 2)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, opts := range [][]func(*ParquetReader){nil, {Prefetch}} {
+		r, err := NewParquetReader(bytes.NewReader(b), opts...)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var n int
+		for r.NextContext(ctx) {
+			var p Person
+			r.Scan(&p)
+			n++
+			if n == 6 {
+				cancel()
+			}
+		}
+
+		assert.Equal(t, 6, n)
+		assert.Equal(t, context.Canceled, r.Error())
+
+		// the reader stays failed
+		assert.False(t, r.Next())
+		assert.Equal(t, context.Canceled, r.Error())
+	}
+
+	// a context that is done after the last record has been read
+	r, err := NewParquetReader(bytes.NewReader(b))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for r.Next() {
+		var p Person
+		r.Scan(&p)
+	}
+	assert.NoError(t, r.Error())
+	assert.False(t, r.NextContext(ctx))
+	assert.Equal(t, context.Canceled, r.Error())
+}
+
+func TestPrefetchCallContext(t *testing.T) {
+	peeps := append(append([][]Person{}, people...), people...)
+	var expected []Person
+	for _, rg := range peeps {
+		expected = append(expected, rg...)
+	}
+
+	b, err := generatedWrite(peeps, 2)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	r, err := NewParquetReader(bytes.NewReader(b), ReadConcurrency(2), Prefetch)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer r.Close()
+
+	// the context of each call is cancelled as soon as it returns,
+	// which must not stop the next row group from being prefetched
+	var out []Person
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		ok := r.NextContext(ctx)
+		cancel()
+		if !ok {
+			break
+		}
+
+		var p Person
+		r.Scan(&p)
+		out = append(out, p)
+	}
+
+	assert.NoError(t, r.Error())
+	assert.Equal(t, expected, out)
+	assert.NoError(t, r.Close())
+}
+
+func TestRecordCount(t *testing.T) {
+	b, err := generatedWrite(people, 2)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// claim that the first row group has one more row than
+	// its columns have records
+	b, err = rewriteFooter(b, func(footer *sch.FileMetaData) {
+		footer.RowGroups[0].NumRows++
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, opts := range [][]func(*ParquetReader){nil, {ReadConcurrency(4)}} {
+		_, err := NewParquetReader(bytes.NewReader(b), opts...)
+		assert.Equal(t, &parquet.RecordCountError{Column: "id", Records: 5, Expected: 6}, err)
+	}
+}
+
+// oneByteReader returns at most one byte from each call to Read.
+type oneByteReader struct {
+	*bytes.Reader
+}
+
+func (r oneByteReader) Read(p []byte) (int, error) {
+	if len(p) > 1 {
+		p = p[:1]
+	}
+	return r.Reader.Read(p)
+}
+
+func TestShortReads(t *testing.T) {
+	for _, opt := range []func(*ParquetWriter) error{Snappy, Uncompressed, Gzip} {
+		b, err := generatedWriteWith(people, MaxPageSize(2), opt)
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		r, err := NewParquetReader(oneByteReader{bytes.NewReader(b)})
+		if !assert.NoError(t, err) {
+			return
+		}
+
+		var out []Person
+		for r.Next() {
+			var p Person
+			r.Scan(&p)
+			out = append(out, p)
+		}
+		assert.NoError(t, r.Error())
+		assert.Equal(t, append(people[0], people[1]...), out)
+	}
+}
+
+func TestChecksums(t *testing.T) {
+	b, err := generatedWriteWith(people, MaxPageSize(2), Checksums)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	footer, err := parquet.ReadMetaData(bytes.NewReader(b))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	headers, err := parquet.PageHeaders(footer, bytes.NewReader(b))
+	if !assert.NoError(t, err) {
+		return
+	}
+	for _, ph := range headers {
+		assert.NotNil(t, ph.Crc)
+	}
+
+	out, err := generatedRead(b)
+	assert.NoError(t, err)
+	assert.Equal(t, append(people[0], people[1]...), out)
+
+	// corrupt the data of the last page of the first column
+	ch := footer.RowGroups[0].Columns[0]
+	corrupt := append([]byte{}, b...)
+	corrupt[ch.MetaData.DataPageOffset+ch.MetaData.TotalCompressedSize-1]++
+
+	_, err = NewParquetReader(bytes.NewReader(corrupt))
+	assert.True(t, errors.Is(err, parquet.ErrChecksum), err)
+	var pe *parquet.ErrCorruptPage
+	if assert.True(t, errors.As(err, &pe)) {
+		assert.Equal(t, strings.Join(ch.MetaData.PathInSchema, "."), pe.Column)
+	}
+}
+
+func TestEncodings(t *testing.T) {
+	delta := sch.Encoding_DELTA_BINARY_PACKED
+	encodings := map[string]sch.Encoding{
+		"id":          delta,
+		"age":         delta,
+		"happiness":   delta,
+		"sadness":     delta,
+		"birthday":    delta,
+		"anniversary": delta,
+		"friends.id":  delta,
+		"code":        sch.Encoding_DELTA_BYTE_ARRAY,
+		"bff":         sch.Encoding_DELTA_LENGTH_BYTE_ARRAY,
+		"hobby.name":  sch.Encoding_DELTA_BYTE_ARRAY,
+		"funkiness":   sch.Encoding_BYTE_STREAM_SPLIT,
+		"boldness":    sch.Encoding_BYTE_STREAM_SPLIT,
+		"lameness":    sch.Encoding_BYTE_STREAM_SPLIT,
+		"keen":        sch.Encoding_RLE,
+		"hungry":      sch.Encoding_RLE,
+	}
+	opts := []func(*ParquetWriter) error{MaxPageSize(2)}
+	for col, enc := range encodings {
+		opts = append(opts, Encoding(col, enc))
+	}
+
+	b, err := generatedWriteWith(people, opts...)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	footer, err := parquet.ReadMetaData(bytes.NewReader(b))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, ch := range footer.RowGroups[0].Columns {
+		col := strings.Join(ch.MetaData.PathInSchema, ".")
+		enc, ok := encodings[col]
+		if !ok {
+			enc = sch.Encoding_PLAIN
+		}
+
+		assert.Contains(t, ch.MetaData.Encodings, enc, col)
+		headers, err := parquet.PageHeadersAtOffset(bytes.NewReader(b), ch.MetaData.DataPageOffset, ch.MetaData.NumValues)
+		if assert.NoError(t, err) {
+			for _, ph := range headers {
+				assert.Equal(t, enc, ph.DataPageHeader.Encoding, col)
+			}
+		}
+	}
+
+	out, err := generatedRead(b)
+	assert.NoError(t, err)
+	assert.Equal(t, append(people[0], people[1]...), out)
+
+	_, err = NewParquetWriter(&bytes.Buffer{}, Encoding("funkiness", delta))
+	assert.True(t, errors.Is(err, parquet.ErrUnsupportedEncoding), err)
+
+	_, err = NewParquetWriter(&bytes.Buffer{}, Encoding("happiness", sch.Encoding_BYTE_STREAM_SPLIT))
+	assert.True(t, errors.Is(err, parquet.ErrUnsupportedEncoding), err)
+
+	_, err = NewParquetWriter(&bytes.Buffer{}, Encoding("bff", sch.Encoding_RLE))
+	assert.True(t, errors.Is(err, parquet.ErrUnsupportedEncoding), err)
+
+	_, err = NewParquetWriter(&bytes.Buffer{}, Encoding("id", sch.Encoding_DELTA_BYTE_ARRAY))
+	assert.True(t, errors.Is(err, parquet.ErrUnsupportedEncoding), err)
+
+	_, err = NewParquetWriter(&bytes.Buffer{}, Encoding("bogus", delta))
+	var se *parquet.ErrSchemaMismatch
+	assert.True(t, errors.As(err, &se), err)
+}
+
+func TestKeyValueMetadata(t *testing.T) {
+	b, err := generatedWriteWith(people, KeyValue("job", "42"), KeyValue("commit", "abc"), KeyValue("job", "43"), CreatedBy("people 1.0"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	footer, err := parquet.ReadMetaData(bytes.NewReader(b))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	if assert.Len(t, footer.KeyValueMetadata, 2) {
+		assert.Equal(t, "job", footer.KeyValueMetadata[0].Key)
+		assert.Equal(t, "43", *footer.KeyValueMetadata[0].Value)
+	}
+
+	expected := map[string]string{"job": "43", "commit": "abc"}
+	r, err := NewParquetReader(bytes.NewReader(b))
+	if assert.NoError(t, err) {
+		assert.Equal(t, expected, r.KeyValueMetadata())
+		assert.Equal(t, "people 1.0", r.CreatedBy())
+	}
+
+	rr, err := parquet.NewReader(bytes.NewReader(b), reflect.TypeOf(Person{}))
+	if assert.NoError(t, err) {
+		assert.Equal(t, expected, rr.KeyValueMetadata())
+		assert.Equal(t, "people 1.0", rr.CreatedBy())
+	}
+
+	f, err := parquet.OpenFile(bytes.NewReader(b))
+	if assert.NoError(t, err) {
+		assert.Equal(t, expected, f.KeyValueMetadata())
+		assert.Equal(t, "people 1.0", f.CreatedBy())
+	}
+
+	b, err = generatedWrite(people, 2)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	r, err = NewParquetReader(bytes.NewReader(b))
+	if assert.NoError(t, err) {
+		assert.Equal(t, map[string]string{}, r.KeyValueMetadata())
+		assert.Equal(t, "", r.CreatedBy())
+	}
+}
+
+// memFile is an in memory io.ReadWriteSeeker.
+type memFile struct {
+	b   []byte
+	pos int64
+}
+
+func (f *memFile) Read(p []byte) (int, error) {
+	if f.pos >= int64(len(f.b)) {
+		return 0, io.EOF
+	}
+	n := copy(p, f.b[f.pos:])
+	f.pos += int64(n)
+	return n, nil
+}
+
+func (f *memFile) Write(p []byte) (int, error) {
+	if end := f.pos + int64(len(p)); end > int64(len(f.b)) {
+		f.b = append(f.b, make([]byte, end-int64(len(f.b)))...)
+	}
+	n := copy(f.b[f.pos:], p)
+	f.pos += int64(n)
+	return n, nil
+}
+
+func (f *memFile) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekCurrent:
+		offset += f.pos
+	case io.SeekEnd:
+		offset += int64(len(f.b))
+	}
+	if offset < 0 {
+		return 0, fmt.Errorf("invalid offset %d", offset)
+	}
+	f.pos = offset
+	return offset, nil
+}
+
+func (f *memFile) Truncate(size int64) error {
+	if size < 0 || size > int64(len(f.b)) {
+		return fmt.Errorf("invalid size %d", size)
+	}
+	f.b = f.b[:size]
+	return nil
+}
+
+func TestAppender(t *testing.T) {
+	b, err := generatedWriteWith(people[:1], MaxPageSize(2), KeyValue("job", "1"), CreatedBy("people"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	f := &memFile{b: b}
+	w, err := NewParquetAppender(f, MaxPageSize(3), Gzip, KeyValue("run", "2"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, p := range people[1] {
+		w.Add(p)
+	}
+	assert.NoError(t, w.Write())
+	assert.NoError(t, w.Close())
+
+	footer, err := parquet.ReadMetaData(bytes.NewReader(f.b))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, footer.RowGroups, 2)
+	assert.Equal(t, int64(len(people[0])+len(people[1])), footer.NumRows)
+
+	r, err := NewParquetReader(bytes.NewReader(f.b))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	var out []Person
+	for r.Next() {
+		var p Person
+		r.Scan(&p)
+		out = append(out, p)
+	}
+	assert.NoError(t, r.Error())
+	assert.Equal(t, append(people[0], people[1]...), out)
+	assert.Equal(t, map[string]string{"job": "1", "run": "2"}, r.KeyValueMetadata())
+	assert.Equal(t, "people", r.CreatedBy())
+
+	// the new footer is shorter than the old one (its key/value
+	// metadata is replaced), so the old footer's tail must not be
+	// left at the end of the file
+	b, err = generatedWriteWith(people[:1], KeyValue("job", strings.Repeat("x", 4096)))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	f = &memFile{b: b}
+	w, err = NewParquetAppender(f, KeyValue("job", "2"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, p := range people[1] {
+		w.Add(p)
+	}
+	assert.NoError(t, w.Write())
+	assert.NoError(t, w.Close())
+	assert.True(t, len(f.b) < len(b), "appended file is %d bytes, the original is %d", len(f.b), len(b))
+
+	r, err = NewParquetReader(bytes.NewReader(f.b))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	out = nil
+	for r.Next() {
+		var p Person
+		r.Scan(&p)
+		out = append(out, p)
+	}
+	assert.NoError(t, r.Error())
+	assert.Equal(t, append(people[0], people[1]...), out)
+	assert.Equal(t, map[string]string{"job": "2"}, r.KeyValueMetadata())
+
+	// the column orders and sorting columns of a sorted file
+	// are kept
+	b, err = generatedWriteWith(people[:1], SortBy("happiness"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	in, err := parquet.ReadMetaData(bytes.NewReader(b))
+	if !assert.NoError(t, err) || !assert.NotEmpty(t, in.ColumnOrders) {
+		return
+	}
+
+	f = &memFile{b: b}
+	w, err = NewParquetAppender(f)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	for _, p := range people[1] {
+		w.Add(p)
+	}
+	assert.NoError(t, w.Write())
+	assert.NoError(t, w.Close())
+
+	footer, err = parquet.ReadMetaData(bytes.NewReader(f.b))
+	if assert.NoError(t, err) && assert.Len(t, footer.RowGroups, 2) {
+		assert.Equal(t, in.ColumnOrders, footer.ColumnOrders)
+		assert.Equal(t, in.RowGroups[0].SortingColumns, footer.RowGroups[0].SortingColumns)
+		assert.Nil(t, footer.RowGroups[1].SortingColumns)
+	}
+
+	// a file that can't be truncated
+	_, err = NewParquetAppender(struct{ io.ReadWriteSeeker }{&memFile{b: b}})
+	assert.EqualError(t, err, "appending requires a file that can be truncated, got struct { io.ReadWriteSeeker }")
+
+	// a file with a different schema
+	var buf bytes.Buffer
+	fw, err := parquet.NewFileWriter(&buf, []parquet.Field{
+		{Path: []string{"id"}, Types: []int{0}, Type: parquet.Int64Type, RepetitionType: parquet.RepetitionRequired},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, fw.StartRowGroup(1))
+	assert.NoError(t, fw.WriteColumn("id", []int64{1}, nil, nil))
+	assert.NoError(t, fw.Close())
+
+	f = &memFile{b: buf.Bytes()}
+	_, err = NewParquetAppender(f)
+	var se *parquet.ErrSchemaMismatch
+	if assert.True(t, errors.As(err, &se), err) {
+		assert.Equal(t, "id", se.Column)
+	}
+	assert.Equal(t, buf.Bytes(), f.b)
+}
+
+func TestMerge(t *testing.T) {
+	a, err := generatedWriteWith(people, MaxPageSize(2), KeyValue("job", "a"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	b, err := generatedWriteWith(people[:1], MaxPageSize(3), Gzip, Encoding("happiness", sch.Encoding_DELTA_BINARY_PACKED))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	expected := append(append(append([]Person{}, people[0]...), people[1]...), people[0]...)
+
+	var buf bytes.Buffer
+	err = parquet.Merge(&buf, []io.ReadSeeker{bytes.NewReader(a), bytes.NewReader(b)})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	out, err := generatedRead(buf.Bytes())
+	if assert.NoError(t, err) {
+		assert.Equal(t, expected, out)
+	}
+
+	footer, err := parquet.ReadMetaData(bytes.NewReader(buf.Bytes()))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Len(t, footer.RowGroups, 3)
+	assert.Equal(t, int64(len(expected)), footer.NumRows)
+	v := "a"
+	assert.Equal(t, []*sch.KeyValue{{Key: "job", Value: &v}}, footer.KeyValueMetadata)
+
+	// the column chunks are copied as they are
+	in, err := parquet.ReadMetaData(bytes.NewReader(b))
+	if !assert.NoError(t, err) {
+		return
+	}
+	for i, ch := range footer.RowGroups[2].Columns {
+		orig := in.RowGroups[0].Columns[i].MetaData
+		md := ch.MetaData
+		assert.Equal(t, orig.TotalCompressedSize, md.TotalCompressedSize)
+		assert.Equal(t, orig.Encodings, md.Encodings)
+		assert.Equal(t, b[orig.DataPageOffset:orig.DataPageOffset+orig.TotalCompressedSize], buf.Bytes()[md.DataPageOffset:md.DataPageOffset+md.TotalCompressedSize])
+	}
+
+	// a file whose writer sets dictionary_page_offset to 0 when
+	// there's no dictionary page
+	var zero int64
+	z, err := rewriteFooter(b, func(footer *sch.FileMetaData) {
+		for _, rg := range footer.RowGroups {
+			for _, ch := range rg.Columns {
+				ch.MetaData.DictionaryPageOffset = &zero
+				ch.FileOffset = 0
+			}
+		}
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	buf.Reset()
+	err = parquet.Merge(&buf, []io.ReadSeeker{bytes.NewReader(a), bytes.NewReader(z)})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	out, err = generatedRead(buf.Bytes())
+	if assert.NoError(t, err) {
+		assert.Equal(t, expected, out)
+	}
+
+	footer, err = parquet.ReadMetaData(bytes.NewReader(buf.Bytes()))
+	if assert.NoError(t, err) {
+		for _, rg := range footer.RowGroups {
+			for _, ch := range rg.Columns {
+				assert.Equal(t, ch.MetaData.DataPageOffset, ch.FileOffset)
+			}
+		}
+		for _, ch := range footer.RowGroups[2].Columns {
+			assert.Equal(t, zero, *ch.MetaData.DictionaryPageOffset)
+		}
+	}
+
+	// coalesced into one row group
+	buf.Reset()
+	err = parquet.Merge(&buf, []io.ReadSeeker{bytes.NewReader(a), bytes.NewReader(b)}, parquet.MergeCoalesce(int64(len(expected))))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	out, err = generatedRead(buf.Bytes())
+	if assert.NoError(t, err) {
+		assert.Equal(t, expected, out)
+	}
+
+	footer, err = parquet.ReadMetaData(bytes.NewReader(buf.Bytes()))
+	if assert.NoError(t, err) {
+		assert.Len(t, footer.RowGroups, 1)
+		assert.Equal(t, int64(len(expected)), footer.RowGroups[0].NumRows)
+	}
+
+	// coalesced row groups keep their sorting columns if they're
+	// still sorted
+	happy := func(vals ...int64) []byte {
+		var rg []Person
+		for _, v := range vals {
+			rg = append(rg, Person{Happiness: v})
+		}
+		b, err := generatedWriteWith([][]Person{rg}, SortBy("happiness"))
+		assert.NoError(t, err)
+		return b
+	}
+
+	for _, tc := range []struct {
+		files  [][]byte
+		sorted bool
+	}{
+		{files: [][]byte{happy(1, 3), happy(3, 5)}, sorted: true},
+		{files: [][]byte{happy(1, 3), happy(2, 5)}},
+		{files: [][]byte{happy(1, 3), a}},
+	} {
+		files := make([]io.ReadSeeker, len(tc.files))
+		for i, f := range tc.files {
+			files[i] = bytes.NewReader(f)
+		}
+
+		buf.Reset()
+		if !assert.NoError(t, parquet.Merge(&buf, files, parquet.MergeCoalesce(100))) {
+			return
+		}
+
+		footer, err = parquet.ReadMetaData(bytes.NewReader(buf.Bytes()))
+		if !assert.NoError(t, err) || !assert.Len(t, footer.RowGroups, 1) {
+			return
+		}
+
+		sc := footer.RowGroups[0].SortingColumns
+		if tc.sorted {
+			assert.Len(t, sc, 1)
+		} else {
+			assert.Nil(t, sc)
+		}
+	}
+
+	// the column orders are kept if all of the files have the same ones
+	sorted, err := generatedWriteWith(people, SortBy("code"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	in, err = parquet.ReadMetaData(bytes.NewReader(sorted))
+	if !assert.NoError(t, err) || !assert.NotEmpty(t, in.ColumnOrders) {
+		return
+	}
+
+	for _, tc := range []struct {
+		files    [][]byte
+		expected []*sch.ColumnOrder
+	}{
+		{files: [][]byte{sorted, sorted}, expected: in.ColumnOrders},
+		{files: [][]byte{sorted, a}},
+	} {
+		files := make([]io.ReadSeeker, len(tc.files))
+		for i, f := range tc.files {
+			files[i] = bytes.NewReader(f)
+		}
+
+		buf.Reset()
+		if !assert.NoError(t, parquet.Merge(&buf, files)) {
+			return
+		}
+
+		footer, err = parquet.ReadMetaData(bytes.NewReader(buf.Bytes()))
+		if assert.NoError(t, err) {
+			assert.Equal(t, tc.expected, footer.ColumnOrders)
+		}
+	}
+
+	// a file with a different schema
+	var other bytes.Buffer
+	fw, err := parquet.NewFileWriter(&other, []parquet.Field{
+		{Path: []string{"id"}, Types: []int{0}, Type: parquet.Int64Type, RepetitionType: parquet.RepetitionRequired},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, fw.StartRowGroup(1))
+	assert.NoError(t, fw.WriteColumn("id", []int64{1}, nil, nil))
+	assert.NoError(t, fw.Close())
+
+	err = parquet.Merge(&bytes.Buffer{}, []io.ReadSeeker{bytes.NewReader(a), bytes.NewReader(other.Bytes())})
+	var se *parquet.ErrSchemaMismatch
+	if assert.True(t, errors.As(err, &se), err) {
+		assert.Equal(t, "id", se.Column)
+	}
+}
+
+func TestSortBy(t *testing.T) {
+	sortBy := []string{"code", "hobby.name", "happiness"}
+	expected := []Person{
+		people[0][2], people[0][3], people[0][1], people[0][4], people[0][0],
+		people[1][1], people[1][0], people[1][2],
+	}
+
+	reflectWriteSorted := func(peeps [][]Person) ([]byte, error) {
+		var buf bytes.Buffer
+		w, err := parquet.NewWriter(&buf, reflect.TypeOf(Person{}), parquet.WriterMaxPageSize(2), parquet.WriterSortBy(sortBy...))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, rg := range peeps {
+			for _, p := range rg {
+				if err := w.Add(&p); err != nil {
+					return nil, err
+				}
+			}
+			if err := w.Write(); err != nil {
+				return nil, err
+			}
+		}
+
+		err = w.Close()
+		return buf.Bytes(), err
+	}
+
+	testCases := []struct {
+		name  string
+		write func([][]Person) ([]byte, error)
+	}{
+		{name: "generated", write: func(peeps [][]Person) ([]byte, error) {
+			return generatedWriteWith(peeps, MaxPageSize(2), SortBy(sortBy...))
+		}},
+		{name: "reflection", write: reflectWriteSorted},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := tc.write(people)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			out, err := generatedRead(b)
+			if assert.NoError(t, err) {
+				assert.Equal(t, expected, out)
+			}
+
+			f, err := parquet.OpenFile(bytes.NewReader(b))
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			idx := map[string]int32{}
+			for i, fld := range f.Fields() {
+				idx[fld.Name] = int32(i)
+			}
+
+			footer := f.MetaData()
+			assert.Len(t, footer.ColumnOrders, len(f.Fields()))
+			for _, rg := range footer.RowGroups {
+				assert.Equal(t, []*sch.SortingColumn{
+					{ColumnIdx: idx["code"], NullsFirst: true},
+					{ColumnIdx: idx["hobby.name"], NullsFirst: true},
+					{ColumnIdx: idx["happiness"], NullsFirst: true},
+				}, rg.SortingColumns)
+			}
+		})
+	}
+
+	_, err := NewParquetWriter(&bytes.Buffer{}, SortBy("friends.id"))
+	assert.EqualError(t, err, "can't sort by column friends.id, it doesn't exist or is repeated")
+
+	_, err = parquet.NewWriter(&bytes.Buffer{}, reflect.TypeOf(Person{}), parquet.WriterSortBy("friends.id"))
+	assert.EqualError(t, err, "can't sort by column friends.id, it is repeated")
+
+	_, err = parquet.NewWriter(&bytes.Buffer{}, reflect.TypeOf(Person{}), parquet.WriterSortBy("nope"))
+	var se *parquet.ErrSchemaMismatch
+	assert.True(t, errors.As(err, &se), err)
+}
+
 func TestPageHeaders(t *testing.T) {
 	var buf bytes.Buffer
 	w, err := NewParquetWriter(&buf, MaxPageSize(2))
@@ -533,6 +1602,19 @@ func TestPageHeaders(t *testing.T) {
 	}
 
 	assert.Equal(t, 88, len(pageHeaders))
+
+	footerAt, err := parquet.ReadMetaDataAt(rd, int64(buf.Len()))
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, footer, footerAt)
+
+	col := footer.RowGroups[1].Columns[0].MetaData
+	headers, err := parquet.PageHeadersAt(rd, col.DataPageOffset, col.NumValues)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, pageHeaders[44:46], headers)
 }
 
 func TestStats(t *testing.T) {
@@ -962,6 +2044,19 @@ func BenchmarkWrite(b *testing.B) {
 	assert.Nil(b, err, "benchmark write")
 }
 
+func BenchmarkWriteBatch(b *testing.B) {
+	var buf bytes.Buffer
+	w, err := NewParquetWriter(&buf, MaxPageSize(10000))
+	assert.Nil(b, err, "benchmark write batch")
+	input := getPeople(b.N, b.N)
+
+	err = w.AddBatch(input[0])
+	assert.Nil(b, err, "benchmark write batch")
+
+	err = w.Close()
+	assert.Nil(b, err, "benchmark write batch")
+}
+
 func writeInt64(i int64) []byte {
 	var buf bytes.Buffer
 	binary.Write(&buf, binary.LittleEndian, i)
@@ -1058,3 +2153,10 @@ type Document struct {
 	Links []Link
 	Names []Name
 }
+
+func pint32(i int32) *int32       { return &i }
+func pint64(i int64) *int64       { return &i }
+func puint64(i uint64) *uint64    { return &i }
+func pbool(b bool) *bool          { return &b }
+func pstring(s string) *string    { return &s }
+func pfloat32(f float32) *float32 { return &f }
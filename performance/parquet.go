@@ -3,10 +3,13 @@ package performance
 // Code generated by github.com/parsyl/parquet.  DO NOT EDIT.
 
 import (
+	"context"
 	"encoding/binary"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/parsyl/parquet"
 	. "github.com/parsyl/parquet/performance/message"
@@ -15,17 +18,6 @@ import (
 	"math"
 )
 
-type compression int
-
-const (
-	compressionUncompressed compression = 0
-	compressionSnappy       compression = 1
-	compressionGzip         compression = 2
-	compressionUnknown      compression = -1
-)
-
-var buffpool = bytebufferpool.Pool{}
-
 // ParquetWriter reprents a row group
 type ParquetWriter struct {
 	fields []Field
@@ -41,51 +33,85 @@ type ParquetWriter struct {
 
 	meta        *parquet.Metadata
 	w           io.Writer
-	compression compression
+	compression sch.CompressionCodec
+
+	// concurrency is the number of columns that are
+	// encoded and compressed at the same time by Write
+	concurrency int
+
+	// checksums is true if each page header
+	// includes a CRC32 of the page's data
+	checksums bool
+
+	// encodings holds the encoding of each column
+	// that isn't PLAIN encoded
+	encodings map[string]sch.Encoding
+
+	// keyValues and createdBy are written to
+	// the footer
+	keyValues [][2]string
+	createdBy *string
+
+	// sortBy holds the columns that each row group is sorted by,
+	// along with their compare funcs, and records holds the records
+	// that have been added since the last Write
+	sortBy   []string
+	compares []func(a, b Message) int
+	records  []Message
+
+	// appended is the file that NewParquetAppender
+	// writes to.  Close truncates it to the end of the new
+	// footer, which can be shorter than the old one.
+	appended truncater
 }
 
-func Fields(compression compression) []Field {
+type truncater interface {
+	io.Seeker
+	Truncate(size int64) error
+}
+
+func Fields(compression sch.CompressionCodec) []Field {
 	return []Field{
-		NewStringOptionalField(readColStr0, writeColStr0, []string{"col_str_0"}, []int{1}, optionalFieldCompression(compression)),
-		NewStringField(readColStr1, writeColStr1, []string{"col_str_1"}, fieldCompression(compression)),
-		NewStringOptionalField(readColStr2, writeColStr2, []string{"col_str_2"}, []int{1}, optionalFieldCompression(compression)),
-		NewStringField(readColStr3, writeColStr3, []string{"col_str_3"}, fieldCompression(compression)),
-		NewStringOptionalField(readColStr4, writeColStr4, []string{"col_str_4"}, []int{1}, optionalFieldCompression(compression)),
-		NewStringField(readColStr5, writeColStr5, []string{"col_str_5"}, fieldCompression(compression)),
-		NewStringOptionalField(readColStr6, writeColStr6, []string{"col_str_6"}, []int{1}, optionalFieldCompression(compression)),
-		NewStringField(readColStr7, writeColStr7, []string{"col_str_7"}, fieldCompression(compression)),
-		NewStringOptionalField(readColStr8, writeColStr8, []string{"col_str_8"}, []int{1}, optionalFieldCompression(compression)),
-		NewStringField(readColStr9, writeColStr9, []string{"col_str_9"}, fieldCompression(compression)),
-		NewInt64OptionalField(readColInt0, writeColInt0, []string{"col_int_0"}, []int{1}, optionalFieldCompression(compression)),
-		NewInt64Field(readColInt1, writeColInt1, []string{"col_int_1"}, fieldCompression(compression)),
-		NewInt64OptionalField(readColInt2, writeColInt2, []string{"col_int_2"}, []int{1}, optionalFieldCompression(compression)),
-		NewInt64Field(readColInt3, writeColInt3, []string{"col_int_3"}, fieldCompression(compression)),
-		NewInt64OptionalField(readColInt4, writeColInt4, []string{"col_int_4"}, []int{1}, optionalFieldCompression(compression)),
-		NewInt32OptionalField(readColInt32_0, writeColInt32_0, []string{"col_int_32_0"}, []int{1}, optionalFieldCompression(compression)),
-		NewInt32Field(readColInt32_1, writeColInt32_1, []string{"col_int_32_1"}, fieldCompression(compression)),
-		NewInt32OptionalField(readColInt32_2, writeColInt32_2, []string{"col_int_32_2"}, []int{1}, optionalFieldCompression(compression)),
-		NewInt32Field(readColInt32_3, writeColInt32_3, []string{"col_int_32_3"}, fieldCompression(compression)),
-		NewInt32OptionalField(readColInt32_4, writeColInt32_4, []string{"col_int_32_4"}, []int{1}, optionalFieldCompression(compression)),
-		NewFloat64OptionalField(readColFloat0, writeColFloat0, []string{"col_float_0"}, []int{1}, optionalFieldCompression(compression)),
-		NewFloat64Field(readColFloat1, writeColFloat1, []string{"col_float_1"}, fieldCompression(compression)),
-		NewFloat64OptionalField(readColFloat2, writeColFloat2, []string{"col_float_2"}, []int{1}, optionalFieldCompression(compression)),
-		NewFloat64Field(readColFloat3, writeColFloat3, []string{"col_float_3"}, fieldCompression(compression)),
-		NewFloat64OptionalField(readColFloat4, writeColFloat4, []string{"col_float_4"}, []int{1}, optionalFieldCompression(compression)),
-		NewFloat32OptionalField(readColFloat32_0, writeColFloat32_0, []string{"col_float_32_0"}, []int{1}, optionalFieldCompression(compression)),
-		NewFloat32Field(readColFloat32_1, writeColFloat32_1, []string{"col_float_32_1"}, fieldCompression(compression)),
-		NewFloat32OptionalField(readColFloat32_2, writeColFloat32_2, []string{"col_float_32_2"}, []int{1}, optionalFieldCompression(compression)),
-		NewFloat32Field(readColFloat32_3, writeColFloat32_3, []string{"col_float_32_3"}, fieldCompression(compression)),
-		NewFloat32OptionalField(readColFloat32_4, writeColFloat32_4, []string{"col_float_32_4"}, []int{1}, optionalFieldCompression(compression)),
-		NewBoolOptionalField(readColBool0, writeColBool0, []string{"col_bool_0"}, []int{1}, optionalFieldCompression(compression)),
-		NewBoolField(readColBool1, writeColBool1, []string{"col_bool_1"}, fieldCompression(compression)),
-		NewBoolOptionalField(readColBool2, writeColBool2, []string{"col_bool_2"}, []int{1}, optionalFieldCompression(compression)),
-		NewBoolField(readColBool3, writeColBool3, []string{"col_bool_3"}, fieldCompression(compression)),
-		NewBoolOptionalField(readColBool4, writeColBool4, []string{"col_bool_4"}, []int{1}, optionalFieldCompression(compression)),
-		NewBoolField(readColBool5, writeColBool5, []string{"col_bool_5"}, fieldCompression(compression)),
-		NewBoolOptionalField(readColBool6, writeColBool6, []string{"col_bool_6"}, []int{1}, optionalFieldCompression(compression)),
-		NewBoolField(readColBool7, writeColBool7, []string{"col_bool_7"}, fieldCompression(compression)),
-		NewBoolOptionalField(readColBool8, writeColBool8, []string{"col_bool_8"}, []int{1}, optionalFieldCompression(compression)),
-		NewBoolField(readColBool9, writeColBool9, []string{"col_bool_9"}, fieldCompression(compression)),
+		NewStringOptionalField(readColStr0, writeColStr0, []string{"col_str_0"}, []int{1}, parquet.OptionalFieldCompression(compression)),
+		NewStringField(readColStr1, addColStr1, writeColStr1, scanColStr1, []string{"col_str_1"}, parquet.RequiredFieldCompression(compression)),
+		NewStringOptionalField(readColStr2, writeColStr2, []string{"col_str_2"}, []int{1}, parquet.OptionalFieldCompression(compression)),
+		NewStringField(readColStr3, addColStr3, writeColStr3, scanColStr3, []string{"col_str_3"}, parquet.RequiredFieldCompression(compression)),
+		NewStringOptionalField(readColStr4, writeColStr4, []string{"col_str_4"}, []int{1}, parquet.OptionalFieldCompression(compression)),
+		NewStringField(readColStr5, addColStr5, writeColStr5, scanColStr5, []string{"col_str_5"}, parquet.RequiredFieldCompression(compression)),
+		NewStringOptionalField(readColStr6, writeColStr6, []string{"col_str_6"}, []int{1}, parquet.OptionalFieldCompression(compression)),
+		NewStringField(readColStr7, addColStr7, writeColStr7, scanColStr7, []string{"col_str_7"}, parquet.RequiredFieldCompression(compression)),
+		NewStringOptionalField(readColStr8, writeColStr8, []string{"col_str_8"}, []int{1}, parquet.OptionalFieldCompression(compression)),
+		NewStringField(readColStr9, addColStr9, writeColStr9, scanColStr9, []string{"col_str_9"}, parquet.RequiredFieldCompression(compression)),
+		NewInt64OptionalField(readColInt0, writeColInt0, []string{"col_int_0"}, []int{1}, parquet.OptionalFieldCompression(compression)),
+		NewInt64Field(readColInt1, addColInt1, writeColInt1, scanColInt1, []string{"col_int_1"}, parquet.RequiredFieldCompression(compression)),
+		NewInt64OptionalField(readColInt2, writeColInt2, []string{"col_int_2"}, []int{1}, parquet.OptionalFieldCompression(compression)),
+		NewInt64Field(readColInt3, addColInt3, writeColInt3, scanColInt3, []string{"col_int_3"}, parquet.RequiredFieldCompression(compression)),
+		NewInt64OptionalField(readColInt4, writeColInt4, []string{"col_int_4"}, []int{1}, parquet.OptionalFieldCompression(compression)),
+		NewInt32OptionalField(readColInt32_0, writeColInt32_0, []string{"col_int_32_0"}, []int{1}, parquet.OptionalFieldCompression(compression)),
+		NewInt32Field(readColInt32_1, addColInt32_1, writeColInt32_1, scanColInt32_1, []string{"col_int_32_1"}, parquet.RequiredFieldCompression(compression)),
+		NewInt32OptionalField(readColInt32_2, writeColInt32_2, []string{"col_int_32_2"}, []int{1}, parquet.OptionalFieldCompression(compression)),
+		NewInt32Field(readColInt32_3, addColInt32_3, writeColInt32_3, scanColInt32_3, []string{"col_int_32_3"}, parquet.RequiredFieldCompression(compression)),
+		NewInt32OptionalField(readColInt32_4, writeColInt32_4, []string{"col_int_32_4"}, []int{1}, parquet.OptionalFieldCompression(compression)),
+		NewFloat64OptionalField(readColFloat0, writeColFloat0, []string{"col_float_0"}, []int{1}, parquet.OptionalFieldCompression(compression)),
+		NewFloat64Field(readColFloat1, addColFloat1, writeColFloat1, scanColFloat1, []string{"col_float_1"}, parquet.RequiredFieldCompression(compression)),
+		NewFloat64OptionalField(readColFloat2, writeColFloat2, []string{"col_float_2"}, []int{1}, parquet.OptionalFieldCompression(compression)),
+		NewFloat64Field(readColFloat3, addColFloat3, writeColFloat3, scanColFloat3, []string{"col_float_3"}, parquet.RequiredFieldCompression(compression)),
+		NewFloat64OptionalField(readColFloat4, writeColFloat4, []string{"col_float_4"}, []int{1}, parquet.OptionalFieldCompression(compression)),
+		NewFloat32OptionalField(readColFloat32_0, writeColFloat32_0, []string{"col_float_32_0"}, []int{1}, parquet.OptionalFieldCompression(compression)),
+		NewFloat32Field(readColFloat32_1, addColFloat32_1, writeColFloat32_1, scanColFloat32_1, []string{"col_float_32_1"}, parquet.RequiredFieldCompression(compression)),
+		NewFloat32OptionalField(readColFloat32_2, writeColFloat32_2, []string{"col_float_32_2"}, []int{1}, parquet.OptionalFieldCompression(compression)),
+		NewFloat32Field(readColFloat32_3, addColFloat32_3, writeColFloat32_3, scanColFloat32_3, []string{"col_float_32_3"}, parquet.RequiredFieldCompression(compression)),
+		NewFloat32OptionalField(readColFloat32_4, writeColFloat32_4, []string{"col_float_32_4"}, []int{1}, parquet.OptionalFieldCompression(compression)),
+		NewBoolOptionalField(readColBool0, writeColBool0, []string{"col_bool_0"}, []int{1}, parquet.OptionalFieldCompression(compression)),
+		NewBoolField(readColBool1, addColBool1, writeColBool1, scanColBool1, []string{"col_bool_1"}, parquet.RequiredFieldCompression(compression)),
+		NewBoolOptionalField(readColBool2, writeColBool2, []string{"col_bool_2"}, []int{1}, parquet.OptionalFieldCompression(compression)),
+		NewBoolField(readColBool3, addColBool3, writeColBool3, scanColBool3, []string{"col_bool_3"}, parquet.RequiredFieldCompression(compression)),
+		NewBoolOptionalField(readColBool4, writeColBool4, []string{"col_bool_4"}, []int{1}, parquet.OptionalFieldCompression(compression)),
+		NewBoolField(readColBool5, addColBool5, writeColBool5, scanColBool5, []string{"col_bool_5"}, parquet.RequiredFieldCompression(compression)),
+		NewBoolOptionalField(readColBool6, writeColBool6, []string{"col_bool_6"}, []int{1}, parquet.OptionalFieldCompression(compression)),
+		NewBoolField(readColBool7, addColBool7, writeColBool7, scanColBool7, []string{"col_bool_7"}, parquet.RequiredFieldCompression(compression)),
+		NewBoolOptionalField(readColBool8, writeColBool8, []string{"col_bool_8"}, []int{1}, parquet.OptionalFieldCompression(compression)),
+		NewBoolField(readColBool9, addColBool9, writeColBool9, scanColBool9, []string{"col_bool_9"}, parquet.RequiredFieldCompression(compression)),
 	}
 }
 
@@ -105,7 +131,7 @@ func writeColStr0(x *Message, vals []string, defs, reps []uint8) (int, int) {
 	def := defs[0]
 	switch def {
 	case 1:
-		x.ColStr0 = pstring(vals[0])
+		x.ColStr0 = parquet.StringPtr(vals[0])
 		return 1, 1
 	}
 
@@ -116,10 +142,25 @@ func readColStr1(x Message) string {
 	return x.ColStr1
 }
 
+func addColStr1(xs []Message, vals []string) []string {
+	n := len(vals)
+	vals = append(vals, make([]string, len(xs))...)
+	for i := range xs {
+		vals[n+i] = xs[i].ColStr1
+	}
+	return vals
+}
+
 func writeColStr1(x *Message, vals []string) {
 	x.ColStr1 = vals[0]
 }
 
+func scanColStr1(xs []Message, vals []string) {
+	for i := range xs {
+		xs[i].ColStr1 = vals[i]
+	}
+}
+
 func readColStr2(x Message, vals []string, defs, reps []uint8) ([]string, []uint8, []uint8) {
 	switch {
 	case x.ColStr2 == nil:
@@ -136,7 +177,7 @@ func writeColStr2(x *Message, vals []string, defs, reps []uint8) (int, int) {
 	def := defs[0]
 	switch def {
 	case 1:
-		x.ColStr2 = pstring(vals[0])
+		x.ColStr2 = parquet.StringPtr(vals[0])
 		return 1, 1
 	}
 
@@ -147,10 +188,25 @@ func readColStr3(x Message) string {
 	return x.ColStr3
 }
 
+func addColStr3(xs []Message, vals []string) []string {
+	n := len(vals)
+	vals = append(vals, make([]string, len(xs))...)
+	for i := range xs {
+		vals[n+i] = xs[i].ColStr3
+	}
+	return vals
+}
+
 func writeColStr3(x *Message, vals []string) {
 	x.ColStr3 = vals[0]
 }
 
+func scanColStr3(xs []Message, vals []string) {
+	for i := range xs {
+		xs[i].ColStr3 = vals[i]
+	}
+}
+
 func readColStr4(x Message, vals []string, defs, reps []uint8) ([]string, []uint8, []uint8) {
 	switch {
 	case x.ColStr4 == nil:
@@ -167,7 +223,7 @@ func writeColStr4(x *Message, vals []string, defs, reps []uint8) (int, int) {
 	def := defs[0]
 	switch def {
 	case 1:
-		x.ColStr4 = pstring(vals[0])
+		x.ColStr4 = parquet.StringPtr(vals[0])
 		return 1, 1
 	}
 
@@ -178,10 +234,25 @@ func readColStr5(x Message) string {
 	return x.ColStr5
 }
 
+func addColStr5(xs []Message, vals []string) []string {
+	n := len(vals)
+	vals = append(vals, make([]string, len(xs))...)
+	for i := range xs {
+		vals[n+i] = xs[i].ColStr5
+	}
+	return vals
+}
+
 func writeColStr5(x *Message, vals []string) {
 	x.ColStr5 = vals[0]
 }
 
+func scanColStr5(xs []Message, vals []string) {
+	for i := range xs {
+		xs[i].ColStr5 = vals[i]
+	}
+}
+
 func readColStr6(x Message, vals []string, defs, reps []uint8) ([]string, []uint8, []uint8) {
 	switch {
 	case x.ColStr6 == nil:
@@ -198,7 +269,7 @@ func writeColStr6(x *Message, vals []string, defs, reps []uint8) (int, int) {
 	def := defs[0]
 	switch def {
 	case 1:
-		x.ColStr6 = pstring(vals[0])
+		x.ColStr6 = parquet.StringPtr(vals[0])
 		return 1, 1
 	}
 
@@ -209,10 +280,25 @@ func readColStr7(x Message) string {
 	return x.ColStr7
 }
 
+func addColStr7(xs []Message, vals []string) []string {
+	n := len(vals)
+	vals = append(vals, make([]string, len(xs))...)
+	for i := range xs {
+		vals[n+i] = xs[i].ColStr7
+	}
+	return vals
+}
+
 func writeColStr7(x *Message, vals []string) {
 	x.ColStr7 = vals[0]
 }
 
+func scanColStr7(xs []Message, vals []string) {
+	for i := range xs {
+		xs[i].ColStr7 = vals[i]
+	}
+}
+
 func readColStr8(x Message, vals []string, defs, reps []uint8) ([]string, []uint8, []uint8) {
 	switch {
 	case x.ColStr8 == nil:
@@ -229,7 +315,7 @@ func writeColStr8(x *Message, vals []string, defs, reps []uint8) (int, int) {
 	def := defs[0]
 	switch def {
 	case 1:
-		x.ColStr8 = pstring(vals[0])
+		x.ColStr8 = parquet.StringPtr(vals[0])
 		return 1, 1
 	}
 
@@ -240,10 +326,25 @@ func readColStr9(x Message) string {
 	return x.ColStr9
 }
 
+func addColStr9(xs []Message, vals []string) []string {
+	n := len(vals)
+	vals = append(vals, make([]string, len(xs))...)
+	for i := range xs {
+		vals[n+i] = xs[i].ColStr9
+	}
+	return vals
+}
+
 func writeColStr9(x *Message, vals []string) {
 	x.ColStr9 = vals[0]
 }
 
+func scanColStr9(xs []Message, vals []string) {
+	for i := range xs {
+		xs[i].ColStr9 = vals[i]
+	}
+}
+
 func readColInt0(x Message, vals []int64, defs, reps []uint8) ([]int64, []uint8, []uint8) {
 	switch {
 	case x.ColInt0 == nil:
@@ -260,7 +361,7 @@ func writeColInt0(x *Message, vals []int64, defs, reps []uint8) (int, int) {
 	def := defs[0]
 	switch def {
 	case 1:
-		x.ColInt0 = pint64(vals[0])
+		x.ColInt0 = parquet.Int64Ptr(vals[0])
 		return 1, 1
 	}
 
@@ -271,10 +372,25 @@ func readColInt1(x Message) int64 {
 	return x.ColInt1
 }
 
+func addColInt1(xs []Message, vals []int64) []int64 {
+	n := len(vals)
+	vals = append(vals, make([]int64, len(xs))...)
+	for i := range xs {
+		vals[n+i] = xs[i].ColInt1
+	}
+	return vals
+}
+
 func writeColInt1(x *Message, vals []int64) {
 	x.ColInt1 = vals[0]
 }
 
+func scanColInt1(xs []Message, vals []int64) {
+	for i := range xs {
+		xs[i].ColInt1 = vals[i]
+	}
+}
+
 func readColInt2(x Message, vals []int64, defs, reps []uint8) ([]int64, []uint8, []uint8) {
 	switch {
 	case x.ColInt2 == nil:
@@ -291,7 +407,7 @@ func writeColInt2(x *Message, vals []int64, defs, reps []uint8) (int, int) {
 	def := defs[0]
 	switch def {
 	case 1:
-		x.ColInt2 = pint64(vals[0])
+		x.ColInt2 = parquet.Int64Ptr(vals[0])
 		return 1, 1
 	}
 
@@ -302,10 +418,25 @@ func readColInt3(x Message) int64 {
 	return x.ColInt3
 }
 
+func addColInt3(xs []Message, vals []int64) []int64 {
+	n := len(vals)
+	vals = append(vals, make([]int64, len(xs))...)
+	for i := range xs {
+		vals[n+i] = xs[i].ColInt3
+	}
+	return vals
+}
+
 func writeColInt3(x *Message, vals []int64) {
 	x.ColInt3 = vals[0]
 }
 
+func scanColInt3(xs []Message, vals []int64) {
+	for i := range xs {
+		xs[i].ColInt3 = vals[i]
+	}
+}
+
 func readColInt4(x Message, vals []int64, defs, reps []uint8) ([]int64, []uint8, []uint8) {
 	switch {
 	case x.ColInt4 == nil:
@@ -322,7 +453,7 @@ func writeColInt4(x *Message, vals []int64, defs, reps []uint8) (int, int) {
 	def := defs[0]
 	switch def {
 	case 1:
-		x.ColInt4 = pint64(vals[0])
+		x.ColInt4 = parquet.Int64Ptr(vals[0])
 		return 1, 1
 	}
 
@@ -345,7 +476,7 @@ func writeColInt32_0(x *Message, vals []int32, defs, reps []uint8) (int, int) {
 	def := defs[0]
 	switch def {
 	case 1:
-		x.ColInt32_0 = pint32(vals[0])
+		x.ColInt32_0 = parquet.Int32Ptr(vals[0])
 		return 1, 1
 	}
 
@@ -356,10 +487,25 @@ func readColInt32_1(x Message) int32 {
 	return x.ColInt32_1
 }
 
+func addColInt32_1(xs []Message, vals []int32) []int32 {
+	n := len(vals)
+	vals = append(vals, make([]int32, len(xs))...)
+	for i := range xs {
+		vals[n+i] = xs[i].ColInt32_1
+	}
+	return vals
+}
+
 func writeColInt32_1(x *Message, vals []int32) {
 	x.ColInt32_1 = vals[0]
 }
 
+func scanColInt32_1(xs []Message, vals []int32) {
+	for i := range xs {
+		xs[i].ColInt32_1 = vals[i]
+	}
+}
+
 func readColInt32_2(x Message, vals []int32, defs, reps []uint8) ([]int32, []uint8, []uint8) {
 	switch {
 	case x.ColInt32_2 == nil:
@@ -376,7 +522,7 @@ func writeColInt32_2(x *Message, vals []int32, defs, reps []uint8) (int, int) {
 	def := defs[0]
 	switch def {
 	case 1:
-		x.ColInt32_2 = pint32(vals[0])
+		x.ColInt32_2 = parquet.Int32Ptr(vals[0])
 		return 1, 1
 	}
 
@@ -387,10 +533,25 @@ func readColInt32_3(x Message) int32 {
 	return x.ColInt32_3
 }
 
+func addColInt32_3(xs []Message, vals []int32) []int32 {
+	n := len(vals)
+	vals = append(vals, make([]int32, len(xs))...)
+	for i := range xs {
+		vals[n+i] = xs[i].ColInt32_3
+	}
+	return vals
+}
+
 func writeColInt32_3(x *Message, vals []int32) {
 	x.ColInt32_3 = vals[0]
 }
 
+func scanColInt32_3(xs []Message, vals []int32) {
+	for i := range xs {
+		xs[i].ColInt32_3 = vals[i]
+	}
+}
+
 func readColInt32_4(x Message, vals []int32, defs, reps []uint8) ([]int32, []uint8, []uint8) {
 	switch {
 	case x.ColInt32_4 == nil:
@@ -407,7 +568,7 @@ func writeColInt32_4(x *Message, vals []int32, defs, reps []uint8) (int, int) {
 	def := defs[0]
 	switch def {
 	case 1:
-		x.ColInt32_4 = pint32(vals[0])
+		x.ColInt32_4 = parquet.Int32Ptr(vals[0])
 		return 1, 1
 	}
 
@@ -430,7 +591,7 @@ func writeColFloat0(x *Message, vals []float64, defs, reps []uint8) (int, int) {
 	def := defs[0]
 	switch def {
 	case 1:
-		x.ColFloat0 = pfloat64(vals[0])
+		x.ColFloat0 = parquet.Float64Ptr(vals[0])
 		return 1, 1
 	}
 
@@ -441,10 +602,25 @@ func readColFloat1(x Message) float64 {
 	return x.ColFloat1
 }
 
+func addColFloat1(xs []Message, vals []float64) []float64 {
+	n := len(vals)
+	vals = append(vals, make([]float64, len(xs))...)
+	for i := range xs {
+		vals[n+i] = xs[i].ColFloat1
+	}
+	return vals
+}
+
 func writeColFloat1(x *Message, vals []float64) {
 	x.ColFloat1 = vals[0]
 }
 
+func scanColFloat1(xs []Message, vals []float64) {
+	for i := range xs {
+		xs[i].ColFloat1 = vals[i]
+	}
+}
+
 func readColFloat2(x Message, vals []float64, defs, reps []uint8) ([]float64, []uint8, []uint8) {
 	switch {
 	case x.ColFloat2 == nil:
@@ -461,7 +637,7 @@ func writeColFloat2(x *Message, vals []float64, defs, reps []uint8) (int, int) {
 	def := defs[0]
 	switch def {
 	case 1:
-		x.ColFloat2 = pfloat64(vals[0])
+		x.ColFloat2 = parquet.Float64Ptr(vals[0])
 		return 1, 1
 	}
 
@@ -472,10 +648,25 @@ func readColFloat3(x Message) float64 {
 	return x.ColFloat3
 }
 
+func addColFloat3(xs []Message, vals []float64) []float64 {
+	n := len(vals)
+	vals = append(vals, make([]float64, len(xs))...)
+	for i := range xs {
+		vals[n+i] = xs[i].ColFloat3
+	}
+	return vals
+}
+
 func writeColFloat3(x *Message, vals []float64) {
 	x.ColFloat3 = vals[0]
 }
 
+func scanColFloat3(xs []Message, vals []float64) {
+	for i := range xs {
+		xs[i].ColFloat3 = vals[i]
+	}
+}
+
 func readColFloat4(x Message, vals []float64, defs, reps []uint8) ([]float64, []uint8, []uint8) {
 	switch {
 	case x.ColFloat4 == nil:
@@ -492,7 +683,7 @@ func writeColFloat4(x *Message, vals []float64, defs, reps []uint8) (int, int) {
 	def := defs[0]
 	switch def {
 	case 1:
-		x.ColFloat4 = pfloat64(vals[0])
+		x.ColFloat4 = parquet.Float64Ptr(vals[0])
 		return 1, 1
 	}
 
@@ -515,7 +706,7 @@ func writeColFloat32_0(x *Message, vals []float32, defs, reps []uint8) (int, int
 	def := defs[0]
 	switch def {
 	case 1:
-		x.ColFloat32_0 = pfloat32(vals[0])
+		x.ColFloat32_0 = parquet.Float32Ptr(vals[0])
 		return 1, 1
 	}
 
@@ -526,10 +717,25 @@ func readColFloat32_1(x Message) float32 {
 	return x.ColFloat32_1
 }
 
+func addColFloat32_1(xs []Message, vals []float32) []float32 {
+	n := len(vals)
+	vals = append(vals, make([]float32, len(xs))...)
+	for i := range xs {
+		vals[n+i] = xs[i].ColFloat32_1
+	}
+	return vals
+}
+
 func writeColFloat32_1(x *Message, vals []float32) {
 	x.ColFloat32_1 = vals[0]
 }
 
+func scanColFloat32_1(xs []Message, vals []float32) {
+	for i := range xs {
+		xs[i].ColFloat32_1 = vals[i]
+	}
+}
+
 func readColFloat32_2(x Message, vals []float32, defs, reps []uint8) ([]float32, []uint8, []uint8) {
 	switch {
 	case x.ColFloat32_2 == nil:
@@ -546,7 +752,7 @@ func writeColFloat32_2(x *Message, vals []float32, defs, reps []uint8) (int, int
 	def := defs[0]
 	switch def {
 	case 1:
-		x.ColFloat32_2 = pfloat32(vals[0])
+		x.ColFloat32_2 = parquet.Float32Ptr(vals[0])
 		return 1, 1
 	}
 
@@ -557,10 +763,25 @@ func readColFloat32_3(x Message) float32 {
 	return x.ColFloat32_3
 }
 
+func addColFloat32_3(xs []Message, vals []float32) []float32 {
+	n := len(vals)
+	vals = append(vals, make([]float32, len(xs))...)
+	for i := range xs {
+		vals[n+i] = xs[i].ColFloat32_3
+	}
+	return vals
+}
+
 func writeColFloat32_3(x *Message, vals []float32) {
 	x.ColFloat32_3 = vals[0]
 }
 
+func scanColFloat32_3(xs []Message, vals []float32) {
+	for i := range xs {
+		xs[i].ColFloat32_3 = vals[i]
+	}
+}
+
 func readColFloat32_4(x Message, vals []float32, defs, reps []uint8) ([]float32, []uint8, []uint8) {
 	switch {
 	case x.ColFloat32_4 == nil:
@@ -577,7 +798,7 @@ func writeColFloat32_4(x *Message, vals []float32, defs, reps []uint8) (int, int
 	def := defs[0]
 	switch def {
 	case 1:
-		x.ColFloat32_4 = pfloat32(vals[0])
+		x.ColFloat32_4 = parquet.Float32Ptr(vals[0])
 		return 1, 1
 	}
 
@@ -600,7 +821,7 @@ func writeColBool0(x *Message, vals []bool, defs, reps []uint8) (int, int) {
 	def := defs[0]
 	switch def {
 	case 1:
-		x.ColBool0 = pbool(vals[0])
+		x.ColBool0 = parquet.BoolPtr(vals[0])
 		return 1, 1
 	}
 
@@ -611,10 +832,25 @@ func readColBool1(x Message) bool {
 	return x.ColBool1
 }
 
+func addColBool1(xs []Message, vals []bool) []bool {
+	n := len(vals)
+	vals = append(vals, make([]bool, len(xs))...)
+	for i := range xs {
+		vals[n+i] = xs[i].ColBool1
+	}
+	return vals
+}
+
 func writeColBool1(x *Message, vals []bool) {
 	x.ColBool1 = vals[0]
 }
 
+func scanColBool1(xs []Message, vals []bool) {
+	for i := range xs {
+		xs[i].ColBool1 = vals[i]
+	}
+}
+
 func readColBool2(x Message, vals []bool, defs, reps []uint8) ([]bool, []uint8, []uint8) {
 	switch {
 	case x.ColBool2 == nil:
@@ -631,7 +867,7 @@ func writeColBool2(x *Message, vals []bool, defs, reps []uint8) (int, int) {
 	def := defs[0]
 	switch def {
 	case 1:
-		x.ColBool2 = pbool(vals[0])
+		x.ColBool2 = parquet.BoolPtr(vals[0])
 		return 1, 1
 	}
 
@@ -642,10 +878,25 @@ func readColBool3(x Message) bool {
 	return x.ColBool3
 }
 
+func addColBool3(xs []Message, vals []bool) []bool {
+	n := len(vals)
+	vals = append(vals, make([]bool, len(xs))...)
+	for i := range xs {
+		vals[n+i] = xs[i].ColBool3
+	}
+	return vals
+}
+
 func writeColBool3(x *Message, vals []bool) {
 	x.ColBool3 = vals[0]
 }
 
+func scanColBool3(xs []Message, vals []bool) {
+	for i := range xs {
+		xs[i].ColBool3 = vals[i]
+	}
+}
+
 func readColBool4(x Message, vals []bool, defs, reps []uint8) ([]bool, []uint8, []uint8) {
 	switch {
 	case x.ColBool4 == nil:
@@ -662,7 +913,7 @@ func writeColBool4(x *Message, vals []bool, defs, reps []uint8) (int, int) {
 	def := defs[0]
 	switch def {
 	case 1:
-		x.ColBool4 = pbool(vals[0])
+		x.ColBool4 = parquet.BoolPtr(vals[0])
 		return 1, 1
 	}
 
@@ -673,10 +924,25 @@ func readColBool5(x Message) bool {
 	return x.ColBool5
 }
 
+func addColBool5(xs []Message, vals []bool) []bool {
+	n := len(vals)
+	vals = append(vals, make([]bool, len(xs))...)
+	for i := range xs {
+		vals[n+i] = xs[i].ColBool5
+	}
+	return vals
+}
+
 func writeColBool5(x *Message, vals []bool) {
 	x.ColBool5 = vals[0]
 }
 
+func scanColBool5(xs []Message, vals []bool) {
+	for i := range xs {
+		xs[i].ColBool5 = vals[i]
+	}
+}
+
 func readColBool6(x Message, vals []bool, defs, reps []uint8) ([]bool, []uint8, []uint8) {
 	switch {
 	case x.ColBool6 == nil:
@@ -693,7 +959,7 @@ func writeColBool6(x *Message, vals []bool, defs, reps []uint8) (int, int) {
 	def := defs[0]
 	switch def {
 	case 1:
-		x.ColBool6 = pbool(vals[0])
+		x.ColBool6 = parquet.BoolPtr(vals[0])
 		return 1, 1
 	}
 
@@ -704,10 +970,25 @@ func readColBool7(x Message) bool {
 	return x.ColBool7
 }
 
+func addColBool7(xs []Message, vals []bool) []bool {
+	n := len(vals)
+	vals = append(vals, make([]bool, len(xs))...)
+	for i := range xs {
+		vals[n+i] = xs[i].ColBool7
+	}
+	return vals
+}
+
 func writeColBool7(x *Message, vals []bool) {
 	x.ColBool7 = vals[0]
 }
 
+func scanColBool7(xs []Message, vals []bool) {
+	for i := range xs {
+		xs[i].ColBool7 = vals[i]
+	}
+}
+
 func readColBool8(x Message, vals []bool, defs, reps []uint8) ([]bool, []uint8, []uint8) {
 	switch {
 	case x.ColBool8 == nil:
@@ -724,7 +1005,7 @@ func writeColBool8(x *Message, vals []bool, defs, reps []uint8) (int, int) {
 	def := defs[0]
 	switch def {
 	case 1:
-		x.ColBool8 = pbool(vals[0])
+		x.ColBool8 = parquet.BoolPtr(vals[0])
 		return 1, 1
 	}
 
@@ -735,33 +1016,22 @@ func readColBool9(x Message) bool {
 	return x.ColBool9
 }
 
-func writeColBool9(x *Message, vals []bool) {
-	x.ColBool9 = vals[0]
+func addColBool9(xs []Message, vals []bool) []bool {
+	n := len(vals)
+	vals = append(vals, make([]bool, len(xs))...)
+	for i := range xs {
+		vals[n+i] = xs[i].ColBool9
+	}
+	return vals
 }
 
-func fieldCompression(c compression) func(*parquet.RequiredField) {
-	switch c {
-	case compressionUncompressed:
-		return parquet.RequiredFieldUncompressed
-	case compressionSnappy:
-		return parquet.RequiredFieldSnappy
-	case compressionGzip:
-		return parquet.RequiredFieldGzip
-	default:
-		return parquet.RequiredFieldUncompressed
-	}
+func writeColBool9(x *Message, vals []bool) {
+	x.ColBool9 = vals[0]
 }
 
-func optionalFieldCompression(c compression) func(*parquet.OptionalField) {
-	switch c {
-	case compressionUncompressed:
-		return parquet.OptionalFieldUncompressed
-	case compressionSnappy:
-		return parquet.OptionalFieldSnappy
-	case compressionGzip:
-		return parquet.OptionalFieldGzip
-	default:
-		return parquet.OptionalFieldUncompressed
+func scanColBool9(xs []Message, vals []bool) {
+	for i := range xs {
+		xs[i].ColBool9 = vals[i]
 	}
 }
 
@@ -769,11 +1039,40 @@ func NewParquetWriter(w io.Writer, opts ...func(*ParquetWriter) error) (*Parquet
 	return newParquetWriter(w, append(opts, begin)...)
 }
 
+// NewParquetAppender creates a writer that adds row groups to the
+// existing parquet file f.  It reads f's footer, checks that the file's
+// schema matches Message's and then writes the new row groups over the
+// footer.  Close writes a footer that has both the existing and the new
+// row groups and truncates f to the end of it, so f must also have a
+// Truncate(int64) error method (like *os.File).
+func NewParquetAppender(f io.ReadWriteSeeker, opts ...func(*ParquetWriter) error) (*ParquetWriter, error) {
+	t, ok := f.(truncater)
+	if !ok {
+		return nil, fmt.Errorf("appending requires a file that can be truncated, got %T", f)
+	}
+
+	p, err := newParquetWriter(f, opts...)
+	if err != nil {
+		return nil, err
+	}
+	p.appended = t
+
+	offset, err := p.meta.AppendTo(f)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
 func newParquetWriter(w io.Writer, opts ...func(*ParquetWriter) error) (*ParquetWriter, error) {
 	p := &ParquetWriter{
 		max:         1000,
 		w:           w,
-		compression: compressionSnappy,
+		compression: sch.CompressionCodec_SNAPPY,
 	}
 
 	for _, opt := range opts {
@@ -790,6 +1089,25 @@ func newParquetWriter(w io.Writer, opts ...func(*ParquetWriter) error) (*Parquet
 			schema[i] = f.Schema()
 		}
 		p.meta = parquet.New(schema...)
+		if p.checksums {
+			p.meta.EnableChecksums()
+		}
+		for col, enc := range p.encodings {
+			if err := p.meta.SetEncoding(col, enc); err != nil {
+				return nil, err
+			}
+		}
+		for _, kv := range p.keyValues {
+			p.meta.SetKeyValue(kv[0], kv[1])
+		}
+		if p.createdBy != nil {
+			p.meta.SetCreatedBy(*p.createdBy)
+		}
+		if len(p.sortBy) > 0 {
+			if err := p.meta.SetSortingColumns(p.sortBy...); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	return p, nil
@@ -803,10 +1121,569 @@ func MaxPageSize(m int) func(*ParquetWriter) error {
 	}
 }
 
-var par1 = []byte("PAR1")
+// Concurrency sets the number of columns that are encoded and
+// compressed in parallel by Write.  Each column is written to its own
+// buffer and the buffers are then written in the order of the schema.
+func Concurrency(n int) func(*ParquetWriter) error {
+	return func(p *ParquetWriter) error {
+		if n < 1 {
+			return fmt.Errorf("invalid concurrency: %d", n)
+		}
+		p.concurrency = n
+		return nil
+	}
+}
+
+// Checksums adds a CRC32 of each page's data to its page header
+// so that readers can detect corrupt pages.
+func Checksums(p *ParquetWriter) error {
+	p.checksums = true
+	return nil
+}
+
+// Encoding sets the encoding of column's values (column is the
+// name of the column's fields, separated by dots).  NewParquetWriter
+// returns an error if the column doesn't exist or if its type can't be
+// encoded with enc.
+func Encoding(column string, enc sch.Encoding) func(*ParquetWriter) error {
+	return func(p *ParquetWriter) error {
+		if p.encodings == nil {
+			p.encodings = map[string]sch.Encoding{}
+		}
+		p.encodings[column] = enc
+		return nil
+	}
+}
+
+// KeyValue adds a key/value pair to the metadata in the
+// file's footer.
+func KeyValue(key, value string) func(*ParquetWriter) error {
+	return func(p *ParquetWriter) error {
+		p.keyValues = append(p.keyValues, [2]string{key, value})
+		return nil
+	}
+}
+
+// CreatedBy sets the application that wrote the file (the
+// footer's created_by).
+func CreatedBy(createdBy string) func(*ParquetWriter) error {
+	return func(p *ParquetWriter) error {
+		p.createdBy = &createdBy
+		return nil
+	}
+}
+
+// SortBy makes Write sort the records of each row group by columns
+// (in ascending order, with missing values first) before they are encoded.
+// The sort order is recorded in the row groups' sorting_columns.  Repeated
+// columns can't be sorted by.
+func SortBy(columns ...string) func(*ParquetWriter) error {
+	return func(p *ParquetWriter) error {
+		for _, col := range columns {
+			cmp, ok := compareFuncs[col]
+			if !ok {
+				return fmt.Errorf("can't sort by column %s, it doesn't exist or is repeated", col)
+			}
+			p.compares = append(p.compares, cmp)
+		}
+		p.sortBy = columns
+		return nil
+	}
+}
+
+var compareFuncs = map[string]func(a, b Message) int{
+	"col_str_0": func(a, b Message) int {
+		xs, _, _ := readColStr0(a, nil, nil, nil)
+		ys, _, _ := readColStr0(b, nil, nil, nil)
+		if len(xs) == 0 || len(ys) == 0 {
+			return len(xs) - len(ys)
+		}
+		switch {
+		case xs[0] < ys[0]:
+			return -1
+		case xs[0] > ys[0]:
+			return 1
+		}
+		return 0
+	},
+	"col_str_1": func(a, b Message) int {
+		x, y := readColStr1(a), readColStr1(b)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	},
+	"col_str_2": func(a, b Message) int {
+		xs, _, _ := readColStr2(a, nil, nil, nil)
+		ys, _, _ := readColStr2(b, nil, nil, nil)
+		if len(xs) == 0 || len(ys) == 0 {
+			return len(xs) - len(ys)
+		}
+		switch {
+		case xs[0] < ys[0]:
+			return -1
+		case xs[0] > ys[0]:
+			return 1
+		}
+		return 0
+	},
+	"col_str_3": func(a, b Message) int {
+		x, y := readColStr3(a), readColStr3(b)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	},
+	"col_str_4": func(a, b Message) int {
+		xs, _, _ := readColStr4(a, nil, nil, nil)
+		ys, _, _ := readColStr4(b, nil, nil, nil)
+		if len(xs) == 0 || len(ys) == 0 {
+			return len(xs) - len(ys)
+		}
+		switch {
+		case xs[0] < ys[0]:
+			return -1
+		case xs[0] > ys[0]:
+			return 1
+		}
+		return 0
+	},
+	"col_str_5": func(a, b Message) int {
+		x, y := readColStr5(a), readColStr5(b)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	},
+	"col_str_6": func(a, b Message) int {
+		xs, _, _ := readColStr6(a, nil, nil, nil)
+		ys, _, _ := readColStr6(b, nil, nil, nil)
+		if len(xs) == 0 || len(ys) == 0 {
+			return len(xs) - len(ys)
+		}
+		switch {
+		case xs[0] < ys[0]:
+			return -1
+		case xs[0] > ys[0]:
+			return 1
+		}
+		return 0
+	},
+	"col_str_7": func(a, b Message) int {
+		x, y := readColStr7(a), readColStr7(b)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	},
+	"col_str_8": func(a, b Message) int {
+		xs, _, _ := readColStr8(a, nil, nil, nil)
+		ys, _, _ := readColStr8(b, nil, nil, nil)
+		if len(xs) == 0 || len(ys) == 0 {
+			return len(xs) - len(ys)
+		}
+		switch {
+		case xs[0] < ys[0]:
+			return -1
+		case xs[0] > ys[0]:
+			return 1
+		}
+		return 0
+	},
+	"col_str_9": func(a, b Message) int {
+		x, y := readColStr9(a), readColStr9(b)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	},
+	"col_int_0": func(a, b Message) int {
+		xs, _, _ := readColInt0(a, nil, nil, nil)
+		ys, _, _ := readColInt0(b, nil, nil, nil)
+		if len(xs) == 0 || len(ys) == 0 {
+			return len(xs) - len(ys)
+		}
+		switch {
+		case xs[0] < ys[0]:
+			return -1
+		case xs[0] > ys[0]:
+			return 1
+		}
+		return 0
+	},
+	"col_int_1": func(a, b Message) int {
+		x, y := readColInt1(a), readColInt1(b)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	},
+	"col_int_2": func(a, b Message) int {
+		xs, _, _ := readColInt2(a, nil, nil, nil)
+		ys, _, _ := readColInt2(b, nil, nil, nil)
+		if len(xs) == 0 || len(ys) == 0 {
+			return len(xs) - len(ys)
+		}
+		switch {
+		case xs[0] < ys[0]:
+			return -1
+		case xs[0] > ys[0]:
+			return 1
+		}
+		return 0
+	},
+	"col_int_3": func(a, b Message) int {
+		x, y := readColInt3(a), readColInt3(b)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	},
+	"col_int_4": func(a, b Message) int {
+		xs, _, _ := readColInt4(a, nil, nil, nil)
+		ys, _, _ := readColInt4(b, nil, nil, nil)
+		if len(xs) == 0 || len(ys) == 0 {
+			return len(xs) - len(ys)
+		}
+		switch {
+		case xs[0] < ys[0]:
+			return -1
+		case xs[0] > ys[0]:
+			return 1
+		}
+		return 0
+	},
+	"col_int_32_0": func(a, b Message) int {
+		xs, _, _ := readColInt32_0(a, nil, nil, nil)
+		ys, _, _ := readColInt32_0(b, nil, nil, nil)
+		if len(xs) == 0 || len(ys) == 0 {
+			return len(xs) - len(ys)
+		}
+		switch {
+		case xs[0] < ys[0]:
+			return -1
+		case xs[0] > ys[0]:
+			return 1
+		}
+		return 0
+	},
+	"col_int_32_1": func(a, b Message) int {
+		x, y := readColInt32_1(a), readColInt32_1(b)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	},
+	"col_int_32_2": func(a, b Message) int {
+		xs, _, _ := readColInt32_2(a, nil, nil, nil)
+		ys, _, _ := readColInt32_2(b, nil, nil, nil)
+		if len(xs) == 0 || len(ys) == 0 {
+			return len(xs) - len(ys)
+		}
+		switch {
+		case xs[0] < ys[0]:
+			return -1
+		case xs[0] > ys[0]:
+			return 1
+		}
+		return 0
+	},
+	"col_int_32_3": func(a, b Message) int {
+		x, y := readColInt32_3(a), readColInt32_3(b)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	},
+	"col_int_32_4": func(a, b Message) int {
+		xs, _, _ := readColInt32_4(a, nil, nil, nil)
+		ys, _, _ := readColInt32_4(b, nil, nil, nil)
+		if len(xs) == 0 || len(ys) == 0 {
+			return len(xs) - len(ys)
+		}
+		switch {
+		case xs[0] < ys[0]:
+			return -1
+		case xs[0] > ys[0]:
+			return 1
+		}
+		return 0
+	},
+	"col_float_0": func(a, b Message) int {
+		xs, _, _ := readColFloat0(a, nil, nil, nil)
+		ys, _, _ := readColFloat0(b, nil, nil, nil)
+		if len(xs) == 0 || len(ys) == 0 {
+			return len(xs) - len(ys)
+		}
+		switch {
+		case xs[0] < ys[0]:
+			return -1
+		case xs[0] > ys[0]:
+			return 1
+		}
+		return 0
+	},
+	"col_float_1": func(a, b Message) int {
+		x, y := readColFloat1(a), readColFloat1(b)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	},
+	"col_float_2": func(a, b Message) int {
+		xs, _, _ := readColFloat2(a, nil, nil, nil)
+		ys, _, _ := readColFloat2(b, nil, nil, nil)
+		if len(xs) == 0 || len(ys) == 0 {
+			return len(xs) - len(ys)
+		}
+		switch {
+		case xs[0] < ys[0]:
+			return -1
+		case xs[0] > ys[0]:
+			return 1
+		}
+		return 0
+	},
+	"col_float_3": func(a, b Message) int {
+		x, y := readColFloat3(a), readColFloat3(b)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	},
+	"col_float_4": func(a, b Message) int {
+		xs, _, _ := readColFloat4(a, nil, nil, nil)
+		ys, _, _ := readColFloat4(b, nil, nil, nil)
+		if len(xs) == 0 || len(ys) == 0 {
+			return len(xs) - len(ys)
+		}
+		switch {
+		case xs[0] < ys[0]:
+			return -1
+		case xs[0] > ys[0]:
+			return 1
+		}
+		return 0
+	},
+	"col_float_32_0": func(a, b Message) int {
+		xs, _, _ := readColFloat32_0(a, nil, nil, nil)
+		ys, _, _ := readColFloat32_0(b, nil, nil, nil)
+		if len(xs) == 0 || len(ys) == 0 {
+			return len(xs) - len(ys)
+		}
+		switch {
+		case xs[0] < ys[0]:
+			return -1
+		case xs[0] > ys[0]:
+			return 1
+		}
+		return 0
+	},
+	"col_float_32_1": func(a, b Message) int {
+		x, y := readColFloat32_1(a), readColFloat32_1(b)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	},
+	"col_float_32_2": func(a, b Message) int {
+		xs, _, _ := readColFloat32_2(a, nil, nil, nil)
+		ys, _, _ := readColFloat32_2(b, nil, nil, nil)
+		if len(xs) == 0 || len(ys) == 0 {
+			return len(xs) - len(ys)
+		}
+		switch {
+		case xs[0] < ys[0]:
+			return -1
+		case xs[0] > ys[0]:
+			return 1
+		}
+		return 0
+	},
+	"col_float_32_3": func(a, b Message) int {
+		x, y := readColFloat32_3(a), readColFloat32_3(b)
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+		return 0
+	},
+	"col_float_32_4": func(a, b Message) int {
+		xs, _, _ := readColFloat32_4(a, nil, nil, nil)
+		ys, _, _ := readColFloat32_4(b, nil, nil, nil)
+		if len(xs) == 0 || len(ys) == 0 {
+			return len(xs) - len(ys)
+		}
+		switch {
+		case xs[0] < ys[0]:
+			return -1
+		case xs[0] > ys[0]:
+			return 1
+		}
+		return 0
+	},
+	"col_bool_0": func(a, b Message) int {
+		xs, _, _ := readColBool0(a, nil, nil, nil)
+		ys, _, _ := readColBool0(b, nil, nil, nil)
+		if len(xs) == 0 || len(ys) == 0 {
+			return len(xs) - len(ys)
+		}
+		switch {
+		case !xs[0] && ys[0]:
+			return -1
+		case xs[0] && !ys[0]:
+			return 1
+		}
+		return 0
+	},
+	"col_bool_1": func(a, b Message) int {
+		x, y := readColBool1(a), readColBool1(b)
+		switch {
+		case !x && y:
+			return -1
+		case x && !y:
+			return 1
+		}
+		return 0
+	},
+	"col_bool_2": func(a, b Message) int {
+		xs, _, _ := readColBool2(a, nil, nil, nil)
+		ys, _, _ := readColBool2(b, nil, nil, nil)
+		if len(xs) == 0 || len(ys) == 0 {
+			return len(xs) - len(ys)
+		}
+		switch {
+		case !xs[0] && ys[0]:
+			return -1
+		case xs[0] && !ys[0]:
+			return 1
+		}
+		return 0
+	},
+	"col_bool_3": func(a, b Message) int {
+		x, y := readColBool3(a), readColBool3(b)
+		switch {
+		case !x && y:
+			return -1
+		case x && !y:
+			return 1
+		}
+		return 0
+	},
+	"col_bool_4": func(a, b Message) int {
+		xs, _, _ := readColBool4(a, nil, nil, nil)
+		ys, _, _ := readColBool4(b, nil, nil, nil)
+		if len(xs) == 0 || len(ys) == 0 {
+			return len(xs) - len(ys)
+		}
+		switch {
+		case !xs[0] && ys[0]:
+			return -1
+		case xs[0] && !ys[0]:
+			return 1
+		}
+		return 0
+	},
+	"col_bool_5": func(a, b Message) int {
+		x, y := readColBool5(a), readColBool5(b)
+		switch {
+		case !x && y:
+			return -1
+		case x && !y:
+			return 1
+		}
+		return 0
+	},
+	"col_bool_6": func(a, b Message) int {
+		xs, _, _ := readColBool6(a, nil, nil, nil)
+		ys, _, _ := readColBool6(b, nil, nil, nil)
+		if len(xs) == 0 || len(ys) == 0 {
+			return len(xs) - len(ys)
+		}
+		switch {
+		case !xs[0] && ys[0]:
+			return -1
+		case xs[0] && !ys[0]:
+			return 1
+		}
+		return 0
+	},
+	"col_bool_7": func(a, b Message) int {
+		x, y := readColBool7(a), readColBool7(b)
+		switch {
+		case !x && y:
+			return -1
+		case x && !y:
+			return 1
+		}
+		return 0
+	},
+	"col_bool_8": func(a, b Message) int {
+		xs, _, _ := readColBool8(a, nil, nil, nil)
+		ys, _, _ := readColBool8(b, nil, nil, nil)
+		if len(xs) == 0 || len(ys) == 0 {
+			return len(xs) - len(ys)
+		}
+		switch {
+		case !xs[0] && ys[0]:
+			return -1
+		case xs[0] && !ys[0]:
+			return 1
+		}
+		return 0
+	},
+	"col_bool_9": func(a, b Message) int {
+		x, y := readColBool9(a), readColBool9(b)
+		switch {
+		case !x && y:
+			return -1
+		case x && !y:
+			return 1
+		}
+		return 0
+	},
+}
 
 func begin(p *ParquetWriter) error {
-	_, err := p.w.Write(par1)
+	_, err := io.WriteString(p.w, parquet.Magic)
 	return err
 }
 
@@ -818,21 +1695,21 @@ func withMeta(m *parquet.Metadata) func(*ParquetWriter) error {
 }
 
 func Uncompressed(p *ParquetWriter) error {
-	p.compression = compressionUncompressed
+	p.compression = sch.CompressionCodec_UNCOMPRESSED
 	return nil
 }
 
 func Snappy(p *ParquetWriter) error {
-	p.compression = compressionSnappy
+	p.compression = sch.CompressionCodec_SNAPPY
 	return nil
 }
 
 func Gzip(p *ParquetWriter) error {
-	p.compression = compressionGzip
+	p.compression = sch.CompressionCodec_GZIP
 	return nil
 }
 
-func withCompression(c compression) func(*ParquetWriter) error {
+func withCompression(c sch.CompressionCodec) func(*ParquetWriter) error {
 	return func(p *ParquetWriter) error {
 		p.compression = c
 		return nil
@@ -840,13 +1717,26 @@ func withCompression(c compression) func(*ParquetWriter) error {
 }
 
 func (p *ParquetWriter) Write() error {
-	for i, f := range p.fields {
-		if err := f.Write(p.w, p.meta); err != nil {
+	return p.WriteContext(context.Background())
+}
+
+// WriteContext is Write with a context.  ctx is checked before each
+// page is written and ctx.Err() is returned if it is done (after
+// which the file is incomplete and should be discarded).
+func (p *ParquetWriter) WriteContext(ctx context.Context) error {
+	if p.compares != nil {
+		if err := p.addSorted(); err != nil {
 			return err
 		}
+	}
 
-		for child := p.child; child != nil; child = child.child {
-			if err := child.fields[i].Write(p.w, p.meta); err != nil {
+	if p.concurrency > 1 {
+		if err := p.writeConcurrently(ctx); err != nil {
+			return err
+		}
+	} else {
+		for i := range p.fields {
+			if err := p.writeColumn(ctx, p.w, i); err != nil {
 				return err
 			}
 		}
@@ -864,16 +1754,118 @@ func (p *ParquetWriter) Write() error {
 	return nil
 }
 
+// addSorted sorts the records that have been added since the
+// last Write and adds them to the row group.
+func (p *ParquetWriter) addSorted() error {
+	recs := p.records
+	p.records = nil
+	sort.SliceStable(recs, func(i, j int) bool {
+		for _, cmp := range p.compares {
+			if c := cmp(recs[i], recs[j]); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+	return p.addBatch(recs)
+}
+
+// writeColumn writes the pages of the i'th column.
+func (p *ParquetWriter) writeColumn(ctx context.Context, w io.Writer, i int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	if err := p.fields[i].Write(w, p.meta); err != nil {
+		return err
+	}
+
+	for child := p.child; child != nil; child = child.child {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if err := child.fields[i].Write(w, p.meta); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeConcurrently writes each column to its own buffer (using
+// up to p.concurrency goroutines) and then writes the buffers to
+// p.w in order.
+func (p *ParquetWriter) writeConcurrently(ctx context.Context) error {
+	bufs := make([]*bytebufferpool.ByteBuffer, len(p.fields))
+	errs := make([]error, len(p.fields))
+	sem := make(chan struct{}, p.concurrency)
+
+	var wg sync.WaitGroup
+	for i := range p.fields {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			bufs[i] = parquet.GetBuffer()
+			errs[i] = p.writeColumn(ctx, bufs[i], i)
+		}(i)
+	}
+	wg.Wait()
+
+	defer func() {
+		for _, buf := range bufs {
+			parquet.PutBuffer(buf)
+		}
+	}()
+
+	for i, buf := range bufs {
+		if errs[i] != nil {
+			return errs[i]
+		}
+
+		if _, err := p.w.Write(buf.B); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (p *ParquetWriter) Close() error {
-	if err := p.meta.Footer(p.w); err != nil {
+	return p.CloseContext(context.Background())
+}
+
+// CloseContext is Close with a context.  It returns ctx.Err() if
+// ctx is done before the metadata is written.
+func (p *ParquetWriter) CloseContext(ctx context.Context) error {
+	if err := p.meta.FooterContext(ctx, p.w); err != nil {
 		return err
 	}
 
-	_, err := p.w.Write(par1)
-	return err
+	if _, err := io.WriteString(p.w, parquet.Magic); err != nil {
+		return err
+	}
+
+	if p.appended == nil {
+		return nil
+	}
+
+	end, err := p.appended.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	return p.appended.Truncate(end)
 }
 
 func (p *ParquetWriter) Add(rec Message) {
+	if p.compares != nil {
+		p.records = append(p.records, rec)
+		return
+	}
+
 	if p.len == p.max {
 		if p.child == nil {
 			// an error can't happen here
@@ -892,11 +1884,61 @@ func (p *ParquetWriter) Add(rec Message) {
 	p.len++
 }
 
+// AddBatch adds recs to the current row group.  Unlike calling
+// Add for each record it adds the records one column (and one page)
+// at a time, so each required column grows with a single append
+// per page.
+func (p *ParquetWriter) AddBatch(recs []Message) error {
+	if p.max <= 0 {
+		return fmt.Errorf("invalid max page size: %d", p.max)
+	}
+
+	if p.compares != nil {
+		p.records = append(p.records, recs...)
+		return nil
+	}
+	return p.addBatch(recs)
+}
+
+func (p *ParquetWriter) addBatch(recs []Message) error {
+	w := p
+	for len(recs) > 0 {
+		for w.len == w.max {
+			if w.child == nil {
+				child, err := newParquetWriter(p.w, MaxPageSize(p.max), withMeta(p.meta), withCompression(p.compression))
+				if err != nil {
+					return err
+				}
+				w.child = child
+			}
+			w = w.child
+		}
+
+		n := w.max - w.len
+		if n > len(recs) {
+			n = len(recs)
+		}
+
+		batch := recs[:n]
+		for _, f := range w.fields {
+			f.AddBatch(batch)
+		}
+
+		p.meta.NextDocs(int64(n))
+
+		w.len += n
+		recs = recs[n:]
+	}
+	return nil
+}
+
 type Field interface {
 	Add(r Message)
+	AddBatch(rs []Message)
 	Write(w io.Writer, meta *parquet.Metadata) error
 	Schema() parquet.Field
-	Scan(r *Message)
+	Scan(r *Message) bool
+	ScanBatch(rs []Message) int
 	Read(r io.ReadSeeker, pg parquet.Page) error
 	Name() string
 	Levels() ([]uint8, []uint8)
@@ -911,7 +1953,6 @@ func getFields(ff []Field) map[string]Field {
 }
 
 func NewParquetReader(r io.ReadSeeker, opts ...func(*ParquetReader)) (*ParquetReader, error) {
-	ff := Fields(compressionUnknown)
 	pr := &ParquetReader{
 		r: r,
 	}
@@ -920,31 +1961,64 @@ func NewParquetReader(r io.ReadSeeker, opts ...func(*ParquetReader)) (*ParquetRe
 		opt(pr)
 	}
 
-	schema := make([]parquet.Field, len(ff))
-	for i, f := range ff {
-		pr.fieldNames = append(pr.fieldNames, f.Name())
-		schema[i] = f.Schema()
+	if pr.concurrency > 0 || pr.prefetch {
+		ra, ok := r.(io.ReaderAt)
+		if !ok {
+			return nil, fmt.Errorf("concurrent reads require an io.ReaderAt, got %T", r)
+		}
+		pr.ra = ra
 	}
 
-	meta := parquet.New(schema...)
+	meta := pr.metadata()
 	if err := meta.ReadFooter(r); err != nil {
 		return nil, err
 	}
-	pr.rows = meta.Rows()
-	var err error
-	pr.pages, err = meta.Pages()
-	if err != nil {
+
+	return pr, pr.start(meta)
+}
+
+// NewParquetReaderAt creates a reader that only reads the file (of the
+// given size) with ReadAt, so, unlike NewParquetReader, it doesn't depend
+// on the seek position of r.
+func NewParquetReaderAt(r io.ReaderAt, size int64, opts ...func(*ParquetReader)) (*ParquetReader, error) {
+	pr := &ParquetReader{
+		ra: r,
+	}
+
+	for _, opt := range opts {
+		opt(pr)
+	}
+
+	meta := pr.metadata()
+	if err := meta.ReadFooterAt(r, size); err != nil {
 		return nil, err
 	}
 
-	pr.rowGroups = meta.RowGroups()
-	_, err = r.Seek(4, io.SeekStart)
+	return pr, pr.start(meta)
+}
+
+func (p *ParquetReader) metadata() *parquet.Metadata {
+	ff := Fields(sch.CompressionCodec_UNCOMPRESSED)
+	schema := make([]parquet.Field, len(ff))
+	for i, f := range ff {
+		p.fieldNames = append(p.fieldNames, f.Name())
+		schema[i] = f.Schema()
+	}
+	return parquet.New(schema...)
+}
+
+func (p *ParquetReader) start(meta *parquet.Metadata) error {
+	p.rows = meta.Rows()
+	var err error
+	p.pages, err = meta.Pages()
 	if err != nil {
-		return nil, err
+		return err
 	}
-	pr.meta = meta
 
-	return pr, pr.readRowGroup()
+	p.rowGroups = meta.RowGroups()
+	p.meta = meta
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	return p.readRowGroup(context.Background())
 }
 
 func readerIndex(i int) func(*ParquetReader) {
@@ -953,6 +2027,22 @@ func readerIndex(i int) func(*ParquetReader) {
 	}
 }
 
+// ReadConcurrency makes the reader read and decode up to n columns
+// of a row group at the same time.  The io.ReadSeeker passed to
+// NewParquetReader must also be an io.ReaderAt.
+func ReadConcurrency(n int) func(*ParquetReader) {
+	return func(p *ParquetReader) {
+		p.concurrency = n
+	}
+}
+
+// Prefetch makes the reader read and decode the next row group in
+// the background while the current one is being scanned.  The io.ReadSeeker
+// passed to NewParquetReader must also be an io.ReaderAt.
+func Prefetch(p *ParquetReader) {
+	p.prefetch = true
+}
+
 // ParquetReader reads one page from a row group.
 type ParquetReader struct {
 	fields         map[string]Field
@@ -968,21 +2058,28 @@ type ParquetReader struct {
 
 	r         io.ReadSeeker
 	rowGroups []parquet.RowGroup
-}
 
-type Levels struct {
-	Name string
-	Defs []uint8
-	Reps []uint8
+	// ra is set when the reader was created by NewParquetReaderAt
+	// or when the row groups are read concurrently (see
+	// ReadConcurrency and Prefetch).
+	ra          io.ReaderAt
+	concurrency int
+	prefetch    bool
+	prefetched  <-chan fetchedRowGroup
+
+	// ctx bounds the row groups that are read in the background.
+	// It is cancelled by Close or once the reader fails.
+	ctx    context.Context
+	cancel context.CancelFunc
 }
 
-func (p *ParquetReader) Levels() []Levels {
-	var out []Levels
+func (p *ParquetReader) Levels() []parquet.Levels {
+	var out []parquet.Levels
 	//for {
 	for _, name := range p.fieldNames {
 		f := p.fields[name]
 		d, r := f.Levels()
-		out = append(out, Levels{Name: f.Name(), Defs: d, Reps: r})
+		out = append(out, parquet.Levels{Name: f.Name(), Defs: d, Reps: r})
 	}
 	//	if err := p.readRowGroup(); err != nil {
 	//		break
@@ -995,7 +2092,27 @@ func (p *ParquetReader) Error() error {
 	return p.err
 }
 
-func (p *ParquetReader) readRowGroup() error {
+// Close stops reading row groups in the background (see
+// Prefetch).  It doesn't close the underlying reader.
+func (p *ParquetReader) Close() error {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	return nil
+}
+
+// KeyValueMetadata returns the key/value metadata of the file.
+func (p *ParquetReader) KeyValueMetadata() map[string]string {
+	return p.meta.KeyValueMetadata()
+}
+
+// CreatedBy returns the application that wrote the file (if the
+// file says so).
+func (p *ParquetReader) CreatedBy() string {
+	return p.meta.CreatedBy()
+}
+
+func (p *ParquetReader) readRowGroup(ctx context.Context) error {
 	p.rowGroupCursor = 0
 
 	if len(p.rowGroups) == 0 {
@@ -1004,23 +2121,39 @@ func (p *ParquetReader) readRowGroup() error {
 	}
 
 	rg := p.rowGroups[0]
-	p.fields = getFields(Fields(compressionUnknown))
 	p.rowGroupCount = rg.Rows
-	p.rowGroupCursor = 0
+	if p.ra != nil {
+		return p.readRowGroupAt(ctx)
+	}
+
+	p.fields = getFields(Fields(sch.CompressionCodec_UNCOMPRESSED))
 	for _, col := range rg.Columns() {
 		name := strings.Join(col.MetaData.PathInSchema, ".")
 		f, ok := p.fields[name]
 		if !ok {
-			return fmt.Errorf("unknown field: %s", name)
+			return &parquet.ErrSchemaMismatch{Column: name, Reason: "unknown field"}
 		}
 		pages := p.pages[name]
 		if len(pages) <= p.index {
 			break
 		}
 
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		pg := pages[0]
+		if _, err := p.r.Seek(pg.Offset, io.SeekStart); err != nil {
+			return err
+		}
+
 		if err := f.Read(p.r, pg); err != nil {
-			return fmt.Errorf("unable to read field %s, err: %s", f.Name(), err)
+			return fmt.Errorf("unable to read field %s, err: %w", f.Name(), err)
+		}
+
+		_, reps := f.Levels()
+		if err := parquet.CheckRecords(name, pg, reps, rg.Rows); err != nil {
+			return err
 		}
 		p.pages[name] = p.pages[name][1:]
 	}
@@ -1028,16 +2161,146 @@ func (p *ParquetReader) readRowGroup() error {
 	return nil
 }
 
+type fetchedRowGroup struct {
+	fields map[string]Field
+	err    error
+}
+
+// readRowGroupAt reads the next row group (unless it has already
+// been prefetched) and, if p.prefetch is set, starts fetching the
+// one after that.  The row groups are fetched under p.ctx, ctx only
+// bounds how long readRowGroupAt waits for the next one.
+func (p *ParquetReader) readRowGroupAt(ctx context.Context) error {
+	next := p.prefetched
+	p.prefetched = nil
+	if next == nil {
+		next = p.fetchRowGroup(p.ctx, p.rowGroups[0])
+	}
+
+	p.rowGroups = p.rowGroups[1:]
+	if p.prefetch && len(p.rowGroups) > 0 {
+		p.prefetched = p.fetchRowGroup(p.ctx, p.rowGroups[0])
+	}
+
+	var res fetchedRowGroup
+	select {
+	case res = <-next:
+	case <-ctx.Done():
+		p.cancel()
+		return ctx.Err()
+	}
+
+	if res.err != nil {
+		p.cancel()
+		return res.err
+	}
+
+	p.fields = res.fields
+	return nil
+}
+
+// fetchRowGroup reads and decodes the columns of rg in the
+// background, up to p.concurrency columns at a time.  It stops
+// reading columns once ctx is done.
+func (p *ParquetReader) fetchRowGroup(ctx context.Context, rg parquet.RowGroup) <-chan fetchedRowGroup {
+	type column struct {
+		f  Field
+		pg parquet.Page
+	}
+
+	out := make(chan fetchedRowGroup, 1)
+	fields := getFields(Fields(sch.CompressionCodec_UNCOMPRESSED))
+	var cols []column
+	for _, col := range rg.Columns() {
+		name := strings.Join(col.MetaData.PathInSchema, ".")
+		f, ok := fields[name]
+		if !ok {
+			out <- fetchedRowGroup{err: &parquet.ErrSchemaMismatch{Column: name, Reason: "unknown field"}}
+			return out
+		}
+		pages := p.pages[name]
+		if len(pages) <= p.index {
+			break
+		}
+
+		cols = append(cols, column{f: f, pg: pages[0]})
+		p.pages[name] = p.pages[name][1:]
+	}
+
+	n := p.concurrency
+	if n < 1 {
+		n = 1
+	}
+
+	go func() {
+		errs := make([]error, len(cols))
+		sem := make(chan struct{}, n)
+		var wg sync.WaitGroup
+		for i, c := range cols {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(i int, c column) {
+				defer func() {
+					<-sem
+					wg.Done()
+				}()
+
+				if err := ctx.Err(); err != nil {
+					errs[i] = err
+					return
+				}
+
+				r := io.NewSectionReader(p.ra, c.pg.Offset, int64(c.pg.Size))
+				if err := c.f.Read(r, c.pg); err != nil {
+					errs[i] = fmt.Errorf("unable to read field %s, err: %w", c.f.Name(), err)
+					return
+				}
+
+				_, reps := c.f.Levels()
+				errs[i] = parquet.CheckRecords(c.f.Name(), c.pg, reps, rg.Rows)
+			}(i, c)
+		}
+		wg.Wait()
+
+		for _, err := range errs {
+			if err != nil {
+				out <- fetchedRowGroup{err: err}
+				return
+			}
+		}
+		out <- fetchedRowGroup{fields: fields}
+	}()
+	return out
+}
+
 func (p *ParquetReader) Rows() int64 {
 	return p.rows
 }
 
 func (p *ParquetReader) Next() bool {
-	if p.err == nil && p.cursor >= p.rows {
+	return p.NextContext(context.Background())
+}
+
+// NextContext is Next with a context.  It returns false (and Error
+// returns ctx.Err()) once ctx is done, even if every record has
+// already been read, and it keeps returning false once the reader
+// has failed.
+func (p *ParquetReader) NextContext(ctx context.Context) bool {
+	if p.err != nil {
+		return false
+	}
+
+	if err := ctx.Err(); err != nil {
+		p.err = err
+		return false
+	}
+
+	if p.cursor >= p.rows {
 		return false
 	}
+
 	if p.rowGroupCursor >= p.rowGroupCount {
-		p.err = p.readRowGroup()
+		p.err = p.readRowGroup(ctx)
 		if p.err != nil {
 			return false
 		}
@@ -1048,15 +2311,65 @@ func (p *ParquetReader) Next() bool {
 	return true
 }
 
-func (p *ParquetReader) Scan(x *Message) {
+func (p *ParquetReader) Scan(x *Message) {
+	if p.err != nil {
+		return
+	}
+
+	for _, name := range p.fieldNames {
+		f := p.fields[name]
+		f.Scan(x)
+	}
+}
+
+// ReadBatch reads up to len(dst) records into dst, one column at a
+// time, and returns the number of records read.  Each record is
+// reset before it is read into, so dst can be reused.  It returns
+// io.EOF once all the records have been read.
+func (p *ParquetReader) ReadBatch(dst []Message) (int, error) {
+	var n int
+	for n < len(dst) && p.err == nil && p.cursor < p.rows {
+		if p.rowGroupCursor >= p.rowGroupCount {
+			p.err = p.readRowGroup(context.Background())
+			if p.err != nil || p.rowGroupCount == 0 {
+				break
+			}
+		}
+
+		m := int64(len(dst) - n)
+		if r := p.rowGroupCount - p.rowGroupCursor; r < m {
+			m = r
+		}
+
+		batch := dst[n : n+int(m)]
+		for i := range batch {
+			batch[i] = Message{}
+		}
+
+		for _, name := range p.fieldNames {
+			if k := p.fields[name].ScanBatch(batch); k < len(batch) {
+				p.err = fmt.Errorf("column %s has no more values: %w", name, io.EOF)
+				break
+			}
+		}
+
+		if p.err != nil {
+			break
+		}
+
+		p.cursor += m
+		p.rowGroupCursor += m
+		n += int(m)
+	}
+
 	if p.err != nil {
-		return
+		return n, p.err
 	}
 
-	for _, name := range p.fieldNames {
-		f := p.fields[name]
-		f.Scan(x)
+	if n == 0 && len(dst) > 0 {
+		return 0, io.EOF
 	}
+	return n, nil
 }
 
 type StringOptionalField struct {
@@ -1068,16 +2381,17 @@ type StringOptionalField struct {
 }
 
 func NewStringOptionalField(read func(r Message, vals []string, def, rep []uint8) ([]string, []uint8, []uint8), write func(r *Message, vals []string, defs, reps []uint8) (int, int), path []string, types []int, opts ...func(*parquet.OptionalField)) *StringOptionalField {
-	return &StringOptionalField{
+	f := &StringOptionalField{
 		read:          read,
 		write:         write,
 		OptionalField: parquet.NewOptionalField(path, types, opts...),
-		stats:         newStringOptionalStats(maxDef(types)),
 	}
+	f.stats = newStringOptionalStats(f.MaxLevels.Def)
+	return f
 }
 
 func (f *StringOptionalField) Schema() parquet.Field {
-	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: StringType, RepetitionType: f.RepetitionType, Types: f.Types}
+	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: parquet.StringType, RepetitionType: f.RepetitionType, Types: f.Types, IDs: f.IDs()}
 }
 
 func (f *StringOptionalField) Add(r Message) {
@@ -1088,9 +2402,42 @@ func (f *StringOptionalField) Add(r Message) {
 	f.Reps = reps
 }
 
-func (f *StringOptionalField) Scan(r *Message) {
+// AddBatch adds the values and levels of each of rs.  It
+// assumes each record has (at least) one value.
+func (f *StringOptionalField) AddBatch(rs []Message) {
+	if n := len(f.vals) + len(rs); n > cap(f.vals) {
+		vals := make([]string, len(f.vals), n)
+		copy(vals, f.vals)
+		f.vals = vals
+	}
+
+	if n := len(f.Defs) + len(rs); n > cap(f.Defs) {
+		defs := make([]uint8, len(f.Defs), n)
+		copy(defs, f.Defs)
+		f.Defs = defs
+	}
+
+	if n := len(f.Reps) + len(rs); f.MaxLevels.Rep > 0 && n > cap(f.Reps) {
+		reps := make([]uint8, len(f.Reps), n)
+		copy(reps, f.Reps)
+		f.Reps = reps
+	}
+
+	vals, defs, reps := f.vals, f.Defs, f.Reps
+	for _, r := range rs {
+		vals, defs, reps = f.read(r, vals, defs, reps)
+	}
+	f.stats.add(vals[len(f.vals):], defs[len(f.Defs):])
+	f.vals = vals
+	f.Defs = defs
+	f.Reps = reps
+}
+
+// Scan writes the values of the next record to r.  It returns
+// false if there are no more records.
+func (f *StringOptionalField) Scan(r *Message) bool {
 	if len(f.Defs) == 0 {
-		return
+		return false
 	}
 
 	v, l := f.write(r, f.vals, f.Defs, f.Reps)
@@ -1099,11 +2446,36 @@ func (f *StringOptionalField) Scan(r *Message) {
 	if len(f.Reps) > 0 {
 		f.Reps = f.Reps[l:]
 	}
+	return true
+}
+
+// ScanBatch writes the values of the next len(rs) records to rs
+// and returns the number of records it wrote, which is less than
+// len(rs) if the column has run out of values.
+func (f *StringOptionalField) ScanBatch(rs []Message) int {
+	var n, v, l int
+	for ; n < len(rs) && l < len(f.Defs); n++ {
+		var reps []uint8
+		if len(f.Reps) > 0 {
+			reps = f.Reps[l:]
+		}
+
+		vv, ll := f.write(&rs[n], f.vals[v:], f.Defs[l:], reps)
+		v += vv
+		l += ll
+	}
+
+	f.vals = f.vals[v:]
+	f.Defs = f.Defs[l:]
+	if len(f.Reps) > 0 {
+		f.Reps = f.Reps[l:]
+	}
+	return n
 }
 
 func (f *StringOptionalField) Write(w io.Writer, meta *parquet.Metadata) error {
-	buf := buffpool.Get()
-	defer buffpool.Put(buf)
+	buf := parquet.GetBuffer()
+	defer parquet.PutBuffer(buf)
 
 	bs := make([]byte, 4)
 	for _, s := range f.vals {
@@ -1129,7 +2501,7 @@ func (f *StringOptionalField) Read(r io.ReadSeeker, pg parquet.Page) error {
 			return err
 		}
 		s := make([]byte, x)
-		if _, err := rr.Read(s); err != nil {
+		if _, err := io.ReadFull(rr, s); err != nil {
 			return err
 		}
 
@@ -1144,28 +2516,32 @@ func (f *StringOptionalField) Levels() ([]uint8, []uint8) {
 
 type StringField struct {
 	parquet.RequiredField
-	vals  []string
-	read  func(r Message) string
-	write func(r *Message, vals []string)
-	stats *stringStats
+	vals       []string
+	read       func(r Message) string
+	readBatch  func(rs []Message, vals []string) []string
+	write      func(r *Message, vals []string)
+	writeBatch func(rs []Message, vals []string)
+	stats      *stringStats
 }
 
-func NewStringField(read func(r Message) string, write func(r *Message, vals []string), path []string, opts ...func(*parquet.RequiredField)) *StringField {
+func NewStringField(read func(r Message) string, readBatch func(rs []Message, vals []string) []string, write func(r *Message, vals []string), writeBatch func(rs []Message, vals []string), path []string, opts ...func(*parquet.RequiredField)) *StringField {
 	return &StringField{
 		read:          read,
+		readBatch:     readBatch,
 		write:         write,
+		writeBatch:    writeBatch,
 		RequiredField: parquet.NewRequiredField(path, opts...),
 		stats:         newStringStats(),
 	}
 }
 
 func (f *StringField) Schema() parquet.Field {
-	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: StringType, RepetitionType: parquet.RepetitionRequired, Types: []int{0}}
+	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: parquet.StringType, RepetitionType: parquet.RepetitionRequired, Types: []int{0}, IDs: f.IDs()}
 }
 
 func (f *StringField) Write(w io.Writer, meta *parquet.Metadata) error {
-	buf := buffpool.Get()
-	defer buffpool.Put(buf)
+	buf := parquet.GetBuffer()
+	defer parquet.PutBuffer(buf)
 
 	bs := make([]byte, 4)
 	for _, s := range f.vals {
@@ -1191,7 +2567,7 @@ func (f *StringField) Read(r io.ReadSeeker, pg parquet.Page) error {
 			return err
 		}
 		s := make([]byte, x)
-		if _, err := rr.Read(s); err != nil {
+		if _, err := io.ReadFull(rr, s); err != nil {
 			return err
 		}
 
@@ -1200,13 +2576,30 @@ func (f *StringField) Read(r io.ReadSeeker, pg parquet.Page) error {
 	return nil
 }
 
-func (f *StringField) Scan(r *Message) {
+// Scan writes the next value to r.  It returns false if there
+// are no more values.
+func (f *StringField) Scan(r *Message) bool {
 	if len(f.vals) == 0 {
-		return
+		return false
 	}
 
 	f.write(r, f.vals)
 	f.vals = f.vals[1:]
+	return true
+}
+
+// ScanBatch writes the next len(rs) values to rs and returns the
+// number of records it wrote, which is less than len(rs) if the
+// column has run out of values.
+func (f *StringField) ScanBatch(rs []Message) int {
+	n := len(rs)
+	if n > len(f.vals) {
+		n = len(f.vals)
+	}
+
+	f.writeBatch(rs[:n], f.vals[:n])
+	f.vals = f.vals[n:]
+	return n
 }
 
 func (f *StringField) Add(r Message) {
@@ -1215,6 +2608,15 @@ func (f *StringField) Add(r Message) {
 	f.vals = append(f.vals, v)
 }
 
+// AddBatch adds the values of each of rs with a single append.
+func (f *StringField) AddBatch(rs []Message) {
+	n := len(f.vals)
+	f.vals = f.readBatch(rs, f.vals)
+	for _, v := range f.vals[n:] {
+		f.stats.add(v)
+	}
+}
+
 func (f *StringField) Levels() ([]uint8, []uint8) {
 	return nil, nil
 }
@@ -1228,21 +2630,22 @@ type Int64OptionalField struct {
 }
 
 func NewInt64OptionalField(read func(r Message, vals []int64, defs, reps []uint8) ([]int64, []uint8, []uint8), write func(r *Message, vals []int64, defs, reps []uint8) (int, int), path []string, types []int, opts ...func(*parquet.OptionalField)) *Int64OptionalField {
-	return &Int64OptionalField{
+	f := &Int64OptionalField{
 		read:          read,
 		write:         write,
 		OptionalField: parquet.NewOptionalField(path, types, opts...),
-		stats:         newint64optionalStats(maxDef(types)),
 	}
+	f.stats = newint64optionalStats(f.MaxLevels.Def)
+	return f
 }
 
 func (f *Int64OptionalField) Schema() parquet.Field {
-	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: Int64Type, RepetitionType: f.RepetitionType, Types: f.Types}
+	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: parquet.Int64Type, RepetitionType: f.RepetitionType, Types: f.Types, IDs: f.IDs()}
 }
 
 func (f *Int64OptionalField) Write(w io.Writer, meta *parquet.Metadata) error {
-	buf := buffpool.Get()
-	defer buffpool.Put(buf)
+	buf := parquet.GetBuffer()
+	defer parquet.PutBuffer(buf)
 
 	bs := make([]byte, 8)
 	for _, v := range f.vals {
@@ -1274,9 +2677,42 @@ func (f *Int64OptionalField) Add(r Message) {
 	f.Reps = reps
 }
 
-func (f *Int64OptionalField) Scan(r *Message) {
+// AddBatch adds the values and levels of each of rs.  It
+// assumes each record has (at least) one value.
+func (f *Int64OptionalField) AddBatch(rs []Message) {
+	if n := len(f.vals) + len(rs); n > cap(f.vals) {
+		vals := make([]int64, len(f.vals), n)
+		copy(vals, f.vals)
+		f.vals = vals
+	}
+
+	if n := len(f.Defs) + len(rs); n > cap(f.Defs) {
+		defs := make([]uint8, len(f.Defs), n)
+		copy(defs, f.Defs)
+		f.Defs = defs
+	}
+
+	if n := len(f.Reps) + len(rs); f.MaxLevels.Rep > 0 && n > cap(f.Reps) {
+		reps := make([]uint8, len(f.Reps), n)
+		copy(reps, f.Reps)
+		f.Reps = reps
+	}
+
+	vals, defs, reps := f.vals, f.Defs, f.Reps
+	for _, r := range rs {
+		vals, defs, reps = f.read(r, vals, defs, reps)
+	}
+	f.stats.add(vals[len(f.vals):], defs[len(f.Defs):])
+	f.vals = vals
+	f.Defs = defs
+	f.Reps = reps
+}
+
+// Scan writes the values of the next record to r.  It returns
+// false if there are no more records.
+func (f *Int64OptionalField) Scan(r *Message) bool {
 	if len(f.Defs) == 0 {
-		return
+		return false
 	}
 
 	v, l := f.write(r, f.vals, f.Defs, f.Reps)
@@ -1285,6 +2721,31 @@ func (f *Int64OptionalField) Scan(r *Message) {
 	if len(f.Reps) > 0 {
 		f.Reps = f.Reps[l:]
 	}
+	return true
+}
+
+// ScanBatch writes the values of the next len(rs) records to rs
+// and returns the number of records it wrote, which is less than
+// len(rs) if the column has run out of values.
+func (f *Int64OptionalField) ScanBatch(rs []Message) int {
+	var n, v, l int
+	for ; n < len(rs) && l < len(f.Defs); n++ {
+		var reps []uint8
+		if len(f.Reps) > 0 {
+			reps = f.Reps[l:]
+		}
+
+		vv, ll := f.write(&rs[n], f.vals[v:], f.Defs[l:], reps)
+		v += vv
+		l += ll
+	}
+
+	f.vals = f.vals[v:]
+	f.Defs = f.Defs[l:]
+	if len(f.Reps) > 0 {
+		f.Reps = f.Reps[l:]
+	}
+	return n
 }
 
 func (f *Int64OptionalField) Levels() ([]uint8, []uint8) {
@@ -1294,22 +2755,26 @@ func (f *Int64OptionalField) Levels() ([]uint8, []uint8) {
 type Int64Field struct {
 	vals []int64
 	parquet.RequiredField
-	read  func(r Message) int64
-	write func(r *Message, vals []int64)
-	stats *int64stats
+	read       func(r Message) int64
+	readBatch  func(rs []Message, vals []int64) []int64
+	write      func(r *Message, vals []int64)
+	writeBatch func(rs []Message, vals []int64)
+	stats      *int64stats
 }
 
-func NewInt64Field(read func(r Message) int64, write func(r *Message, vals []int64), path []string, opts ...func(*parquet.RequiredField)) *Int64Field {
+func NewInt64Field(read func(r Message) int64, readBatch func(rs []Message, vals []int64) []int64, write func(r *Message, vals []int64), writeBatch func(rs []Message, vals []int64), path []string, opts ...func(*parquet.RequiredField)) *Int64Field {
 	return &Int64Field{
 		read:          read,
+		readBatch:     readBatch,
 		write:         write,
+		writeBatch:    writeBatch,
 		RequiredField: parquet.NewRequiredField(path, opts...),
 		stats:         newInt64stats(),
 	}
 }
 
 func (f *Int64Field) Schema() parquet.Field {
-	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: Int64Type, RepetitionType: parquet.RepetitionRequired, Types: []int{0}}
+	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: parquet.Int64Type, RepetitionType: parquet.RepetitionRequired, Types: []int{0}, IDs: f.IDs()}
 }
 
 func (f *Int64Field) Read(r io.ReadSeeker, pg parquet.Page) error {
@@ -1325,8 +2790,8 @@ func (f *Int64Field) Read(r io.ReadSeeker, pg parquet.Page) error {
 }
 
 func (f *Int64Field) Write(w io.Writer, meta *parquet.Metadata) error {
-	buf := buffpool.Get()
-	defer buffpool.Put(buf)
+	buf := parquet.GetBuffer()
+	defer parquet.PutBuffer(buf)
 
 	bs := make([]byte, 8)
 	for _, v := range f.vals {
@@ -1338,13 +2803,30 @@ func (f *Int64Field) Write(w io.Writer, meta *parquet.Metadata) error {
 	return f.DoWrite(w, meta, buf.Bytes(), len(f.vals), f.stats)
 }
 
-func (f *Int64Field) Scan(r *Message) {
+// Scan writes the next value to r.  It returns false if there
+// are no more values.
+func (f *Int64Field) Scan(r *Message) bool {
 	if len(f.vals) == 0 {
-		return
+		return false
 	}
 
 	f.write(r, f.vals)
 	f.vals = f.vals[1:]
+	return true
+}
+
+// ScanBatch writes the next len(rs) values to rs and returns the
+// number of records it wrote, which is less than len(rs) if the
+// column has run out of values.
+func (f *Int64Field) ScanBatch(rs []Message) int {
+	n := len(rs)
+	if n > len(f.vals) {
+		n = len(f.vals)
+	}
+
+	f.writeBatch(rs[:n], f.vals[:n])
+	f.vals = f.vals[n:]
+	return n
 }
 
 func (f *Int64Field) Add(r Message) {
@@ -1353,6 +2835,15 @@ func (f *Int64Field) Add(r Message) {
 	f.vals = append(f.vals, v)
 }
 
+// AddBatch adds the values of each of rs with a single append.
+func (f *Int64Field) AddBatch(rs []Message) {
+	n := len(f.vals)
+	f.vals = f.readBatch(rs, f.vals)
+	for _, v := range f.vals[n:] {
+		f.stats.add(v)
+	}
+}
+
 func (f *Int64Field) Levels() ([]uint8, []uint8) {
 	return nil, nil
 }
@@ -1366,21 +2857,22 @@ type Int32OptionalField struct {
 }
 
 func NewInt32OptionalField(read func(r Message, vals []int32, defs, reps []uint8) ([]int32, []uint8, []uint8), write func(r *Message, vals []int32, defs, reps []uint8) (int, int), path []string, types []int, opts ...func(*parquet.OptionalField)) *Int32OptionalField {
-	return &Int32OptionalField{
+	f := &Int32OptionalField{
 		read:          read,
 		write:         write,
 		OptionalField: parquet.NewOptionalField(path, types, opts...),
-		stats:         newint32optionalStats(maxDef(types)),
 	}
+	f.stats = newint32optionalStats(f.MaxLevels.Def)
+	return f
 }
 
 func (f *Int32OptionalField) Schema() parquet.Field {
-	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: Int32Type, RepetitionType: f.RepetitionType, Types: f.Types}
+	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: parquet.Int32Type, RepetitionType: f.RepetitionType, Types: f.Types, IDs: f.IDs()}
 }
 
 func (f *Int32OptionalField) Write(w io.Writer, meta *parquet.Metadata) error {
-	buf := buffpool.Get()
-	defer buffpool.Put(buf)
+	buf := parquet.GetBuffer()
+	defer parquet.PutBuffer(buf)
 
 	bs := make([]byte, 4)
 	for _, v := range f.vals {
@@ -1412,9 +2904,42 @@ func (f *Int32OptionalField) Add(r Message) {
 	f.Reps = reps
 }
 
-func (f *Int32OptionalField) Scan(r *Message) {
+// AddBatch adds the values and levels of each of rs.  It
+// assumes each record has (at least) one value.
+func (f *Int32OptionalField) AddBatch(rs []Message) {
+	if n := len(f.vals) + len(rs); n > cap(f.vals) {
+		vals := make([]int32, len(f.vals), n)
+		copy(vals, f.vals)
+		f.vals = vals
+	}
+
+	if n := len(f.Defs) + len(rs); n > cap(f.Defs) {
+		defs := make([]uint8, len(f.Defs), n)
+		copy(defs, f.Defs)
+		f.Defs = defs
+	}
+
+	if n := len(f.Reps) + len(rs); f.MaxLevels.Rep > 0 && n > cap(f.Reps) {
+		reps := make([]uint8, len(f.Reps), n)
+		copy(reps, f.Reps)
+		f.Reps = reps
+	}
+
+	vals, defs, reps := f.vals, f.Defs, f.Reps
+	for _, r := range rs {
+		vals, defs, reps = f.read(r, vals, defs, reps)
+	}
+	f.stats.add(vals[len(f.vals):], defs[len(f.Defs):])
+	f.vals = vals
+	f.Defs = defs
+	f.Reps = reps
+}
+
+// Scan writes the values of the next record to r.  It returns
+// false if there are no more records.
+func (f *Int32OptionalField) Scan(r *Message) bool {
 	if len(f.Defs) == 0 {
-		return
+		return false
 	}
 
 	v, l := f.write(r, f.vals, f.Defs, f.Reps)
@@ -1423,6 +2948,31 @@ func (f *Int32OptionalField) Scan(r *Message) {
 	if len(f.Reps) > 0 {
 		f.Reps = f.Reps[l:]
 	}
+	return true
+}
+
+// ScanBatch writes the values of the next len(rs) records to rs
+// and returns the number of records it wrote, which is less than
+// len(rs) if the column has run out of values.
+func (f *Int32OptionalField) ScanBatch(rs []Message) int {
+	var n, v, l int
+	for ; n < len(rs) && l < len(f.Defs); n++ {
+		var reps []uint8
+		if len(f.Reps) > 0 {
+			reps = f.Reps[l:]
+		}
+
+		vv, ll := f.write(&rs[n], f.vals[v:], f.Defs[l:], reps)
+		v += vv
+		l += ll
+	}
+
+	f.vals = f.vals[v:]
+	f.Defs = f.Defs[l:]
+	if len(f.Reps) > 0 {
+		f.Reps = f.Reps[l:]
+	}
+	return n
 }
 
 func (f *Int32OptionalField) Levels() ([]uint8, []uint8) {
@@ -1432,22 +2982,26 @@ func (f *Int32OptionalField) Levels() ([]uint8, []uint8) {
 type Int32Field struct {
 	vals []int32
 	parquet.RequiredField
-	read  func(r Message) int32
-	write func(r *Message, vals []int32)
-	stats *int32stats
+	read       func(r Message) int32
+	readBatch  func(rs []Message, vals []int32) []int32
+	write      func(r *Message, vals []int32)
+	writeBatch func(rs []Message, vals []int32)
+	stats      *int32stats
 }
 
-func NewInt32Field(read func(r Message) int32, write func(r *Message, vals []int32), path []string, opts ...func(*parquet.RequiredField)) *Int32Field {
+func NewInt32Field(read func(r Message) int32, readBatch func(rs []Message, vals []int32) []int32, write func(r *Message, vals []int32), writeBatch func(rs []Message, vals []int32), path []string, opts ...func(*parquet.RequiredField)) *Int32Field {
 	return &Int32Field{
 		read:          read,
+		readBatch:     readBatch,
 		write:         write,
+		writeBatch:    writeBatch,
 		RequiredField: parquet.NewRequiredField(path, opts...),
 		stats:         newInt32stats(),
 	}
 }
 
 func (f *Int32Field) Schema() parquet.Field {
-	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: Int32Type, RepetitionType: parquet.RepetitionRequired, Types: []int{0}}
+	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: parquet.Int32Type, RepetitionType: parquet.RepetitionRequired, Types: []int{0}, IDs: f.IDs()}
 }
 
 func (f *Int32Field) Read(r io.ReadSeeker, pg parquet.Page) error {
@@ -1463,8 +3017,8 @@ func (f *Int32Field) Read(r io.ReadSeeker, pg parquet.Page) error {
 }
 
 func (f *Int32Field) Write(w io.Writer, meta *parquet.Metadata) error {
-	buf := buffpool.Get()
-	defer buffpool.Put(buf)
+	buf := parquet.GetBuffer()
+	defer parquet.PutBuffer(buf)
 
 	bs := make([]byte, 4)
 	for _, v := range f.vals {
@@ -1476,13 +3030,30 @@ func (f *Int32Field) Write(w io.Writer, meta *parquet.Metadata) error {
 	return f.DoWrite(w, meta, buf.Bytes(), len(f.vals), f.stats)
 }
 
-func (f *Int32Field) Scan(r *Message) {
+// Scan writes the next value to r.  It returns false if there
+// are no more values.
+func (f *Int32Field) Scan(r *Message) bool {
 	if len(f.vals) == 0 {
-		return
+		return false
 	}
 
 	f.write(r, f.vals)
 	f.vals = f.vals[1:]
+	return true
+}
+
+// ScanBatch writes the next len(rs) values to rs and returns the
+// number of records it wrote, which is less than len(rs) if the
+// column has run out of values.
+func (f *Int32Field) ScanBatch(rs []Message) int {
+	n := len(rs)
+	if n > len(f.vals) {
+		n = len(f.vals)
+	}
+
+	f.writeBatch(rs[:n], f.vals[:n])
+	f.vals = f.vals[n:]
+	return n
 }
 
 func (f *Int32Field) Add(r Message) {
@@ -1491,6 +3062,15 @@ func (f *Int32Field) Add(r Message) {
 	f.vals = append(f.vals, v)
 }
 
+// AddBatch adds the values of each of rs with a single append.
+func (f *Int32Field) AddBatch(rs []Message) {
+	n := len(f.vals)
+	f.vals = f.readBatch(rs, f.vals)
+	for _, v := range f.vals[n:] {
+		f.stats.add(v)
+	}
+}
+
 func (f *Int32Field) Levels() ([]uint8, []uint8) {
 	return nil, nil
 }
@@ -1504,21 +3084,22 @@ type Float64OptionalField struct {
 }
 
 func NewFloat64OptionalField(read func(r Message, vals []float64, defs, reps []uint8) ([]float64, []uint8, []uint8), write func(r *Message, vals []float64, defs, reps []uint8) (int, int), path []string, types []int, opts ...func(*parquet.OptionalField)) *Float64OptionalField {
-	return &Float64OptionalField{
+	f := &Float64OptionalField{
 		read:          read,
 		write:         write,
 		OptionalField: parquet.NewOptionalField(path, types, opts...),
-		stats:         newfloat64optionalStats(maxDef(types)),
 	}
+	f.stats = newfloat64optionalStats(f.MaxLevels.Def)
+	return f
 }
 
 func (f *Float64OptionalField) Schema() parquet.Field {
-	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: Float64Type, RepetitionType: f.RepetitionType, Types: f.Types}
+	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: parquet.Float64Type, RepetitionType: f.RepetitionType, Types: f.Types, IDs: f.IDs()}
 }
 
 func (f *Float64OptionalField) Write(w io.Writer, meta *parquet.Metadata) error {
-	buf := buffpool.Get()
-	defer buffpool.Put(buf)
+	buf := parquet.GetBuffer()
+	defer parquet.PutBuffer(buf)
 
 	bs := make([]byte, 8)
 	for _, v := range f.vals {
@@ -1550,9 +3131,42 @@ func (f *Float64OptionalField) Add(r Message) {
 	f.Reps = reps
 }
 
-func (f *Float64OptionalField) Scan(r *Message) {
+// AddBatch adds the values and levels of each of rs.  It
+// assumes each record has (at least) one value.
+func (f *Float64OptionalField) AddBatch(rs []Message) {
+	if n := len(f.vals) + len(rs); n > cap(f.vals) {
+		vals := make([]float64, len(f.vals), n)
+		copy(vals, f.vals)
+		f.vals = vals
+	}
+
+	if n := len(f.Defs) + len(rs); n > cap(f.Defs) {
+		defs := make([]uint8, len(f.Defs), n)
+		copy(defs, f.Defs)
+		f.Defs = defs
+	}
+
+	if n := len(f.Reps) + len(rs); f.MaxLevels.Rep > 0 && n > cap(f.Reps) {
+		reps := make([]uint8, len(f.Reps), n)
+		copy(reps, f.Reps)
+		f.Reps = reps
+	}
+
+	vals, defs, reps := f.vals, f.Defs, f.Reps
+	for _, r := range rs {
+		vals, defs, reps = f.read(r, vals, defs, reps)
+	}
+	f.stats.add(vals[len(f.vals):], defs[len(f.Defs):])
+	f.vals = vals
+	f.Defs = defs
+	f.Reps = reps
+}
+
+// Scan writes the values of the next record to r.  It returns
+// false if there are no more records.
+func (f *Float64OptionalField) Scan(r *Message) bool {
 	if len(f.Defs) == 0 {
-		return
+		return false
 	}
 
 	v, l := f.write(r, f.vals, f.Defs, f.Reps)
@@ -1561,6 +3175,31 @@ func (f *Float64OptionalField) Scan(r *Message) {
 	if len(f.Reps) > 0 {
 		f.Reps = f.Reps[l:]
 	}
+	return true
+}
+
+// ScanBatch writes the values of the next len(rs) records to rs
+// and returns the number of records it wrote, which is less than
+// len(rs) if the column has run out of values.
+func (f *Float64OptionalField) ScanBatch(rs []Message) int {
+	var n, v, l int
+	for ; n < len(rs) && l < len(f.Defs); n++ {
+		var reps []uint8
+		if len(f.Reps) > 0 {
+			reps = f.Reps[l:]
+		}
+
+		vv, ll := f.write(&rs[n], f.vals[v:], f.Defs[l:], reps)
+		v += vv
+		l += ll
+	}
+
+	f.vals = f.vals[v:]
+	f.Defs = f.Defs[l:]
+	if len(f.Reps) > 0 {
+		f.Reps = f.Reps[l:]
+	}
+	return n
 }
 
 func (f *Float64OptionalField) Levels() ([]uint8, []uint8) {
@@ -1570,22 +3209,26 @@ func (f *Float64OptionalField) Levels() ([]uint8, []uint8) {
 type Float64Field struct {
 	vals []float64
 	parquet.RequiredField
-	read  func(r Message) float64
-	write func(r *Message, vals []float64)
-	stats *float64stats
+	read       func(r Message) float64
+	readBatch  func(rs []Message, vals []float64) []float64
+	write      func(r *Message, vals []float64)
+	writeBatch func(rs []Message, vals []float64)
+	stats      *float64stats
 }
 
-func NewFloat64Field(read func(r Message) float64, write func(r *Message, vals []float64), path []string, opts ...func(*parquet.RequiredField)) *Float64Field {
+func NewFloat64Field(read func(r Message) float64, readBatch func(rs []Message, vals []float64) []float64, write func(r *Message, vals []float64), writeBatch func(rs []Message, vals []float64), path []string, opts ...func(*parquet.RequiredField)) *Float64Field {
 	return &Float64Field{
 		read:          read,
+		readBatch:     readBatch,
 		write:         write,
+		writeBatch:    writeBatch,
 		RequiredField: parquet.NewRequiredField(path, opts...),
 		stats:         newFloat64stats(),
 	}
 }
 
 func (f *Float64Field) Schema() parquet.Field {
-	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: Float64Type, RepetitionType: parquet.RepetitionRequired, Types: []int{0}}
+	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: parquet.Float64Type, RepetitionType: parquet.RepetitionRequired, Types: []int{0}, IDs: f.IDs()}
 }
 
 func (f *Float64Field) Read(r io.ReadSeeker, pg parquet.Page) error {
@@ -1601,8 +3244,8 @@ func (f *Float64Field) Read(r io.ReadSeeker, pg parquet.Page) error {
 }
 
 func (f *Float64Field) Write(w io.Writer, meta *parquet.Metadata) error {
-	buf := buffpool.Get()
-	defer buffpool.Put(buf)
+	buf := parquet.GetBuffer()
+	defer parquet.PutBuffer(buf)
 
 	bs := make([]byte, 8)
 	for _, v := range f.vals {
@@ -1614,13 +3257,30 @@ func (f *Float64Field) Write(w io.Writer, meta *parquet.Metadata) error {
 	return f.DoWrite(w, meta, buf.Bytes(), len(f.vals), f.stats)
 }
 
-func (f *Float64Field) Scan(r *Message) {
+// Scan writes the next value to r.  It returns false if there
+// are no more values.
+func (f *Float64Field) Scan(r *Message) bool {
 	if len(f.vals) == 0 {
-		return
+		return false
 	}
 
 	f.write(r, f.vals)
 	f.vals = f.vals[1:]
+	return true
+}
+
+// ScanBatch writes the next len(rs) values to rs and returns the
+// number of records it wrote, which is less than len(rs) if the
+// column has run out of values.
+func (f *Float64Field) ScanBatch(rs []Message) int {
+	n := len(rs)
+	if n > len(f.vals) {
+		n = len(f.vals)
+	}
+
+	f.writeBatch(rs[:n], f.vals[:n])
+	f.vals = f.vals[n:]
+	return n
 }
 
 func (f *Float64Field) Add(r Message) {
@@ -1629,6 +3289,15 @@ func (f *Float64Field) Add(r Message) {
 	f.vals = append(f.vals, v)
 }
 
+// AddBatch adds the values of each of rs with a single append.
+func (f *Float64Field) AddBatch(rs []Message) {
+	n := len(f.vals)
+	f.vals = f.readBatch(rs, f.vals)
+	for _, v := range f.vals[n:] {
+		f.stats.add(v)
+	}
+}
+
 func (f *Float64Field) Levels() ([]uint8, []uint8) {
 	return nil, nil
 }
@@ -1642,21 +3311,22 @@ type Float32OptionalField struct {
 }
 
 func NewFloat32OptionalField(read func(r Message, vals []float32, defs, reps []uint8) ([]float32, []uint8, []uint8), write func(r *Message, vals []float32, defs, reps []uint8) (int, int), path []string, types []int, opts ...func(*parquet.OptionalField)) *Float32OptionalField {
-	return &Float32OptionalField{
+	f := &Float32OptionalField{
 		read:          read,
 		write:         write,
 		OptionalField: parquet.NewOptionalField(path, types, opts...),
-		stats:         newfloat32optionalStats(maxDef(types)),
 	}
+	f.stats = newfloat32optionalStats(f.MaxLevels.Def)
+	return f
 }
 
 func (f *Float32OptionalField) Schema() parquet.Field {
-	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: Float32Type, RepetitionType: f.RepetitionType, Types: f.Types}
+	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: parquet.Float32Type, RepetitionType: f.RepetitionType, Types: f.Types, IDs: f.IDs()}
 }
 
 func (f *Float32OptionalField) Write(w io.Writer, meta *parquet.Metadata) error {
-	buf := buffpool.Get()
-	defer buffpool.Put(buf)
+	buf := parquet.GetBuffer()
+	defer parquet.PutBuffer(buf)
 
 	bs := make([]byte, 4)
 	for _, v := range f.vals {
@@ -1688,9 +3358,42 @@ func (f *Float32OptionalField) Add(r Message) {
 	f.Reps = reps
 }
 
-func (f *Float32OptionalField) Scan(r *Message) {
+// AddBatch adds the values and levels of each of rs.  It
+// assumes each record has (at least) one value.
+func (f *Float32OptionalField) AddBatch(rs []Message) {
+	if n := len(f.vals) + len(rs); n > cap(f.vals) {
+		vals := make([]float32, len(f.vals), n)
+		copy(vals, f.vals)
+		f.vals = vals
+	}
+
+	if n := len(f.Defs) + len(rs); n > cap(f.Defs) {
+		defs := make([]uint8, len(f.Defs), n)
+		copy(defs, f.Defs)
+		f.Defs = defs
+	}
+
+	if n := len(f.Reps) + len(rs); f.MaxLevels.Rep > 0 && n > cap(f.Reps) {
+		reps := make([]uint8, len(f.Reps), n)
+		copy(reps, f.Reps)
+		f.Reps = reps
+	}
+
+	vals, defs, reps := f.vals, f.Defs, f.Reps
+	for _, r := range rs {
+		vals, defs, reps = f.read(r, vals, defs, reps)
+	}
+	f.stats.add(vals[len(f.vals):], defs[len(f.Defs):])
+	f.vals = vals
+	f.Defs = defs
+	f.Reps = reps
+}
+
+// Scan writes the values of the next record to r.  It returns
+// false if there are no more records.
+func (f *Float32OptionalField) Scan(r *Message) bool {
 	if len(f.Defs) == 0 {
-		return
+		return false
 	}
 
 	v, l := f.write(r, f.vals, f.Defs, f.Reps)
@@ -1699,6 +3402,31 @@ func (f *Float32OptionalField) Scan(r *Message) {
 	if len(f.Reps) > 0 {
 		f.Reps = f.Reps[l:]
 	}
+	return true
+}
+
+// ScanBatch writes the values of the next len(rs) records to rs
+// and returns the number of records it wrote, which is less than
+// len(rs) if the column has run out of values.
+func (f *Float32OptionalField) ScanBatch(rs []Message) int {
+	var n, v, l int
+	for ; n < len(rs) && l < len(f.Defs); n++ {
+		var reps []uint8
+		if len(f.Reps) > 0 {
+			reps = f.Reps[l:]
+		}
+
+		vv, ll := f.write(&rs[n], f.vals[v:], f.Defs[l:], reps)
+		v += vv
+		l += ll
+	}
+
+	f.vals = f.vals[v:]
+	f.Defs = f.Defs[l:]
+	if len(f.Reps) > 0 {
+		f.Reps = f.Reps[l:]
+	}
+	return n
 }
 
 func (f *Float32OptionalField) Levels() ([]uint8, []uint8) {
@@ -1708,22 +3436,26 @@ func (f *Float32OptionalField) Levels() ([]uint8, []uint8) {
 type Float32Field struct {
 	vals []float32
 	parquet.RequiredField
-	read  func(r Message) float32
-	write func(r *Message, vals []float32)
-	stats *float32stats
+	read       func(r Message) float32
+	readBatch  func(rs []Message, vals []float32) []float32
+	write      func(r *Message, vals []float32)
+	writeBatch func(rs []Message, vals []float32)
+	stats      *float32stats
 }
 
-func NewFloat32Field(read func(r Message) float32, write func(r *Message, vals []float32), path []string, opts ...func(*parquet.RequiredField)) *Float32Field {
+func NewFloat32Field(read func(r Message) float32, readBatch func(rs []Message, vals []float32) []float32, write func(r *Message, vals []float32), writeBatch func(rs []Message, vals []float32), path []string, opts ...func(*parquet.RequiredField)) *Float32Field {
 	return &Float32Field{
 		read:          read,
+		readBatch:     readBatch,
 		write:         write,
+		writeBatch:    writeBatch,
 		RequiredField: parquet.NewRequiredField(path, opts...),
 		stats:         newFloat32stats(),
 	}
 }
 
 func (f *Float32Field) Schema() parquet.Field {
-	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: Float32Type, RepetitionType: parquet.RepetitionRequired, Types: []int{0}}
+	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: parquet.Float32Type, RepetitionType: parquet.RepetitionRequired, Types: []int{0}, IDs: f.IDs()}
 }
 
 func (f *Float32Field) Read(r io.ReadSeeker, pg parquet.Page) error {
@@ -1739,8 +3471,8 @@ func (f *Float32Field) Read(r io.ReadSeeker, pg parquet.Page) error {
 }
 
 func (f *Float32Field) Write(w io.Writer, meta *parquet.Metadata) error {
-	buf := buffpool.Get()
-	defer buffpool.Put(buf)
+	buf := parquet.GetBuffer()
+	defer parquet.PutBuffer(buf)
 
 	bs := make([]byte, 4)
 	for _, v := range f.vals {
@@ -1752,13 +3484,30 @@ func (f *Float32Field) Write(w io.Writer, meta *parquet.Metadata) error {
 	return f.DoWrite(w, meta, buf.Bytes(), len(f.vals), f.stats)
 }
 
-func (f *Float32Field) Scan(r *Message) {
+// Scan writes the next value to r.  It returns false if there
+// are no more values.
+func (f *Float32Field) Scan(r *Message) bool {
 	if len(f.vals) == 0 {
-		return
+		return false
 	}
 
 	f.write(r, f.vals)
 	f.vals = f.vals[1:]
+	return true
+}
+
+// ScanBatch writes the next len(rs) values to rs and returns the
+// number of records it wrote, which is less than len(rs) if the
+// column has run out of values.
+func (f *Float32Field) ScanBatch(rs []Message) int {
+	n := len(rs)
+	if n > len(f.vals) {
+		n = len(f.vals)
+	}
+
+	f.writeBatch(rs[:n], f.vals[:n])
+	f.vals = f.vals[n:]
+	return n
 }
 
 func (f *Float32Field) Add(r Message) {
@@ -1767,6 +3516,15 @@ func (f *Float32Field) Add(r Message) {
 	f.vals = append(f.vals, v)
 }
 
+// AddBatch adds the values of each of rs with a single append.
+func (f *Float32Field) AddBatch(rs []Message) {
+	n := len(f.vals)
+	f.vals = f.readBatch(rs, f.vals)
+	for _, v := range f.vals[n:] {
+		f.stats.add(v)
+	}
+}
+
 func (f *Float32Field) Levels() ([]uint8, []uint8) {
 	return nil, nil
 }
@@ -1780,16 +3538,17 @@ type BoolOptionalField struct {
 }
 
 func NewBoolOptionalField(read func(r Message, vals []bool, defs, reps []uint8) ([]bool, []uint8, []uint8), write func(r *Message, vals []bool, defs, reps []uint8) (int, int), path []string, types []int, opts ...func(*parquet.OptionalField)) *BoolOptionalField {
-	return &BoolOptionalField{
+	f := &BoolOptionalField{
 		read:          read,
 		write:         write,
 		OptionalField: parquet.NewOptionalField(path, types, opts...),
-		stats:         newBoolOptionalStats(maxDef(types)),
 	}
+	f.stats = newBoolOptionalStats(f.MaxLevels.Def)
+	return f
 }
 
 func (f *BoolOptionalField) Schema() parquet.Field {
-	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: BoolType, RepetitionType: f.RepetitionType, Types: f.Types}
+	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: parquet.BoolType, RepetitionType: f.RepetitionType, Types: f.Types, IDs: f.IDs()}
 }
 
 func (f *BoolOptionalField) Read(r io.ReadSeeker, pg parquet.Page) error {
@@ -1803,9 +3562,11 @@ func (f *BoolOptionalField) Read(r io.ReadSeeker, pg parquet.Page) error {
 	return err
 }
 
-func (f *BoolOptionalField) Scan(r *Message) {
+// Scan writes the values of the next record to r.  It returns
+// false if there are no more records.
+func (f *BoolOptionalField) Scan(r *Message) bool {
 	if len(f.Defs) == 0 {
-		return
+		return false
 	}
 
 	v, l := f.write(r, f.vals, f.Defs, f.Reps)
@@ -1814,6 +3575,31 @@ func (f *BoolOptionalField) Scan(r *Message) {
 	if len(f.Reps) > 0 {
 		f.Reps = f.Reps[l:]
 	}
+	return true
+}
+
+// ScanBatch writes the values of the next len(rs) records to rs
+// and returns the number of records it wrote, which is less than
+// len(rs) if the column has run out of values.
+func (f *BoolOptionalField) ScanBatch(rs []Message) int {
+	var n, v, l int
+	for ; n < len(rs) && l < len(f.Defs); n++ {
+		var reps []uint8
+		if len(f.Reps) > 0 {
+			reps = f.Reps[l:]
+		}
+
+		vv, ll := f.write(&rs[n], f.vals[v:], f.Defs[l:], reps)
+		v += vv
+		l += ll
+	}
+
+	f.vals = f.vals[v:]
+	f.Defs = f.Defs[l:]
+	if len(f.Reps) > 0 {
+		f.Reps = f.Reps[l:]
+	}
+	return n
 }
 
 func (f *BoolOptionalField) Add(r Message) {
@@ -1824,6 +3610,37 @@ func (f *BoolOptionalField) Add(r Message) {
 	f.Reps = reps
 }
 
+// AddBatch adds the values and levels of each of rs.  It
+// assumes each record has (at least) one value.
+func (f *BoolOptionalField) AddBatch(rs []Message) {
+	if n := len(f.vals) + len(rs); n > cap(f.vals) {
+		vals := make([]bool, len(f.vals), n)
+		copy(vals, f.vals)
+		f.vals = vals
+	}
+
+	if n := len(f.Defs) + len(rs); n > cap(f.Defs) {
+		defs := make([]uint8, len(f.Defs), n)
+		copy(defs, f.Defs)
+		f.Defs = defs
+	}
+
+	if n := len(f.Reps) + len(rs); f.MaxLevels.Rep > 0 && n > cap(f.Reps) {
+		reps := make([]uint8, len(f.Reps), n)
+		copy(reps, f.Reps)
+		f.Reps = reps
+	}
+
+	vals, defs, reps := f.vals, f.Defs, f.Reps
+	for _, r := range rs {
+		vals, defs, reps = f.read(r, vals, defs, reps)
+	}
+	f.stats.add(vals[len(f.vals):], defs[len(f.Defs):])
+	f.vals = vals
+	f.Defs = defs
+	f.Reps = reps
+}
+
 func (f *BoolOptionalField) Write(w io.Writer, meta *parquet.Metadata) error {
 	ln := len(f.vals)
 	byteNum := (ln + 7) / 8
@@ -1844,22 +3661,26 @@ func (f *BoolOptionalField) Levels() ([]uint8, []uint8) {
 
 type BoolField struct {
 	parquet.RequiredField
-	vals  []bool
-	read  func(r Message) bool
-	write func(r *Message, vals []bool)
-	stats *boolStats
+	vals       []bool
+	read       func(r Message) bool
+	readBatch  func(rs []Message, vals []bool) []bool
+	write      func(r *Message, vals []bool)
+	writeBatch func(rs []Message, vals []bool)
+	stats      *boolStats
 }
 
-func NewBoolField(read func(r Message) bool, write func(r *Message, vals []bool), path []string, opts ...func(*parquet.RequiredField)) *BoolField {
+func NewBoolField(read func(r Message) bool, readBatch func(rs []Message, vals []bool) []bool, write func(r *Message, vals []bool), writeBatch func(rs []Message, vals []bool), path []string, opts ...func(*parquet.RequiredField)) *BoolField {
 	return &BoolField{
 		read:          read,
+		readBatch:     readBatch,
 		write:         write,
+		writeBatch:    writeBatch,
 		RequiredField: parquet.NewRequiredField(path, opts...),
 	}
 }
 
 func (f *BoolField) Schema() parquet.Field {
-	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: BoolType, RepetitionType: parquet.RepetitionRequired, Types: []int{0}}
+	return parquet.Field{Name: f.Name(), Path: f.Path(), Type: parquet.BoolType, RepetitionType: parquet.RepetitionRequired, Types: []int{0}, IDs: f.IDs()}
 }
 
 func (f *BoolField) Write(w io.Writer, meta *parquet.Metadata) error {
@@ -1886,13 +3707,30 @@ func (f *BoolField) Read(r io.ReadSeeker, pg parquet.Page) error {
 	return err
 }
 
-func (f *BoolField) Scan(r *Message) {
+// Scan writes the next value to r.  It returns false if there
+// are no more values.
+func (f *BoolField) Scan(r *Message) bool {
 	if len(f.vals) == 0 {
-		return
+		return false
 	}
 
 	f.write(r, f.vals)
 	f.vals = f.vals[1:]
+	return true
+}
+
+// ScanBatch writes the next len(rs) values to rs and returns the
+// number of records it wrote, which is less than len(rs) if the
+// column has run out of values.
+func (f *BoolField) ScanBatch(rs []Message) int {
+	n := len(rs)
+	if n > len(f.vals) {
+		n = len(f.vals)
+	}
+
+	f.writeBatch(rs[:n], f.vals[:n])
+	f.vals = f.vals[n:]
+	return n
 }
 
 func (f *BoolField) Add(r Message) {
@@ -1900,6 +3738,11 @@ func (f *BoolField) Add(r Message) {
 	f.vals = append(f.vals, v)
 }
 
+// AddBatch adds the values of each of rs with a single append.
+func (f *BoolField) AddBatch(rs []Message) {
+	f.vals = f.readBatch(rs, f.vals)
+}
+
 func (f *BoolField) Levels() ([]uint8, []uint8) {
 	return nil, nil
 }
@@ -1928,14 +3771,14 @@ func (s *stringOptionalStats) add(vals []string, defs []uint8) {
 			s.nils++
 		} else {
 			val := vals[i]
-			if s.min == nilString {
+			if s.min == nilOptString {
 				s.min = val
 			} else {
 				if val < s.min {
 					s.min = val
 				}
 			}
-			if s.max == nilString {
+			if s.max == nilOptString {
 				s.max = val
 			} else {
 				if val > s.max {
@@ -2482,80 +4325,3 @@ func (b *boolStats) NullCount() *int64     { return nil }
 func (b *boolStats) DistinctCount() *int64 { return nil }
 func (b *boolStats) Min() []byte           { return nil }
 func (b *boolStats) Max() []byte           { return nil }
-
-func pint32(i int32) *int32       { return &i }
-func puint32(i uint32) *uint32    { return &i }
-func pint64(i int64) *int64       { return &i }
-func puint64(i uint64) *uint64    { return &i }
-func pbool(b bool) *bool          { return &b }
-func pstring(s string) *string    { return &s }
-func pfloat32(f float32) *float32 { return &f }
-func pfloat64(f float64) *float64 { return &f }
-
-// keeps track of the indices of repeated fields
-// that have already been handled by a previous field
-type indices []int
-
-func (i indices) rep(rep uint8) {
-	if rep > 0 {
-		r := int(rep) - 1
-		i[r] = i[r] + 1
-		for j := int(rep); j < len(i); j++ {
-			i[j] = 0
-		}
-	}
-}
-
-func maxDef(types []int) uint8 {
-	var out uint8
-	for _, typ := range types {
-		if typ > 0 {
-			out++
-		}
-	}
-	return out
-}
-
-func Int32Type(se *sch.SchemaElement) {
-	t := sch.Type_INT32
-	se.Type = &t
-}
-
-func Uint32Type(se *sch.SchemaElement) {
-	t := sch.Type_INT32
-	se.Type = &t
-	ct := sch.ConvertedType_UINT_32
-	se.ConvertedType = &ct
-}
-
-func Int64Type(se *sch.SchemaElement) {
-	t := sch.Type_INT64
-	se.Type = &t
-}
-
-func Uint64Type(se *sch.SchemaElement) {
-	t := sch.Type_INT64
-	se.Type = &t
-	ct := sch.ConvertedType_UINT_64
-	se.ConvertedType = &ct
-}
-
-func Float32Type(se *sch.SchemaElement) {
-	t := sch.Type_FLOAT
-	se.Type = &t
-}
-
-func Float64Type(se *sch.SchemaElement) {
-	t := sch.Type_DOUBLE
-	se.Type = &t
-}
-
-func BoolType(se *sch.SchemaElement) {
-	t := sch.Type_BOOLEAN
-	se.Type = &t
-}
-
-func StringType(se *sch.SchemaElement) {
-	t := sch.Type_BYTE_ARRAY
-	se.Type = &t
-}
@@ -0,0 +1,774 @@
+package parquet
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	sch "github.com/parsyl/parquet/schema"
+)
+
+// node is an exported field of a struct.  The leaf
+// nodes (the primitive fields) are the parquet columns.
+type node struct {
+	name     string
+	id       int32
+	index    []int
+	rt       RepetitionType
+	typ      reflect.Type
+	children []*node
+}
+
+var typeFuncs = map[reflect.Kind]FieldFunc{
+	reflect.Int32:   Int32Type,
+	reflect.Uint32:  Uint32Type,
+	reflect.Int64:   Int64Type,
+	reflect.Uint64:  Uint64Type,
+	reflect.Float32: Float32Type,
+	reflect.Float64: Float64Type,
+	reflect.Bool:    BoolType,
+	reflect.String:  StringType,
+}
+
+// structNodes reads the parquet tags of struct type t the same
+// way parquetgen does: pointers are optional, slices are repeated,
+// embedded structs are flattened and fields tagged with "-" or
+// that aren't exported are skipped.  parents holds the struct
+// types that t is nested in, since a struct that contains itself
+// can't be written as a parquet schema.
+func structNodes(t reflect.Type, index []int, parents []reflect.Type) ([]*node, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s is not a struct", t)
+	}
+
+	var out []*node
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		idx := append(append([]int{}, index...), i)
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			nodes, err := structNodes(sf.Type, idx, append(append([]reflect.Type{}, parents...), sf.Type))
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, nodes...)
+			continue
+		}
+
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		name, id, err := ParseTag(sf.Tag.Get("parquet"))
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", sf.Name, err)
+		}
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+
+		n := &node{name: name, id: id, index: idx, rt: Required, typ: sf.Type}
+		switch sf.Type.Kind() {
+		case reflect.Ptr:
+			n.rt = Optional
+			n.typ = sf.Type.Elem()
+		case reflect.Slice:
+			n.rt = Repeated
+			n.typ = sf.Type.Elem()
+		}
+
+		if n.typ.Kind() == reflect.Struct {
+			for _, p := range parents {
+				if p == n.typ {
+					return nil, fmt.Errorf("field %s: %s contains itself", sf.Name, n.typ)
+				}
+			}
+
+			children, err := structNodes(n.typ, nil, append(append([]reflect.Type{}, parents...), n.typ))
+			if err != nil {
+				return nil, err
+			}
+			n.children = children
+		} else if _, ok := typeFuncs[n.typ.Kind()]; !ok {
+			return nil, fmt.Errorf("unsupported type %s for field %s", sf.Type, sf.Name)
+		}
+
+		out = append(out, n)
+	}
+	return out, nil
+}
+
+// ParseTag returns the column name and the field ID (from the
+// id option, for example `parquet:"name,id=7"`) of a parquet tag.
+// parquetgen uses it too, so both read tags the same way.
+func ParseTag(tag string) (string, int32, error) {
+	parts := strings.Split(tag, ",")
+	var id int32
+	for _, opt := range parts[1:] {
+		if !strings.HasPrefix(opt, "id=") {
+			continue
+		}
+
+		i, err := strconv.ParseInt(strings.TrimPrefix(opt, "id="), 10, 32)
+		if err != nil || i <= 0 {
+			return "", 0, fmt.Errorf("invalid field ID %q, it must be a positive int32", strings.TrimPrefix(opt, "id="))
+		}
+		id = int32(i)
+	}
+	return parts[0], id, nil
+}
+
+// leaves returns the path to each of the primitive fields.
+func leaves(nodes []*node, parents []*node) [][]*node {
+	var out [][]*node
+	for _, n := range nodes {
+		pth := append(append([]*node{}, parents...), n)
+		if n.children == nil {
+			out = append(out, pth)
+		} else {
+			out = append(out, leaves(n.children, pth)...)
+		}
+	}
+	return out
+}
+
+func leafField(pth []*node) Field {
+	names := make([]string, len(pth))
+	types := make([]int, len(pth))
+	var ids []int32
+	for i, n := range pth {
+		names[i] = n.name
+		types[i] = int(n.rt)
+		if n.id != 0 {
+			if ids == nil {
+				ids = make([]int32, len(pth))
+			}
+			ids[i] = n.id
+		}
+	}
+
+	leaf := pth[len(pth)-1]
+	return Field{
+		Name:           strings.Join(names, "."),
+		Path:           names,
+		Types:          types,
+		Type:           typeFuncs[leaf.typ.Kind()],
+		RepetitionType: fieldFuncs[leaf.rt],
+		IDs:            ids,
+	}
+}
+
+// structColumns derives the parquet schema of struct type t.
+func structColumns(t reflect.Type) ([][]*node, []Field, error) {
+	nodes, err := structNodes(t, nil, []reflect.Type{t})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pths := leaves(nodes, nil)
+	if len(pths) == 0 {
+		return nil, nil, fmt.Errorf("%s has no parquet fields", t)
+	}
+
+	fields := make([]Field, len(pths))
+	for i, pth := range pths {
+		fields[i] = leafField(pth)
+		if err := CheckDepth(strings.Join(fields[i].Path, "."), fields[i].Types); err != nil {
+			return nil, nil, err
+		}
+	}
+	return pths, fields, nil
+}
+
+// Writer writes structs to a parquet file.  The schema is derived
+// from the struct's parquet tags at runtime, so Writer is slower
+// than the ParquetWriter that parquetgen generates, but it doesn't
+// require code generation.
+type Writer struct {
+	typ         reflect.Type
+	pths        [][]*node
+	fields      []Field
+	columns     []*column
+	meta        *Metadata
+	w           io.Writer
+	max         int
+	len         int
+	rows        int
+	compression sch.CompressionCodec
+	checksums   bool
+	encodings   map[string]sch.Encoding
+	keyValues   [][2]string
+	createdBy   *string
+
+	// sortBy holds the columns that the records of each row group
+	// are sorted by, sorts holds their paths and records holds the
+	// records that have been added since the last Write.
+	sortBy  []string
+	sorts   [][]*node
+	records []reflect.Value
+}
+
+// NewWriter creates a Writer for structs of type t.
+func NewWriter(w io.Writer, t reflect.Type, opts ...func(*Writer) error) (*Writer, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	pths, fields, err := structColumns(t)
+	if err != nil {
+		return nil, err
+	}
+
+	wr := &Writer{
+		typ:         t,
+		pths:        pths,
+		fields:      fields,
+		w:           w,
+		max:         1000,
+		compression: sch.CompressionCodec_SNAPPY,
+	}
+
+	for _, opt := range opts {
+		if err := opt(wr); err != nil {
+			return nil, err
+		}
+	}
+
+	wr.columns = make([]*column, len(fields))
+	for i, f := range fields {
+		wr.columns[i] = newColumn(f, wr.compression)
+	}
+
+	wr.meta = New(fields...)
+	if wr.checksums {
+		wr.meta.EnableChecksums()
+	}
+	for col, enc := range wr.encodings {
+		if err := wr.meta.SetEncoding(col, enc); err != nil {
+			return nil, err
+		}
+	}
+	for _, kv := range wr.keyValues {
+		wr.meta.SetKeyValue(kv[0], kv[1])
+	}
+	if wr.createdBy != nil {
+		wr.meta.SetCreatedBy(*wr.createdBy)
+	}
+	if len(wr.sortBy) > 0 {
+		if err := wr.meta.SetSortingColumns(wr.sortBy...); err != nil {
+			return nil, err
+		}
+		for _, col := range wr.sortBy {
+			for i, f := range fields {
+				if f.Name == col {
+					wr.sorts = append(wr.sorts, pths[i])
+				}
+			}
+		}
+	}
+
+	_, err = w.Write(par1)
+	return wr, err
+}
+
+// Magic is written at the beginning and the end of
+// every parquet file.
+const Magic = "PAR1"
+
+var par1 = []byte(Magic)
+
+// WriterMaxPageSize is the maximum number of rows in each page.
+func WriterMaxPageSize(m int) func(*Writer) error {
+	return func(w *Writer) error {
+		w.max = m
+		return nil
+	}
+}
+
+// WriterUncompressed turns off the compression of the pages.
+func WriterUncompressed(w *Writer) error {
+	w.compression = sch.CompressionCodec_UNCOMPRESSED
+	return nil
+}
+
+// WriterSnappy compresses the pages with snappy (the default).
+func WriterSnappy(w *Writer) error {
+	w.compression = sch.CompressionCodec_SNAPPY
+	return nil
+}
+
+// WriterGzip compresses the pages with gzip.
+func WriterGzip(w *Writer) error {
+	w.compression = sch.CompressionCodec_GZIP
+	return nil
+}
+
+// WriterChecksums adds a CRC32 of each page's data to its
+// page header.
+func WriterChecksums(w *Writer) error {
+	w.checksums = true
+	return nil
+}
+
+// WriterEncoding sets the encoding of the values of column (the
+// names of its fields separated by dots).  NewWriter returns an
+// error if the column doesn't exist or its type can't be encoded
+// with enc.
+func WriterEncoding(column string, enc sch.Encoding) func(*Writer) error {
+	return func(w *Writer) error {
+		if w.encodings == nil {
+			w.encodings = map[string]sch.Encoding{}
+		}
+		w.encodings[column] = enc
+		return nil
+	}
+}
+
+// WriterKeyValue adds a key/value pair to the metadata in the
+// file's footer.
+func WriterKeyValue(key, value string) func(*Writer) error {
+	return func(w *Writer) error {
+		w.keyValues = append(w.keyValues, [2]string{key, value})
+		return nil
+	}
+}
+
+// WriterCreatedBy sets the application that wrote the file
+// (the footer's created_by).
+func WriterCreatedBy(createdBy string) func(*Writer) error {
+	return func(w *Writer) error {
+		w.createdBy = &createdBy
+		return nil
+	}
+}
+
+// WriterSortBy makes Write sort the records of each row group by
+// columns (in ascending order, with missing values first) before
+// they are encoded.  The sort order is recorded in the row groups'
+// sorting_columns.  NewWriter returns an error if a column doesn't
+// exist or is repeated.
+func WriterSortBy(columns ...string) func(*Writer) error {
+	return func(w *Writer) error {
+		w.sortBy = columns
+		return nil
+	}
+}
+
+// Add adds a record to the current row group.  rec must be
+// a struct (or a pointer to a struct) of the Writer's type.
+func (w *Writer) Add(rec interface{}) error {
+	v := reflect.Indirect(reflect.ValueOf(rec))
+	if !v.IsValid() {
+		return fmt.Errorf("can't add a nil record to a writer of %s", w.typ)
+	}
+
+	if v.Type() != w.typ {
+		return fmt.Errorf("can't add %s to a writer of %s", v.Type(), w.typ)
+	}
+
+	if w.sorts != nil {
+		cp := reflect.New(w.typ).Elem()
+		cp.Set(v)
+		w.records = append(w.records, cp)
+		return nil
+	}
+	return w.add(v)
+}
+
+func (w *Writer) add(v reflect.Value) error {
+	if w.len == w.max {
+		for _, c := range w.columns {
+			c.newPage()
+		}
+		w.len = 0
+	}
+
+	w.meta.NextDoc()
+	for i, c := range w.columns {
+		if err := stripe(c, w.pths[i], v, 0, 0); err != nil {
+			return err
+		}
+	}
+
+	w.len++
+	w.rows++
+	return nil
+}
+
+// stripe adds the values (and definition and repetition levels)
+// of the field at pth[0] of struct v to column c.
+func stripe(c *column, pth []*node, v reflect.Value, def, rep uint8) error {
+	n := pth[0]
+	fv := v.FieldByIndex(n.index)
+	leaf := len(pth) == 1
+	switch n.rt {
+	case Optional:
+		if fv.IsNil() {
+			c.levels(def, rep)
+			return nil
+		}
+		fv = fv.Elem()
+		def++
+	case Repeated:
+		if fv.Len() == 0 {
+			c.levels(def, rep)
+			return nil
+		}
+
+		def++
+		lvl := repLevel(c, pth)
+		for i := 0; i < fv.Len(); i++ {
+			r := rep
+			if i > 0 {
+				r = lvl
+			}
+
+			var err error
+			if leaf {
+				c.levels(def, r)
+				err = c.add(fv.Index(i))
+			} else {
+				err = stripe(c, pth[1:], fv.Index(i), def, r)
+			}
+
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if leaf {
+		c.levels(def, rep)
+		return c.add(fv)
+	}
+	return stripe(c, pth[1:], fv, def, rep)
+}
+
+// repLevel is the repetition level of the last node in pth
+// (pth is the tail of the column's full path).
+func repLevel(c *column, pth []*node) uint8 {
+	lvl := c.maxRep
+	for _, n := range pth[1:] {
+		if n.rt == Repeated {
+			lvl--
+		}
+	}
+	return lvl
+}
+
+// sortedValue returns the value of the (non-repeated) column at
+// pth, or false if it is missing.
+func sortedValue(pth []*node, v reflect.Value) (reflect.Value, bool) {
+	for _, n := range pth {
+		v = v.FieldByIndex(n.index)
+		if n.rt == Optional {
+			if v.IsNil() {
+				return v, false
+			}
+			v = v.Elem()
+		}
+	}
+	return v, true
+}
+
+// compareSorted compares two values of the same primitive kind.
+func compareSorted(a, b reflect.Value) int {
+	var less, greater bool
+	switch a.Kind() {
+	case reflect.Int32, reflect.Int64:
+		less, greater = a.Int() < b.Int(), a.Int() > b.Int()
+	case reflect.Uint32, reflect.Uint64:
+		less, greater = a.Uint() < b.Uint(), a.Uint() > b.Uint()
+	case reflect.Float32, reflect.Float64:
+		less, greater = a.Float() < b.Float(), a.Float() > b.Float()
+	case reflect.Bool:
+		less, greater = !a.Bool() && b.Bool(), a.Bool() && !b.Bool()
+	case reflect.String:
+		less, greater = a.String() < b.String(), a.String() > b.String()
+	}
+
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
+
+// addSorted sorts the records that have been buffered since the
+// last Write and adds them to the row group.
+func (w *Writer) addSorted() error {
+	recs := w.records
+	w.records = nil
+	sort.SliceStable(recs, func(i, j int) bool {
+		for _, pth := range w.sorts {
+			a, okA := sortedValue(pth, recs[i])
+			b, okB := sortedValue(pth, recs[j])
+			if !okA || !okB {
+				if okA != okB {
+					return !okA
+				}
+				continue
+			}
+
+			if c := compareSorted(a, b); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+
+	for _, v := range recs {
+		if err := w.add(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Write writes the current row group to the file.
+func (w *Writer) Write() error {
+	if err := w.addSorted(); err != nil {
+		return err
+	}
+
+	if w.rows == 0 {
+		return nil
+	}
+
+	for _, c := range w.columns {
+		if err := c.write(w.w, w.meta); err != nil {
+			return err
+		}
+		c.reset()
+	}
+
+	w.len = 0
+	w.rows = 0
+	w.meta.StartRowGroup(w.fields...)
+	return nil
+}
+
+// Close writes the current row group (if it has any rows) and
+// then the parquet metadata at the end of the file.
+func (w *Writer) Close() error {
+	if err := w.Write(); err != nil {
+		return err
+	}
+
+	if err := w.meta.Footer(w.w); err != nil {
+		return err
+	}
+
+	_, err := w.w.Write(par1)
+	return err
+}
+
+// Reader reads structs from a parquet file.  The schema is derived
+// from the struct's parquet tags at runtime.
+type Reader struct {
+	typ            reflect.Type
+	pths           [][]*node
+	fields         []Field
+	columns        []*columnValues
+	cursor         int64
+	rows           int64
+	rowGroupCursor int64
+	rowGroupCount  int64
+	pages          map[string][]Page
+	meta           *Metadata
+	err            error
+
+	r         io.ReadSeeker
+	rowGroups []RowGroup
+}
+
+// NewReader creates a Reader for structs of type t.
+func NewReader(r io.ReadSeeker, t reflect.Type) (*Reader, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	pths, fields, err := structColumns(t)
+	if err != nil {
+		return nil, err
+	}
+
+	meta := New(fields...)
+	if err := meta.ReadFooter(r); err != nil {
+		return nil, err
+	}
+
+	pages, err := meta.Pages()
+	if err != nil {
+		return nil, err
+	}
+
+	rd := &Reader{
+		typ:       t,
+		pths:      pths,
+		fields:    fields,
+		rows:      meta.Rows(),
+		pages:     pages,
+		meta:      meta,
+		rowGroups: meta.RowGroups(),
+		r:         r,
+	}
+
+	return rd, rd.readRowGroup()
+}
+
+// Rows returns the number of rows in the file.
+func (r *Reader) Rows() int64 {
+	return r.rows
+}
+
+// KeyValueMetadata returns the key/value metadata of the file.
+func (r *Reader) KeyValueMetadata() map[string]string {
+	return r.meta.KeyValueMetadata()
+}
+
+// CreatedBy returns the application that wrote the file (if
+// the file says so).
+func (r *Reader) CreatedBy() string {
+	return r.meta.CreatedBy()
+}
+
+// Error returns the error (if any) that stopped Next.
+func (r *Reader) Error() error {
+	return r.err
+}
+
+func (r *Reader) readRowGroup() error {
+	r.rowGroupCursor = 0
+
+	if len(r.rowGroups) == 0 {
+		r.rowGroupCount = 0
+		return nil
+	}
+
+	rg := r.rowGroups[0]
+	r.rowGroupCount = rg.Rows
+	r.columns = make([]*columnValues, len(r.fields))
+	for i, f := range r.fields {
+		pages := r.pages[f.Name]
+		if len(pages) == 0 {
+			return &ErrSchemaMismatch{Column: f.Name, Reason: "missing column chunk"}
+		}
+
+		pg := pages[0]
+		if _, err := r.r.Seek(pg.Offset, io.SeekStart); err != nil {
+			return err
+		}
+
+		col, err := readColumn(r.r, f, pg)
+		if err != nil {
+			return fmt.Errorf("unable to read field %s, err: %w", f.Name, err)
+		}
+
+		r.columns[i] = col
+		r.pages[f.Name] = pages[1:]
+	}
+
+	r.rowGroups = r.rowGroups[1:]
+	return nil
+}
+
+// Next prepares the next record to be read with Scan.
+func (r *Reader) Next() bool {
+	if r.err != nil || r.cursor >= r.rows {
+		return false
+	}
+
+	if r.rowGroupCursor >= r.rowGroupCount {
+		r.err = r.readRowGroup()
+		if r.err != nil {
+			return false
+		}
+	}
+
+	r.cursor++
+	r.rowGroupCursor++
+	return true
+}
+
+// Scan reads the current record into x, which must be a
+// pointer to a struct of the Reader's type.  x is reset first,
+// so a struct can be reused for every record.
+func (r *Reader) Scan(x interface{}) error {
+	if r.err != nil {
+		return r.err
+	}
+
+	v := reflect.ValueOf(x)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Type() != r.typ {
+		return fmt.Errorf("can't scan into %T, expected *%s", x, r.typ)
+	}
+
+	v = v.Elem()
+	v.Set(reflect.Zero(r.typ))
+	for i, col := range r.columns {
+		assemble(col, r.pths[i], v)
+	}
+	return nil
+}
+
+// assemble consumes the levels (and values) of the current
+// record of col and writes the values to struct v.
+func assemble(col *columnValues, pth []*node, v reflect.Value) {
+	rts := make([]int, len(pth))
+	for i, n := range pth {
+		rts[i] = int(n.rt)
+	}
+
+	col.record(getRepetitionTypes(rts).MaxRep(), func(def uint8, ind []int) {
+		setField(col, pth, v, def, ind)
+	})
+}
+
+// setField follows pth, initializing pointers and growing slices
+// as needed, until either the definition level has been reached
+// or the value has been set.
+func setField(col *columnValues, pth []*node, v reflect.Value, def uint8, ind []int) {
+	var defs uint8
+	var reps int
+	for _, n := range pth {
+		fv := v.FieldByIndex(n.index)
+		switch n.rt {
+		case Optional:
+			defs++
+			if def < defs {
+				return
+			}
+			if fv.IsNil() {
+				fv.Set(reflect.New(n.typ))
+			}
+			fv = fv.Elem()
+		case Repeated:
+			defs++
+			reps++
+			if def < defs {
+				return
+			}
+			i := ind[reps-1]
+			for fv.Len() <= i {
+				fv.Set(reflect.Append(fv, reflect.Zero(n.typ)))
+			}
+			fv = fv.Index(i)
+		}
+
+		if n.children == nil {
+			setValue(fv, col.vals, col.vi)
+			col.vi++
+			return
+		}
+		v = fv
+	}
+}
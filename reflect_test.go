@@ -0,0 +1,221 @@
+package parquet_test
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/parsyl/parquet"
+	"github.com/stretchr/testify/assert"
+)
+
+var people = [][]Person{
+	{
+		{Being: Being{ID: 1, Age: pint32(-10)}, Code: pstring("a")},
+		{Happiness: 55, Keen: pbool(true), Birthday: 55},
+		{Sadness: pint64(1), Anniversary: puint64(1010010), Hungry: true},
+		{Funkiness: 0.2, Boldness: 1.5, Lameness: pfloat32(-0.4), BFF: "bob"},
+		{
+			Hobby: &Hobby{
+				Name:       "napping",
+				Difficulty: pint32(10),
+				Skills: []Skill{
+					{Name: "meditation", Difficulty: "very"},
+					{Name: "calmness", Difficulty: "so-so"},
+				},
+			},
+		},
+	},
+	{
+		{Hobby: &Hobby{Name: "running"}, Sleepy: true},
+		{
+			Friends: []Being{
+				{ID: 2, Age: pint32(12)},
+				{ID: 3, Name: "pal"},
+			},
+		},
+		{Code: pstring("g"), Keen: pbool(false)},
+	},
+}
+
+func TestReflection(t *testing.T) {
+	type testCase struct {
+		name     string
+		writer   func([][]Person, int) ([]byte, error)
+		reader   func([]byte) ([]Person, error)
+		pageSize int
+	}
+
+	testCases := []testCase{
+		{name: "reflection writer and reader", writer: reflectWrite, reader: reflectRead},
+		{name: "reflection writer and reader small pages", writer: reflectWrite, reader: reflectRead, pageSize: 2},
+		{name: "reflection writer and generated reader", writer: reflectWrite, reader: generatedRead},
+		{name: "generated writer and reflection reader", writer: generatedWrite, reader: reflectRead, pageSize: 2},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("%02d %s", i, tc.name), func(t *testing.T) {
+			if tc.pageSize == 0 {
+				tc.pageSize = 100
+			}
+
+			b, err := tc.writer(people, tc.pageSize)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			out, err := tc.reader(b)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			var expected []Person
+			for _, rg := range people {
+				expected = append(expected, rg...)
+			}
+			assert.Equal(t, expected, out)
+		})
+	}
+}
+
+func TestReflectionErrors(t *testing.T) {
+	_, err := parquet.NewWriter(&bytes.Buffer{}, reflect.TypeOf(1))
+	assert.EqualError(t, err, "int is not a struct")
+
+	_, err = parquet.NewWriter(&bytes.Buffer{}, reflect.TypeOf(struct{ M map[string]int }{}))
+	assert.EqualError(t, err, "unsupported type map[string]int for field M")
+
+	_, err = parquet.NewWriter(&bytes.Buffer{}, reflect.TypeOf(struct {
+		ID int32 `parquet:"id,id=0"`
+	}{}))
+	assert.EqualError(t, err, `field ID: invalid field ID "0", it must be a positive int32`)
+
+	type node struct {
+		Value int32
+		Next  *node
+	}
+	_, err = parquet.NewWriter(&bytes.Buffer{}, reflect.TypeOf(node{}))
+	assert.EqualError(t, err, "field Next: parquet_test.node contains itself")
+
+	type tree struct {
+		Value    int32
+		Children []struct{ Nodes []tree }
+	}
+	_, err = parquet.NewReader(bytes.NewReader(nil), reflect.TypeOf(tree{}))
+	assert.EqualError(t, err, "field Nodes: parquet_test.tree contains itself")
+
+	w, err := parquet.NewWriter(&bytes.Buffer{}, reflect.TypeOf(Person{}))
+	assert.NoError(t, err)
+	assert.EqualError(t, w.Add(Being{}), "can't add parquet_test.Being to a writer of parquet_test.Person")
+	assert.EqualError(t, w.Add((*Person)(nil)), "can't add a nil record to a writer of parquet_test.Person")
+	assert.EqualError(t, w.Add(nil), "can't add a nil record to a writer of parquet_test.Person")
+}
+
+func reflectWrite(peeps [][]Person, pageSize int) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := parquet.NewWriter(&buf, reflect.TypeOf(Person{}), parquet.WriterMaxPageSize(pageSize))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rg := range peeps {
+		for _, p := range rg {
+			if err := w.Add(p); err != nil {
+				return nil, err
+			}
+		}
+		if err := w.Write(); err != nil {
+			return nil, err
+		}
+	}
+
+	err = w.Close()
+	return buf.Bytes(), err
+}
+
+func TestReflectionScanReuse(t *testing.T) {
+	var expected []Person
+	for _, rg := range people {
+		expected = append(expected, rg...)
+	}
+
+	b, err := reflectWrite(people, 2)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	r, err := parquet.NewReader(bytes.NewReader(b), reflect.TypeOf(Person{}))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	// the optional and repeated fields of the previous record
+	// mustn't be left in p
+	var p Person
+	var out []Person
+	for r.Next() {
+		if !assert.NoError(t, r.Scan(&p)) {
+			return
+		}
+		out = append(out, p)
+	}
+	assert.NoError(t, r.Error())
+	assert.Equal(t, expected, out)
+}
+
+func reflectRead(b []byte) ([]Person, error) {
+	r, err := parquet.NewReader(bytes.NewReader(b), reflect.TypeOf(Person{}))
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Person
+	for r.Next() {
+		var p Person
+		if err := r.Scan(&p); err != nil {
+			return nil, err
+		}
+		out = append(out, p)
+	}
+	return out, r.Error()
+}
+
+func generatedWrite(peeps [][]Person, pageSize int) ([]byte, error) {
+	return generatedWriteWith(peeps, MaxPageSize(pageSize))
+}
+
+func generatedWriteWith(peeps [][]Person, opts ...func(*ParquetWriter) error) ([]byte, error) {
+	var buf bytes.Buffer
+	w, err := NewParquetWriter(&buf, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rg := range peeps {
+		for _, p := range rg {
+			w.Add(p)
+		}
+		if err := w.Write(); err != nil {
+			return nil, err
+		}
+	}
+
+	err = w.Close()
+	return buf.Bytes(), err
+}
+
+func generatedRead(b []byte) ([]Person, error) {
+	r, err := NewParquetReader(bytes.NewReader(b))
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Person
+	for r.Next() {
+		var p Person
+		r.Scan(&p)
+		out = append(out, p)
+	}
+	return out, r.Error()
+}
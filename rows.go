@@ -0,0 +1,349 @@
+package parquet
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	sch "github.com/parsyl/parquet/schema"
+)
+
+// Row is a record read by RowReader.  Required and optional
+// primitive fields are stored as int32, uint32, int64, uint64,
+// float32, float64, bool or string (missing optional fields are
+// nil), nested fields are stored as a Row and repeated fields are
+// stored as a []interface{}.
+type Row map[string]interface{}
+
+// rowNode is an element of the schema of a parquet file.
+type rowNode struct {
+	se       *sch.SchemaElement
+	children []*rowNode
+}
+
+// rowColumn is a primitive column of a parquet file.
+type rowColumn struct {
+	pth    []*rowNode
+	field  Field
+	maxRep uint8
+	vals   *columnValues
+}
+
+// RowReader reads the rows of any parquet file (as long as it
+// only uses the supported types and encodings) without a struct
+// that defines its schema.
+type RowReader struct {
+	columns        []*rowColumn
+	cursor         int64
+	rows           int64
+	rowGroupCursor int64
+	rowGroupCount  int64
+	err            error
+
+	r         io.ReadSeeker
+	rowGroups []*sch.RowGroup
+}
+
+// NewRowReader reads the schema from the metadata of the parquet
+// file and returns a RowReader.
+func NewRowReader(r io.ReadSeeker) (*RowReader, error) {
+	meta, err := ReadMetaData(r)
+	if err != nil {
+		return nil, err
+	}
+
+	columns, err := schemaColumns(meta)
+	if err != nil {
+		return nil, err
+	}
+
+	rr := &RowReader{
+		columns:   columns,
+		rows:      meta.NumRows,
+		rowGroups: meta.RowGroups,
+		r:         r,
+	}
+
+	return rr, rr.readRowGroup()
+}
+
+// schemaColumns returns the primitive columns of the schema
+// of a parquet file.
+func schemaColumns(meta *sch.FileMetaData) ([]*rowColumn, error) {
+	if len(meta.Schema) == 0 {
+		return nil, fmt.Errorf("parquet file has no schema")
+	}
+
+	root, n, err := schemaTree(meta.Schema, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if n != len(meta.Schema) {
+		return nil, fmt.Errorf("invalid schema, %d of %d elements are part of the schema tree", n, len(meta.Schema))
+	}
+
+	return rowColumns(root.children, nil), nil
+}
+
+// schemaTree turns the flattened (depth first) schema elements
+// into a tree and returns it along with the index of the next
+// element.
+func schemaTree(elements []*sch.SchemaElement, i int) (*rowNode, int, error) {
+	n := &rowNode{se: elements[i]}
+	i++
+	if n.se.NumChildren == nil || *n.se.NumChildren == 0 {
+		if n.se.Type == nil {
+			return nil, 0, fmt.Errorf("schema element %s has neither a type nor children", n.se.Name)
+		}
+		return n, i, nil
+	}
+
+	for j := 0; j < int(*n.se.NumChildren); j++ {
+		if i >= len(elements) {
+			return nil, 0, fmt.Errorf("invalid schema, %s is missing children", n.se.Name)
+		}
+
+		var child *rowNode
+		var err error
+		child, i, err = schemaTree(elements, i)
+		if err != nil {
+			return nil, 0, err
+		}
+		n.children = append(n.children, child)
+	}
+	return n, i, nil
+}
+
+func rowColumns(nodes []*rowNode, parents []*rowNode) []*rowColumn {
+	var out []*rowColumn
+	for _, n := range nodes {
+		pth := append(append([]*rowNode{}, parents...), n)
+		if n.children != nil {
+			out = append(out, rowColumns(n.children, pth)...)
+			continue
+		}
+
+		names := make([]string, len(pth))
+		types := make([]int, len(pth))
+		var ids []int32
+		for i, p := range pth {
+			names[i] = p.se.Name
+			if p.se.RepetitionType != nil {
+				types[i] = int(*p.se.RepetitionType)
+			}
+			if p.se.FieldID != nil && *p.se.FieldID != 0 {
+				if ids == nil {
+					ids = make([]int32, len(pth))
+				}
+				ids[i] = *p.se.FieldID
+			}
+		}
+
+		se := n.se
+		out = append(out, &rowColumn{
+			pth:    pth,
+			maxRep: getRepetitionTypes(types).MaxRep(),
+			field: Field{
+				Name:  strings.Join(names, "."),
+				Path:  names,
+				Types: types,
+				Type: func(s *sch.SchemaElement) {
+					s.Type = se.Type
+					s.ConvertedType = se.ConvertedType
+				},
+				RepetitionType: fieldFuncs[types[len(types)-1]],
+				IDs:            ids,
+			},
+		})
+	}
+	return out
+}
+
+// Fields returns the primitive columns of the parquet file.
+func (r *RowReader) Fields() []Field {
+	out := make([]Field, len(r.columns))
+	for i, c := range r.columns {
+		out[i] = c.field
+	}
+	return out
+}
+
+// Rows returns the number of rows in the file.
+func (r *RowReader) Rows() int64 {
+	return r.rows
+}
+
+// Error returns the error (if any) that stopped Next.
+func (r *RowReader) Error() error {
+	return r.err
+}
+
+func (r *RowReader) readRowGroup() error {
+	r.rowGroupCursor = 0
+
+	if len(r.rowGroups) == 0 {
+		r.rowGroupCount = 0
+		return nil
+	}
+
+	rg := r.rowGroups[0]
+	r.rowGroupCount = rg.NumRows
+
+	chunks := map[string]*sch.ColumnChunk{}
+	for i, ch := range rg.Columns {
+		if ch.MetaData == nil {
+			return fmt.Errorf("column chunk %d has no metadata", i)
+		}
+		chunks[strings.Join(ch.MetaData.PathInSchema, ".")] = ch
+	}
+
+	for _, c := range r.columns {
+		ch, ok := chunks[c.field.Name]
+		if !ok {
+			return fmt.Errorf("missing column chunk for %s", c.field.Name)
+		}
+
+		pg := chunkPage(ch)
+		if _, err := r.r.Seek(pg.Offset, io.SeekStart); err != nil {
+			return err
+		}
+
+		vals, err := readColumn(r.r, c.field, pg)
+		if err != nil {
+			return fmt.Errorf("unable to read field %s, err: %w", c.field.Name, err)
+		}
+		c.vals = vals
+	}
+
+	r.rowGroups = r.rowGroups[1:]
+	return nil
+}
+
+// Next prepares the next row to be read with Row.
+func (r *RowReader) Next() bool {
+	if r.err != nil || r.cursor >= r.rows {
+		return false
+	}
+
+	if r.rowGroupCursor >= r.rowGroupCount {
+		r.err = r.readRowGroup()
+		if r.err != nil {
+			return false
+		}
+	}
+
+	r.cursor++
+	r.rowGroupCursor++
+	return true
+}
+
+// Row returns the current row.
+func (r *RowReader) Row() Row {
+	row := Row{}
+	for _, c := range r.columns {
+		c.vals.record(c.maxRep, func(def uint8, ind []int) {
+			c.set(row, def, ind)
+		})
+	}
+	return row
+}
+
+// set follows the column's path, creating nested rows and growing
+// repeated fields as needed, until either the definition level has
+// been reached or the value has been set.
+func (c *rowColumn) set(row Row, def uint8, ind []int) {
+	var defs uint8
+	var reps int
+	for i, n := range c.pth {
+		leaf := i == len(c.pth)-1
+		name := n.se.Name
+		var rt sch.FieldRepetitionType
+		if n.se.RepetitionType != nil {
+			rt = *n.se.RepetitionType
+		}
+
+		switch rt {
+		case sch.FieldRepetitionType_OPTIONAL:
+			defs++
+			if def < defs {
+				if _, ok := row[name]; !ok {
+					row[name] = nil
+				}
+				return
+			}
+		case sch.FieldRepetitionType_REPEATED:
+			defs++
+			reps++
+			if def < defs {
+				if _, ok := row[name]; !ok {
+					row[name] = nil
+				}
+				return
+			}
+
+			list, _ := row[name].([]interface{})
+			j := ind[reps-1]
+			for len(list) <= j {
+				if leaf {
+					list = append(list, nil)
+				} else {
+					list = append(list, Row{})
+				}
+			}
+			row[name] = list
+
+			if leaf {
+				list[j] = c.value()
+				return
+			}
+			row = list[j].(Row)
+			continue
+		}
+
+		if leaf {
+			row[name] = c.value()
+			return
+		}
+
+		child, ok := row[name].(Row)
+		if !ok {
+			child = Row{}
+			row[name] = child
+		}
+		row = child
+	}
+}
+
+// value returns the column's next value.
+func (c *rowColumn) value() interface{} {
+	i := c.vals.vi
+	c.vals.vi++
+	return columnValue(c.vals.vals, i, c.pth[len(c.pth)-1].se.ConvertedType)
+}
+
+// columnValue returns the i'th of the decoded values, converted
+// to an unsigned integer if the column has a UINT converted type.
+func columnValue(vals interface{}, i int, ct *sch.ConvertedType) interface{} {
+	switch vals := vals.(type) {
+	case []int32:
+		if ct != nil && *ct == sch.ConvertedType_UINT_32 {
+			return uint32(vals[i])
+		}
+		return vals[i]
+	case []int64:
+		if ct != nil && *ct == sch.ConvertedType_UINT_64 {
+			return uint64(vals[i])
+		}
+		return vals[i]
+	case []float32:
+		return vals[i]
+	case []float64:
+		return vals[i]
+	case []bool:
+		return vals[i]
+	case []string:
+		return vals[i]
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package parquet_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/parsyl/parquet"
+	sch "github.com/parsyl/parquet/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRowReader(t *testing.T) {
+	b, err := generatedWrite(people, 2)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	r, err := parquet.NewRowReader(bytes.NewReader(b))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, int64(8), r.Rows())
+	assert.Len(t, r.Fields(), 22)
+
+	var rows []parquet.Row
+	for r.Next() {
+		rows = append(rows, r.Row())
+	}
+
+	if !assert.NoError(t, r.Error()) || !assert.Len(t, rows, 8) {
+		return
+	}
+
+	assert.Equal(t, parquet.Row{
+		"id":          int32(1),
+		"name":        "",
+		"age":         int32(-10),
+		"happiness":   int64(0),
+		"sadness":     nil,
+		"code":        "a",
+		"funkiness":   float32(0),
+		"boldness":    float64(0),
+		"lameness":    nil,
+		"keen":        nil,
+		"birthday":    uint32(0),
+		"anniversary": nil,
+		"bff":         "",
+		"hungry":      false,
+		"hobby":       nil,
+		"friends":     nil,
+		"Sleepy":      false,
+	}, rows[0])
+
+	assert.Equal(t, uint64(1010010), rows[2]["anniversary"])
+
+	assert.Equal(t, parquet.Row{
+		"name":       "napping",
+		"difficulty": int32(10),
+		"skills": []interface{}{
+			parquet.Row{"name": "meditation", "difficulty": "very"},
+			parquet.Row{"name": "calmness", "difficulty": "so-so"},
+		},
+	}, rows[4]["hobby"])
+
+	assert.Equal(t, parquet.Row{
+		"name":       "running",
+		"difficulty": nil,
+		"skills":     nil,
+	}, rows[5]["hobby"])
+
+	assert.Equal(t, []interface{}{
+		parquet.Row{"id": int32(2), "name": "", "age": int32(12)},
+		parquet.Row{"id": int32(3), "name": "pal", "age": nil},
+	}, rows[6]["friends"])
+
+	// a chunk whose metadata isn't in the footer
+	b, err = rewriteFooter(b, func(footer *sch.FileMetaData) {
+		footer.RowGroups[0].Columns[0].MetaData = nil
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = parquet.NewRowReader(bytes.NewReader(b))
+	assert.EqualError(t, err, "column chunk 0 has no metadata")
+}
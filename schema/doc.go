@@ -0,0 +1,7 @@
+// Package schema holds the thrift types that make up a parquet file's
+// metadata.  Everything except this file is generated from schema.thrift
+// with thrift 0.11.0, so add new types and values to the IDL and
+// regenerate instead of editing the generated code.
+package schema
+
+//go:generate thrift --gen go:thrift_import=github.com/apache/thrift/lib/go/thrift -out .. schema.thrift